@@ -3,5 +3,11 @@ module github.com/scopweb/mcp-go-context
 go 1.21
 
 require (
-	github.com/google/uuid v1.6.0
+	github.com/BurntSushi/toml v1.3.2
+	github.com/bmatcuk/doublestar/v4 v4.6.1
+	github.com/fsnotify/fsnotify v1.7.0
+	golang.org/x/mod v0.14.0
+	gopkg.in/yaml.v3 v3.0.1
 )
+
+require golang.org/x/sys v0.13.0 // indirect
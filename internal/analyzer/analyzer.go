@@ -1,22 +1,37 @@
 package analyzer
 
 import (
-	"bufio"
+	"bytes"
+	"errors"
 	"fmt"
 	"go/parser"
 	"go/token"
 	"io/fs"
+	"log"
 	"os"
+	"path"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
 
-	"github.com/scopweb/mcp-context-server/internal/config"
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/scopweb/mcp-go-context/internal/config"
 )
 
 // ProjectAnalyzer analyzes project structure and content
 type ProjectAnalyzer struct {
-	config config.ContextConfig
-	cache  map[string]*FileInfo
+	config    config.ContextConfig
+	deps      config.DependencyConfig
+	cache     *fileCache
+	types     map[string]*TypeIndex // module dir -> type-checked packages
+	typesFset *token.FileSet
+	typesMu   sync.Mutex // guards types and typesFset
+	calls     *CallGraph
+	callsMu   sync.Mutex // guards calls
+	search    *searchIndex
+	vectors   *vectorIndex // nil when embeddings are off
+	tokenizer Tokenizer
 }
 
 // FileInfo contains information about a file
@@ -27,16 +42,71 @@ type FileInfo struct {
 	Imports      []string
 	Functions    []string
 	Types        []string
+	Symbols      []Symbol
+	Package      string
+	IsMain       bool
+	Lines        int
 	LastModified int64
 }
 
 // ProjectStructure represents the analyzed project
 type ProjectStructure struct {
-	RootPath     string
-	Files        []*FileInfo
-	Dependencies []Dependency
-	Structure    map[string][]string // directory -> files
-	Stats        ProjectStats
+	RootPath           string
+	Files              []*FileInfo
+	Dependencies       []Dependency
+	DependencyWarnings []string            // manifests that could not be parsed
+	Structure          map[string][]string // directory -> files
+	Stats              ProjectStats
+	Implementations    []InterfaceImpl
+	Skipped            SkipSummary
+}
+
+// AnalyzeOptions limits what AnalyzeProject walks
+type AnalyzeOptions struct {
+	Depth       int      // maximum directory depth below the root; 0 or negative means unlimited
+	Include     []string // only files matching one of these globs are analyzed
+	Exclude     []string // files and directories matching these globs are skipped
+	MaxFiles    int      // stop after this many files; 0 uses the configured limit
+	MaxFileSize int64    // skip larger files (bytes); 0 uses the configured limit
+	Interfaces  bool     // map interfaces to their implementations, which type-checks the module
+}
+
+// SkipSummary counts what the walk skipped, by reason
+type SkipSummary struct {
+	Counts   map[string]int      // reason -> count
+	Examples map[string][]string // reason -> first few relative paths
+	Limits   map[string]string   // reason -> the limit or patterns applied
+}
+
+const maxSkipExamples = 5
+
+// maxRelevantFiles bounds the files GetRelevantContext finds for a query
+const maxRelevantFiles = 20
+
+// minSectionTokens is the smallest remaining budget worth another file
+const minSectionTokens = 32
+
+// Skip reasons reported in SkipSummary
+const (
+	SkipIgnored     = "ignored"
+	SkipExcluded    = "excluded"
+	SkipNotIncluded = "not included"
+	SkipDepth       = "depth limit"
+	SkipTooLarge    = "too large"
+	SkipFileLimit   = "file limit"
+)
+
+func (s *SkipSummary) add(reason, limit, relPath string) {
+	if s.Counts == nil {
+		s.Counts = make(map[string]int)
+		s.Examples = make(map[string][]string)
+		s.Limits = make(map[string]string)
+	}
+	s.Counts[reason]++
+	s.Limits[reason] = limit
+	if len(s.Examples[reason]) < maxSkipExamples {
+		s.Examples[reason] = append(s.Examples[reason], filepath.ToSlash(relPath))
+	}
 }
 
 // ProjectStats contains project statistics
@@ -51,22 +121,61 @@ type ProjectStats struct {
 
 // Dependency represents a project dependency
 type Dependency struct {
-	Name    string
-	Version string
-	Type    string // direct, indirect
-	Path    string
+	Name       string
+	Version    string
+	Type       string   // direct, dev, peer, optional, indirect
+	Path       string   // manifest declaring the dependency
+	Module     string   // module or package requiring the dependency
+	Ecosystem  string   // go, npm, python, cargo, maven, gradle, nuget
+	Constraint string   // version range declared in the manifest, when it differs from Version
+	Extras     []string // optional features requested (python extras, cargo features)
+	Markers    string   // condition limiting where it is installed (markers, cfg, MSBuild)
 }
 
-// New creates a new project analyzer
-func New(cfg config.ContextConfig) (*ProjectAnalyzer, error) {
-	return &ProjectAnalyzer{
-		config: cfg,
-		cache:  make(map[string]*FileInfo),
-	}, nil
+// New creates a new project analyzer. File analysis results are persisted
+// under cacheCfg.Directory when caching is enabled.
+func New(cfg config.ContextConfig, cacheCfg config.CacheConfig, depCfg config.DependencyConfig) (*ProjectAnalyzer, error) {
+	a := &ProjectAnalyzer{
+		config:    cfg,
+		deps:      depCfg,
+		cache:     newFileCache(cacheCfg),
+		types:     make(map[string]*TypeIndex),
+		typesFset: token.NewFileSet(),
+		search:    newSearchIndex(),
+	}
+
+	tokenizer, err := newTokenizer(cfg)
+	if err != nil {
+		log.Printf("analyzer: tokenizer: %v, using the bundled one", err)
+		if tokenizer, err = bundledTokenizer(); err != nil {
+			return nil, err
+		}
+	}
+	a.tokenizer = tokenizer
+
+	embedder, err := newEmbedder(cfg.Embeddings)
+	if err != nil {
+		log.Printf("analyzer: %v, using hash embeddings", err)
+		embedder, _ = newHashEmbedder(cfg.Embeddings)
+	}
+	if embedder != nil {
+		vectorDir := ""
+		if cacheCfg.Enabled {
+			vectorDir = cacheCfg.Directory
+		}
+		a.vectors = newVectorIndex(embedder, vectorDir)
+	}
+
+	return a, nil
 }
 
 // AnalyzeProject performs a comprehensive project analysis
 func (a *ProjectAnalyzer) AnalyzeProject(rootPath string, depth int) (*ProjectStructure, error) {
+	return a.AnalyzeProjectWithOptions(rootPath, AnalyzeOptions{Depth: depth})
+}
+
+// AnalyzeProjectWithOptions performs a project analysis limited by opts
+func (a *ProjectAnalyzer) AnalyzeProjectWithOptions(rootPath string, opts AnalyzeOptions) (*ProjectStructure, error) {
 	absPath, err := filepath.Abs(rootPath)
 	if err != nil {
 		return nil, fmt.Errorf("invalid path: %w", err)
@@ -81,33 +190,31 @@ func (a *ProjectAnalyzer) AnalyzeProject(rootPath string, depth int) (*ProjectSt
 		},
 	}
 
-	// Walk project directory
-	err = filepath.WalkDir(absPath, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return nil
-		}
+	walk, err := a.walkProject(absPath, opts, a.newIgnoreMatcher(absPath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk directory: %w", err)
+	}
+	ps.Skipped = walk.skipped
+	for _, dir := range walk.dirs {
+		ps.Structure[dir] = []string{}
+	}
+	candidates := walk.files
+
+	// Analyze files concurrently; results keep walk order. Type information
+	// is rebuilt lazily once a Go source changed.
+	goChanges := a.cache.GoChanges()
+	infos := a.analyzeFiles(candidates)
+	if a.cache.GoChanges() != goChanges {
+		a.invalidateTypes()
+	}
 
-		// Check ignore patterns
-		if a.shouldIgnore(path) {
-			if d.IsDir() {
-				return filepath.SkipDir
-			}
-			return nil
+	for i, path := range candidates {
+		info := infos[i]
+		if info == nil {
+			continue
 		}
 
 		relPath, _ := filepath.Rel(absPath, path)
-
-		if d.IsDir() {
-			ps.Structure[relPath] = []string{}
-			return nil
-		}
-
-		// Analyze file
-		info, err := a.analyzeFile(path)
-		if err != nil {
-			return nil
-		}
-
 		ps.Files = append(ps.Files, info)
 
 		// Update structure
@@ -117,41 +224,180 @@ func (a *ProjectAnalyzer) AnalyzeProject(rootPath string, depth int) (*ProjectSt
 		// Update stats
 		ps.Stats.TotalFiles++
 		ps.Stats.TotalSize += info.Size
+		ps.Stats.TotalLines += info.Lines
 		ps.Stats.Languages[info.Language]++
 
-		return nil
-	})
+		if info.IsMain {
+			ps.Stats.MainPackages = appendUnique(ps.Stats.MainPackages, filepath.Dir(relPath))
+		}
+		if filepath.Base(path) == "go.mod" {
+			if module := readModulePath(path); module != "" {
+				ps.Stats.GoModules = appendUnique(ps.Stats.GoModules, module)
+			}
+		}
+	}
 
-	if err != nil {
-		return nil, fmt.Errorf("failed to walk directory: %w", err)
+	a.saveCache()
+
+	// Map interfaces to their implementations (Go modules only)
+	if opts.Interfaces {
+		if impls, err := a.InterfaceMap(absPath); err == nil {
+			ps.Implementations = impls
+		}
 	}
 
 	// Analyze dependencies if Go project
 	if a.config.AutoDetectDeps {
-		deps, err := a.AnalyzeDependencies(false)
+		deps, warnings, err := a.AnalyzeDependencies(false)
 		if err == nil {
 			ps.Dependencies = deps
+			ps.DependencyWarnings = warnings
 		}
 	}
 
 	return ps, nil
 }
 
-// analyzeFile analyzes a single file
-func (a *ProjectAnalyzer) analyzeFile(path string) (*FileInfo, error) {
-	// Check cache
-	if info, exists := a.cache[path]; exists {
-		stat, err := os.Stat(path)
-		if err == nil && stat.ModTime().Unix() == info.LastModified {
-			return info, nil
+// projectWalk lists what an analysis of a directory covers
+type projectWalk struct {
+	files   []string // to analyze, in walk order
+	dirs    []string // relative to the walked directory
+	skipped SkipSummary
+}
+
+// walkProject collects the files below dir that opts select. Ignored,
+// excluded, not included, too deep and too large entries are skipped, as are
+// the files beyond opts.MaxFiles. Zero limits use the configured ones.
+func (a *ProjectAnalyzer) walkProject(dir string, opts AnalyzeOptions, ignore *ignoreMatcher) (*projectWalk, error) {
+	if opts.MaxFiles <= 0 {
+		opts.MaxFiles = a.config.MaxFiles
+	}
+	if opts.MaxFileSize <= 0 {
+		opts.MaxFileSize = int64(a.config.MaxFileSizeKB) * 1024
+	}
+
+	walk := &projectWalk{}
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+
+		relPath, _ := filepath.Rel(dir, path)
+		slashPath := filepath.ToSlash(relPath)
+
+		// skip records a skipped entry, pruning directories
+		skip := func(reason, limit string) error {
+			walk.skipped.add(reason, limit, relPath)
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		// Check ignore patterns
+		if path != dir && ignore.Match(path, d.IsDir()) {
+			return skip(SkipIgnored, ".gitignore, .mcpignore and ignorePatterns")
 		}
+		if path != dir && matchAnyGlob(opts.Exclude, slashPath) {
+			return skip(SkipExcluded, "matching "+strings.Join(opts.Exclude, ", "))
+		}
+
+		if d.IsDir() {
+			if opts.Depth > 0 && path != dir && strings.Count(slashPath, "/")+1 > opts.Depth {
+				return skip(SkipDepth, fmt.Sprintf("deeper than %d", opts.Depth))
+			}
+			walk.dirs = append(walk.dirs, relPath)
+			return nil
+		}
+
+		if len(opts.Include) > 0 && !matchAnyGlob(opts.Include, slashPath) {
+			return skip(SkipNotIncluded, "not matching "+strings.Join(opts.Include, ", "))
+		}
+		if opts.MaxFiles > 0 && len(walk.files) >= opts.MaxFiles {
+			return skip(SkipFileLimit, fmt.Sprintf("more than %d files", opts.MaxFiles))
+		}
+		stat, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if opts.MaxFileSize > 0 && stat.Size() > opts.MaxFileSize {
+			return skip(SkipTooLarge, fmt.Sprintf("over %d KB", opts.MaxFileSize/1024))
+		}
+
+		walk.files = append(walk.files, path)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return walk, nil
+}
+
+// invalidateTypes drops type information and the call graph so they are
+// rebuilt on next use
+func (a *ProjectAnalyzer) invalidateTypes() {
+	a.typesMu.Lock()
+	a.types = make(map[string]*TypeIndex)
+	a.typesFset = token.NewFileSet()
+	a.typesMu.Unlock()
+
+	a.callsMu.Lock()
+	a.calls = nil
+	a.callsMu.Unlock()
+}
+
+// analyzeFiles analyzes paths with a bounded worker pool. The result slice is
+// index-aligned with paths; files that fail to analyze are nil.
+func (a *ProjectAnalyzer) analyzeFiles(paths []string) []*FileInfo {
+	infos := make([]*FileInfo, len(paths))
+
+	workers := a.config.Concurrency
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > len(paths) {
+		workers = len(paths)
 	}
 
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if info, err := a.analyzeFile(paths[i]); err == nil {
+					infos[i] = info
+				}
+			}
+		}()
+	}
+
+	for i := range paths {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return infos
+}
+
+// analyzeFile analyzes a single file, reusing the cached result while the
+// file's size and modification time (or content hash) are unchanged
+func (a *ProjectAnalyzer) analyzeFile(path string) (*FileInfo, error) {
 	stat, err := os.Stat(path)
 	if err != nil {
+		a.cache.Delete(path)
 		return nil, err
 	}
 
+	if info, ok := a.cache.Get(path, stat); ok {
+		return info, nil
+	}
+
 	info := &FileInfo{
 		Path:         path,
 		Size:         stat.Size(),
@@ -160,26 +406,31 @@ func (a *ProjectAnalyzer) analyzeFile(path string) (*FileInfo, error) {
 	}
 
 	// Special handling for Go files
+	hash := ""
 	if strings.HasSuffix(path, ".go") {
-		a.analyzeGoFile(path, info)
+		if content, err := os.ReadFile(path); err == nil {
+			hash = hashContent(content)
+			a.analyzeGoFile(path, content, info)
+		}
+	} else if info.Language != "text" {
+		info.Lines = countLines(path)
 	}
 
-	// Cache the result
-	a.cache[path] = info
+	a.cache.Put(path, info, stat, hash)
 
 	return info, nil
 }
 
 // analyzeGoFile performs Go-specific analysis
-func (a *ProjectAnalyzer) analyzeGoFile(path string, info *FileInfo) error {
-	content, err := os.ReadFile(path)
-	if err != nil {
-		return err
+func (a *ProjectAnalyzer) analyzeGoFile(path string, content []byte, info *FileInfo) error {
+	info.Lines = bytes.Count(content, []byte("\n"))
+	if len(content) > 0 && content[len(content)-1] != '\n' {
+		info.Lines++
 	}
 
 	fset := token.NewFileSet()
-	node, err := parser.ParseFile(fset, path, content, parser.ImportsOnly)
-	if err != nil {
+	node, err := parser.ParseFile(fset, path, content, parser.ParseComments)
+	if node == nil {
 		return err
 	}
 
@@ -189,188 +440,255 @@ func (a *ProjectAnalyzer) analyzeGoFile(path string, info *FileInfo) error {
 		info.Imports = append(info.Imports, importPath)
 	}
 
-	return nil
+	// Extract declarations (partial results are kept for files with syntax errors)
+	info.Package = node.Name.Name
+	info.IsMain = hasMainFunc(node)
+	info.Symbols = extractSymbols(fset, node, path)
+	for _, sym := range info.Symbols {
+		switch sym.Kind {
+		case "func", "method":
+			info.Functions = append(info.Functions, sym.QualifiedName())
+		case "struct", "interface", "type":
+			info.Types = append(info.Types, sym.Name)
+		}
+	}
+
+	return err
 }
 
-// GetRelevantContext retrieves context relevant to a query
+// GetRelevantContext retrieves context relevant to a query, within maxTokens
+// tokens as counted by the configured tokenizer
 func (a *ProjectAnalyzer) GetRelevantContext(query string, files []string, maxTokens int) (string, error) {
 	var context strings.Builder
-	tokenCount := 0
 
-	context.WriteString(fmt.Sprintf("# Context for: %s\n\n", query))
+	header := fmt.Sprintf("# Context for: %s\n\n", query)
+	context.WriteString(header)
+	tokenCount := a.tokenizer.Count(header)
 
 	// If specific files requested
 	if len(files) > 0 {
 		for _, file := range files {
-			content, err := a.getFileContext(file, maxTokens-tokenCount)
-			if err != nil {
-				continue
-			}
-			context.WriteString(content)
-			tokenCount += len(content) / 4 // Approximate token count
-
-			if tokenCount >= maxTokens {
+			if maxTokens-tokenCount < minSectionTokens {
 				break
 			}
-		}
-	} else {
-		// Find relevant files based on query
-		relevantFiles := a.findRelevantFiles(query)
-		for _, file := range relevantFiles {
-			content, err := a.getFileContext(file.Path, maxTokens-tokenCount)
+			content, err := a.getFileContext(file, query, maxTokens-tokenCount)
 			if err != nil {
 				continue
 			}
 			context.WriteString(content)
-			tokenCount += len(content) / 4
-
-			if tokenCount >= maxTokens {
+			tokenCount += a.tokenizer.Count(content)
+		}
+	} else {
+		// Find relevant files based on query, best matches first
+		for _, hit := range a.findRelevantFiles(query) {
+			if maxTokens-tokenCount < minSectionTokens {
 				break
 			}
+			content := a.formatSearchHit(hit, maxTokens-tokenCount)
+			context.WriteString(content)
+			tokenCount += a.tokenizer.Count(content)
 		}
 	}
 
 	return context.String(), nil
 }
 
-// AnalyzeDependencies analyzes project dependencies
-func (a *ProjectAnalyzer) AnalyzeDependencies(includeTransitive bool) ([]Dependency, error) {
-	var deps []Dependency
-
-	// Check for go.mod
-	goModPath := filepath.Join(a.config.ProjectPaths[0], "go.mod")
-	if _, err := os.Stat(goModPath); err == nil {
-		deps, err = a.parseGoMod(goModPath, includeTransitive)
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	// TODO: Add support for other dependency files (package.json, requirements.txt, etc.)
+// CountTokens counts the tokens of text with the configured tokenizer
+func (a *ProjectAnalyzer) CountTokens(text string) int {
+	return a.tokenizer.Count(text)
+}
 
-	return deps, nil
+// TruncateTokens cuts text to at most maxTokens tokens
+func (a *ProjectAnalyzer) TruncateTokens(text string, maxTokens int) string {
+	return a.tokenizer.Truncate(text, maxTokens)
 }
 
-// parseGoMod parses go.mod file for dependencies
-func (a *ProjectAnalyzer) parseGoMod(path string, includeTransitive bool) ([]Dependency, error) {
-	file, err := os.Open(path)
-	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
+// TokenizerName names the configured tokenizer
+func (a *ProjectAnalyzer) TokenizerName() string {
+	return a.tokenizer.Name()
+}
 
+// AnalyzeDependencies analyzes the dependencies of every project path with
+// each registered DependencyParser that detects a manifest there. A parser
+// that fails does not stop the others: its error is returned as a warning,
+// and an error only when every parser failed.
+func (a *ProjectAnalyzer) AnalyzeDependencies(includeTransitive bool) ([]Dependency, []string, error) {
 	var deps []Dependency
-	scanner := bufio.NewScanner(file)
-	inRequire := false
-
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
+	var warnings []string
+	var errs []error
+	parsed := 0
+	seen := make(map[string]bool)
 
-		if line == "require (" {
-			inRequire = true
-			continue
-		}
-
-		if inRequire && line == ")" {
-			inRequire = false
+	for _, root := range a.config.ProjectPaths {
+		absRoot, err := filepath.Abs(root)
+		if err != nil {
 			continue
 		}
 
-		if inRequire || strings.HasPrefix(line, "require ") {
-			parts := strings.Fields(line)
-			if len(parts) >= 2 {
-				name := parts[0]
-				if name == "require" {
-					name = parts[1]
-				}
-				version := ""
-				if len(parts) > 2 {
-					version = parts[2]
-				} else if len(parts) > 1 && parts[0] != "require" {
-					version = parts[1]
-				}
-
-				depType := "direct"
-				if strings.Contains(line, "// indirect") {
-					depType = "indirect"
-				}
-
-				if includeTransitive || depType == "direct" {
-					deps = append(deps, Dependency{
-						Name:    name,
-						Version: version,
-						Type:    depType,
-						Path:    path,
-					})
+		for _, parser := range DependencyParsers() {
+			if !parser.Detect(absRoot) {
+				continue
+			}
+			found, err := parser.Parse(absRoot, includeTransitive)
+			if err != nil {
+				err = fmt.Errorf("%s dependencies of %s: %w", parser.Ecosystem(), absRoot, err)
+				warnings = append(warnings, err.Error())
+				errs = append(errs, err)
+				continue
+			}
+			parsed++
+
+			// Project paths may share a manifest, e.g. modules of one go.work
+			for _, dep := range found {
+				key := dep.Ecosystem + "\x00" + dep.Path + "\x00" + dep.Module + "\x00" + dep.Name + "\x00" + dep.Version + "\x00" + dep.Type
+				if !seen[key] {
+					seen[key] = true
+					deps = append(deps, dep)
 				}
 			}
 		}
 	}
 
-	return deps, scanner.Err()
+	if parsed == 0 && len(errs) > 0 {
+		return nil, nil, errors.Join(errs...)
+	}
+	return deps, warnings, nil
 }
 
 // Helper methods
 
-func (a *ProjectAnalyzer) shouldIgnore(path string) bool {
-	for _, pattern := range a.config.IgnorePatterns {
-		if matched, _ := filepath.Match(pattern, filepath.Base(path)); matched {
-			return true
-		}
-		if strings.Contains(path, pattern) {
-			return true
-		}
+// findRelevantFiles ranks the project files for query by BM25 over their
+// contents, paths and declared symbols, blended with the similarity of
+// their closest chunk when embeddings are enabled
+func (a *ProjectAnalyzer) findRelevantFiles(query string) []searchHit {
+	a.ensureIndexed()
+	files := a.cache.Snapshot()
+	a.search.sync(files)
+
+	if a.vectors == nil {
+		hits := a.search.Search(query, maxRelevantFiles)
+		addSnippets(hits, query)
+		return hits
 	}
-	return false
-}
 
-func (a *ProjectAnalyzer) findRelevantFiles(query string) []*FileInfo {
-	var relevant []*FileInfo
-	queryLower := strings.ToLower(query)
+	// Candidates beyond the final count let either ranking promote a file
+	hits := a.search.Search(query, 3*maxRelevantFiles)
+	if err := a.vectors.sync(files); err != nil {
+		log.Printf("analyzer: embedding failed, using keyword search only: %v", err)
+	} else if semantic, err := a.vectors.search(query, 3*maxRelevantFiles); err != nil {
+		log.Printf("analyzer: embedding failed, using keyword search only: %v", err)
+	} else {
+		hits = blendHits(hits, semantic, a.config.Embeddings.Weight)
+	}
+	if len(hits) > maxRelevantFiles {
+		hits = hits[:maxRelevantFiles]
+	}
+	addSnippets(hits, query)
+	return hits
+}
 
-	for _, file := range a.cache {
-		score := 0
+// formatSearchHit renders the snippet of a search hit within maxTokens
+func (a *ProjectAnalyzer) formatSearchHit(hit searchHit, maxTokens int) string {
+	header := fmt.Sprintf("\n## File: %s (line %d, score %.2f)\n\n```%s\n", hit.Path, hit.StartLine, hit.Score, detectLanguage(hit.Path))
+	return a.fitSection(header, hit.Snippet, maxTokens)
+}
 
-		// Check filename
-		if strings.Contains(strings.ToLower(filepath.Base(file.Path)), queryLower) {
-			score += 10
-		}
+// getFileContext renders a file within maxTokens: whole when it fits, else
+// an excerpt of the parts relevant to query
+func (a *ProjectAnalyzer) getFileContext(path, query string, maxTokens int) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
 
-		// Check imports (for Go files)
-		for _, imp := range file.Imports {
-			if strings.Contains(strings.ToLower(imp), queryLower) {
-				score += 5
-			}
-		}
+	header := fmt.Sprintf("\n## File: %s\n\n```%s\n", path, detectLanguage(path))
+	const footer = "\n```\n\n"
+	if result := header + string(content) + footer; a.tokenizer.Count(result) <= maxTokens {
+		return result, nil
+	}
 
-		if score > 0 {
-			relevant = append(relevant, file)
-		}
+	budget := maxTokens - a.tokenizer.Count(header+footer)
+	if budget < minSectionTokens {
+		return "", nil
 	}
+	return header + a.excerptFile(path, string(content), query, budget) + footer, nil
+}
 
-	// Sort by relevance (implement sorting if needed)
+// fitSection renders a fenced section, truncating body so that the whole
+// section takes at most maxTokens tokens; it is empty when no part of body fits
+func (a *ProjectAnalyzer) fitSection(header, body string, maxTokens int) string {
+	const footer = "\n```\n\n"
+	const truncated = "\n... (truncated)\n"
 
-	return relevant
+	result := header + body + footer
+	if a.tokenizer.Count(result) <= maxTokens {
+		return result
+	}
+
+	budget := maxTokens - a.tokenizer.Count(header+truncated+footer)
+	for budget > 0 {
+		result = header + a.tokenizer.Truncate(body, budget) + truncated + footer
+		// Tokens can merge across the joins, so check the whole section
+		excess := a.tokenizer.Count(result) - maxTokens
+		if excess <= 0 {
+			return result
+		}
+		budget -= excess
+	}
+	return ""
 }
 
-func (a *ProjectAnalyzer) getFileContext(path string, maxChars int) (string, error) {
-	content, err := os.ReadFile(path)
+// countLines counts the lines of a file like analyzeGoFile does, reading it
+// in chunks so that lines of any length are counted
+func countLines(path string) int {
+	file, err := os.Open(path)
 	if err != nil {
-		return "", err
+		return 0
 	}
+	defer file.Close()
 
-	result := fmt.Sprintf("\n## File: %s\n\n```%s\n", path, detectLanguage(path))
-
-	if len(content) > maxChars {
-		result += string(content[:maxChars])
-		result += "\n... (truncated)\n"
-	} else {
-		result += string(content)
+	lines := 0
+	last := byte('\n')
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := file.Read(buf)
+		if n > 0 {
+			lines += bytes.Count(buf[:n], []byte("\n"))
+			last = buf[n-1]
+		}
+		if err != nil {
+			break
+		}
+	}
+	if last != '\n' {
+		lines++
 	}
+	return lines
+}
 
-	result += "\n```\n\n"
+// matchAnyGlob reports whether relPath (slash-separated) matches any doublestar
+// pattern. Patterns without a slash match the base name.
+func matchAnyGlob(patterns []string, relPath string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.TrimPrefix(filepath.ToSlash(pattern), "./")
+		name := relPath
+		if !strings.Contains(pattern, "/") {
+			name = path.Base(relPath)
+		}
+		if ok, _ := doublestar.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
 
-	return result, nil
+func appendUnique(list []string, value string) []string {
+	for _, v := range list {
+		if v == value {
+			return list
+		}
+	}
+	return append(list, value)
 }
 
 func detectLanguage(path string) string {
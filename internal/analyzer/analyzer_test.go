@@ -0,0 +1,155 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/scopweb/mcp-go-context/internal/config"
+)
+
+func newTestAnalyzer(t *testing.T, root string) *ProjectAnalyzer {
+	t.Helper()
+	a, err := New(config.ContextConfig{ProjectPaths: []string{root}, Tokenizer: "chars"}, config.CacheConfig{}, config.DependencyConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestAnalyzeProjectKeepsTypesUntilGoChanges(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.mod":    "module example.com/m\n\ngo 1.21\n",
+		"main.go":   "package main\n\nfunc main() {}\n",
+		"README.md": "# m\n",
+	})
+	a := newTestAnalyzer(t, root)
+
+	typesKept := func() bool {
+		a.typesMu.Lock()
+		defer a.typesMu.Unlock()
+		return a.types["sentinel"] != nil
+	}
+	analyze := func() {
+		t.Helper()
+		a.typesMu.Lock()
+		if a.types["sentinel"] == nil {
+			a.types["sentinel"] = &TypeIndex{}
+		}
+		a.typesMu.Unlock()
+		if _, err := a.AnalyzeProject(root, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	analyze()
+	analyze()
+	if !typesKept() {
+		t.Error("types dropped by a walk with no changes")
+	}
+
+	writeFiles(t, root, map[string]string{"README.md": "# m\n\nChanged.\n"})
+	analyze()
+	if !typesKept() {
+		t.Error("types dropped after a change to a non-Go file")
+	}
+
+	writeFiles(t, root, map[string]string{"util.go": "package main\n\nfunc util() {}\n"})
+	analyze()
+	if typesKept() {
+		t.Error("types kept after a Go file was added")
+	}
+}
+
+func TestAnalyzeProjectInterfacesOptIn(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.mod":   "module example.com/m\n\ngo 1.21\n",
+		"shape.go": "package m\n\ntype Shape interface{ Area() float64 }\n\ntype Square struct{ Side float64 }\n\nfunc (s Square) Area() float64 { return s.Side * s.Side }\n",
+	})
+	a := newTestAnalyzer(t, root)
+
+	ps, err := a.AnalyzeProject(root, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ps.Implementations) != 0 {
+		t.Errorf("Implementations = %v without Interfaces, want none", ps.Implementations)
+	}
+
+	ps, err = a.AnalyzeProjectWithOptions(root, AnalyzeOptions{Interfaces: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ps.Implementations) != 1 || len(ps.Implementations[0].Implementations) != 1 {
+		t.Errorf("Implementations = %+v, want Shape implemented by Square", ps.Implementations)
+	}
+}
+
+func TestAnalyzeDependenciesKeepsOtherEcosystems(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.mod":       "module example.com/m\n\ngo 1.21\n\nrequire github.com/pkg/errors v0.9.1\n",
+		"package.json": "{ not json",
+	})
+	a := newTestAnalyzer(t, root)
+
+	deps, warnings, err := a.AnalyzeDependencies(false)
+	if err != nil {
+		t.Fatalf("AnalyzeDependencies() error = %v, want go dependencies and a warning", err)
+	}
+	if len(deps) != 1 || deps[0].Name != "github.com/pkg/errors" {
+		t.Errorf("deps = %+v, want github.com/pkg/errors", deps)
+	}
+	if len(warnings) != 1 || !strings.HasPrefix(warnings[0], "npm dependencies") {
+		t.Errorf("warnings = %q, want one npm warning", warnings)
+	}
+
+	// Nothing parsed at all is an error
+	if err := os.Remove(filepath.Join(root, "go.mod")); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := a.AnalyzeDependencies(false); err == nil {
+		t.Error("AnalyzeDependencies() with only a broken manifest succeeded, want an error")
+	}
+}
+
+func TestCountLines(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    int
+	}{
+		{"empty", "", 0},
+		{"one line", "a\n", 1},
+		{"no final newline", "a\nb", 2},
+		{"blank lines", "\n\n\n", 3},
+		{"long line", strings.Repeat("x", 2<<20) + "\nend\n", 2},
+		{"long last line", "start\n" + strings.Repeat("x", 100<<10), 2},
+	}
+	dir := t.TempDir()
+	for _, tt := range tests {
+		path := filepath.Join(dir, tt.name)
+		if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if got := countLines(path); got != tt.want {
+			t.Errorf("countLines(%s) = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
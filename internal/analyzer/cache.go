@@ -0,0 +1,343 @@
+package analyzer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/scopweb/mcp-go-context/internal/config"
+)
+
+const cacheFileName = "file-index.json"
+
+// fileCache is a concurrency-safe FileInfo cache, optionally persisted to
+// CacheConfig.Directory so restarts don't require a full rescan. Persisted
+// entries only become visible to queries once a walk has revalidated them.
+type fileCache struct {
+	config  config.CacheConfig
+	path    string
+	mu      sync.RWMutex
+	entries map[string]*cacheEntry
+	dirty   bool
+
+	goChanges uint64 // count of changes to Go source entries
+}
+
+// cacheEntry is a cached analysis result with the data used to validate it
+type cacheEntry struct {
+	Info     *FileInfo `json:"info"`
+	ModTime  int64     `json:"modTime"` // UnixNano
+	Size     int64     `json:"size"`
+	Hash     string    `json:"hash,omitempty"` // sha256 of the content, when it was read
+	CachedAt time.Time `json:"cachedAt"`
+	LastUsed time.Time `json:"lastUsed"`
+
+	live bool // seen by a walk in this process
+}
+
+// newFileCache creates the cache and loads persisted entries when enabled
+func newFileCache(cfg config.CacheConfig) *fileCache {
+	c := &fileCache{
+		config:  cfg,
+		entries: make(map[string]*cacheEntry),
+	}
+
+	if cfg.Enabled && cfg.Directory != "" {
+		c.path = filepath.Join(expandHome(cfg.Directory), cacheFileName)
+		c.load()
+	}
+
+	return c
+}
+
+// Get returns the cached info for path if it is still valid for stat. When only
+// the modification time changed, content is compared by hash before rescanning.
+func (c *fileCache) Get(path string, stat os.FileInfo) (*FileInfo, bool) {
+	modTime := stat.ModTime().UnixNano()
+
+	c.mu.Lock()
+	entry, exists := c.entries[path]
+	if !exists || c.expired(entry) || entry.Size != stat.Size() {
+		c.mu.Unlock()
+		return nil, false
+	}
+	if entry.ModTime == modTime {
+		c.touch(entry, modTime)
+		c.mu.Unlock()
+		return entry.Info, true
+	}
+	hash := entry.Hash
+	c.mu.Unlock()
+
+	// Hash outside the lock, then check the entry was not replaced meanwhile
+	if hash == "" || hashFile(path) != hash {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries[path] != entry {
+		return nil, false
+	}
+	c.touch(entry, modTime)
+	return entry.Info, true
+}
+
+// touch marks entry as used and live; c.mu must be held
+func (c *fileCache) touch(entry *cacheEntry, modTime int64) {
+	entry.ModTime = modTime
+	entry.LastUsed = time.Now()
+	entry.live = true
+}
+
+// sameContent reports whether the entry describes the file of stat and hash.
+// Contents are compared by hash when both are known, else by modification time.
+func (e *cacheEntry) sameContent(stat os.FileInfo, hash string) bool {
+	if e == nil || e.Size != stat.Size() {
+		return false
+	}
+	if e.Hash != "" && hash != "" {
+		return e.Hash == hash
+	}
+	return e.ModTime == stat.ModTime().UnixNano()
+}
+
+// Put stores info for path; hash may be empty when the content was not read
+func (c *fileCache) Put(path string, info *FileInfo, stat os.FileInfo, hash string) {
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if previous := c.entries[path]; isGoSource(path) && !previous.sameContent(stat, hash) {
+		c.goChanges++
+	}
+	c.entries[path] = &cacheEntry{
+		Info:     info,
+		ModTime:  stat.ModTime().UnixNano(),
+		Size:     stat.Size(),
+		Hash:     hash,
+		CachedAt: now,
+		LastUsed: now,
+		live:     true,
+	}
+	c.dirty = true
+}
+
+// Delete removes path from the cache, reporting whether it was live
+func (c *fileCache) Delete(path string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, exists := c.entries[path]
+	if !exists {
+		return false
+	}
+	delete(c.entries, path)
+	c.dirty = true
+	if isGoSource(path) {
+		c.goChanges++
+	}
+	return entry.live
+}
+
+// DeleteTree removes path and everything below it, reporting whether any live
+// file was removed
+func (c *fileCache) DeleteTree(path string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := false
+	prefix := path + string(filepath.Separator)
+	for p, entry := range c.entries {
+		if p == path || strings.HasPrefix(p, prefix) {
+			delete(c.entries, p)
+			c.dirty = true
+			removed = removed || entry.live
+			if isGoSource(p) {
+				c.goChanges++
+			}
+		}
+	}
+	return removed
+}
+
+// GoChanges counts the changes to Go source entries: additions, removals and
+// content changes. Type information built from older sources is stale once
+// the count moves.
+func (c *fileCache) GoChanges() uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.goChanges
+}
+
+// Snapshot returns the infos of all live files sorted by path
+func (c *fileCache) Snapshot() []*FileInfo {
+	c.mu.RLock()
+	infos := make([]*FileInfo, 0, len(c.entries))
+	for _, entry := range c.entries {
+		if entry.live {
+			infos = append(infos, entry.Info)
+		}
+	}
+	c.mu.RUnlock()
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Path < infos[j].Path
+	})
+	return infos
+}
+
+// Live reports whether path is a live file of the index
+func (c *fileCache) Live(path string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, exists := c.entries[path]
+	return exists && entry.live
+}
+
+// Len returns the number of live files
+func (c *fileCache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	count := 0
+	for _, entry := range c.entries {
+		if entry.live {
+			count++
+		}
+	}
+	return count
+}
+
+// Save persists the cache if it changed. Expired entries are dropped and the
+// least recently used ones are left out of the file to stay within MaxSizeMB.
+func (c *fileCache) Save() error {
+	if c.path == "" {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.dirty {
+		return nil
+	}
+
+	persist := make(map[string]*cacheEntry, len(c.entries))
+	for path, entry := range c.entries {
+		if !c.expired(entry) {
+			persist[path] = entry
+		} else if !entry.live {
+			delete(c.entries, path)
+		}
+	}
+
+	data, err := json.Marshal(persist)
+	if err != nil {
+		return err
+	}
+
+	if limit := int64(c.config.MaxSizeMB) * 1024 * 1024; limit > 0 && int64(len(data)) > limit {
+		evict(persist, int64(len(data))-limit)
+		if data, err = json.Marshal(persist); err != nil {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	// Write atomically so a crash never leaves a truncated index
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		return err
+	}
+
+	c.dirty = false
+	return nil
+}
+
+// evict drops least recently used entries until roughly excess bytes are freed
+func evict(entries map[string]*cacheEntry, excess int64) {
+	paths := make([]string, 0, len(entries))
+	for path := range entries {
+		paths = append(paths, path)
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		return entries[paths[i]].LastUsed.Before(entries[paths[j]].LastUsed)
+	})
+
+	for _, path := range paths {
+		if excess <= 0 {
+			break
+		}
+		if data, err := json.Marshal(entries[path]); err == nil {
+			excess -= int64(len(data))
+		}
+		delete(entries, path)
+	}
+}
+
+func (c *fileCache) load() {
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return
+	}
+
+	var entries map[string]*cacheEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return
+	}
+
+	for path, entry := range entries {
+		if entry == nil || entry.Info == nil || c.expired(entry) {
+			continue
+		}
+		c.entries[path] = entry
+	}
+}
+
+func (c *fileCache) expired(entry *cacheEntry) bool {
+	if c.config.TTLMinutes <= 0 {
+		return false
+	}
+	return time.Since(entry.CachedAt) > time.Duration(c.config.TTLMinutes)*time.Minute
+}
+
+func hashContent(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+func hashFile(path string) string {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return hashContent(content)
+}
+
+// expandHome resolves a leading ~ in configured paths
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
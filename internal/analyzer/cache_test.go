@@ -0,0 +1,112 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/scopweb/mcp-go-context/internal/config"
+)
+
+func TestFileCacheGet(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.go")
+	content := []byte("package main\n")
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	stat, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := newFileCache(config.CacheConfig{})
+	info := &FileInfo{Path: path}
+	c.Put(path, info, stat, hashContent(content))
+
+	if got, ok := c.Get(path, stat); !ok || got != info {
+		t.Fatalf("Get() = %v, %v; want cached info", got, ok)
+	}
+
+	// Touched without changing the content: revalidated by hash
+	later := stat.ModTime().Add(time.Hour)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	touched, _ := os.Stat(path)
+	if _, ok := c.Get(path, touched); !ok {
+		t.Error("Get() after touch missed; want a hit by hash")
+	}
+
+	// Same size, different content
+	if err := os.WriteFile(path, []byte("package test\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	changed, _ := os.Stat(path)
+	if changed.ModTime().Equal(touched.ModTime()) {
+		changed = fakeStat{changed, later.Add(time.Hour)}
+	}
+	if _, ok := c.Get(path, changed); ok {
+		t.Error("Get() after change hit; want a miss")
+	}
+}
+
+func TestFileCacheConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	var paths []string
+	var stats []os.FileInfo
+	for _, name := range []string{"a.go", "b.go", "c.go"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("package x\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		stat, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		paths, stats = append(paths, path), append(stats, stat)
+	}
+
+	c := newFileCache(config.CacheConfig{TTLMinutes: 60})
+	hash := hashContent([]byte("package x\n"))
+	for k, path := range paths {
+		c.Put(path, &FileInfo{Path: path}, stats[k], hash)
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				k := (w + i) % len(paths)
+				stat := stats[k]
+				if i%3 == 0 {
+					// A changed modification time forces the hash path
+					stat = fakeStat{stat, stat.ModTime().Add(time.Duration(i) * time.Second)}
+				}
+				if i%50 == w {
+					c.Put(paths[k], &FileInfo{Path: paths[k]}, stat, hash)
+				} else {
+					c.Get(paths[k], stat)
+				}
+				c.Len()
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	if n := c.Len(); n != len(paths) {
+		t.Errorf("Len() = %d, want %d", n, len(paths))
+	}
+}
+
+// fakeStat overrides the modification time of a FileInfo
+type fakeStat struct {
+	os.FileInfo
+	modTime time.Time
+}
+
+func (s fakeStat) ModTime() time.Time { return s.modTime }
@@ -0,0 +1,391 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"sort"
+	"strings"
+)
+
+const maxCallDepth = 5
+
+// CallGraph is a static call graph over the project's Go packages
+type CallGraph struct {
+	Nodes   map[string]*CallNode // keyed by types.Func.FullName
+	modules []string
+}
+
+// CallNode is a function or method in the call graph
+type CallNode struct {
+	Key      string
+	Name     string // display name, e.g. server.(*Server).Start
+	Package  string
+	Receiver string
+	Func     string
+	Path     string
+	Line     int
+	External bool
+	Out      []CallSite
+	In       []CallSite
+}
+
+// CallSite is a single call from Caller to Callee
+type CallSite struct {
+	Caller  string
+	Callee  string
+	Path    string
+	Line    int
+	Dynamic bool // resolved through an interface method to a concrete implementation
+}
+
+// Callers renders functions calling symbol, up to depth levels
+func (a *ProjectAnalyzer) Callers(symbol string, depth int) (string, error) {
+	return a.renderCalls(symbol, depth, true)
+}
+
+// Callees renders functions called by symbol, up to depth levels
+func (a *ProjectAnalyzer) Callees(symbol string, depth int) (string, error) {
+	return a.renderCalls(symbol, depth, false)
+}
+
+func (a *ProjectAnalyzer) renderCalls(symbol string, depth int, callers bool) (string, error) {
+	symbol = strings.TrimSpace(symbol)
+	if symbol == "" {
+		return "", fmt.Errorf("function name required")
+	}
+	if depth <= 0 {
+		depth = 1
+	}
+	if depth > maxCallDepth {
+		depth = maxCallDepth
+	}
+
+	graph := a.CallGraph()
+	if graph == nil {
+		return "", fmt.Errorf("call graph unavailable: no Go module found in project paths")
+	}
+
+	nodes := graph.Lookup(symbol)
+	if len(nodes) == 0 {
+		return fmt.Sprintf("# %s\n\nNo function found.\n", symbol), nil
+	}
+
+	title := "Callees of"
+	if callers {
+		title = "Callers of"
+	}
+
+	var result strings.Builder
+	for _, node := range nodes {
+		result.WriteString(fmt.Sprintf("# %s `%s`\n\n", title, node.Name))
+		if node.Path != "" {
+			result.WriteString(fmt.Sprintf("Defined at `%s:%d`\n\n", a.displayPath(node.Path), node.Line))
+		}
+
+		visited := map[string]bool{node.Key: true}
+		count := a.writeCallTree(&result, graph, node, callers, 0, depth, visited)
+		if count == 0 {
+			if callers {
+				result.WriteString("No callers found in the project.\n")
+			} else {
+				result.WriteString("No calls found.\n")
+			}
+		}
+		result.WriteString("\n")
+	}
+
+	result.WriteString(fmt.Sprintf("_Type information: %s_\n", typeStatus(a.typeIndexes())))
+	return result.String(), nil
+}
+
+func (a *ProjectAnalyzer) writeCallTree(result *strings.Builder, graph *CallGraph, node *CallNode, callers bool, level, depth int, visited map[string]bool) int {
+	sites := node.Out
+	if callers {
+		sites = node.In
+	}
+
+	count := 0
+	for _, site := range sites {
+		key := site.Callee
+		if callers {
+			key = site.Caller
+		}
+		next := graph.Nodes[key]
+		if next == nil {
+			continue
+		}
+
+		line := fmt.Sprintf("%s- `%s` at `%s:%d`", strings.Repeat("  ", level), next.Name, a.displayPath(site.Path), site.Line)
+		if site.Dynamic {
+			line += " _(via interface)_"
+		}
+		if visited[key] {
+			line += " _(recursive)_"
+		}
+		result.WriteString(line + "\n")
+		count++
+
+		if level+1 < depth && !visited[key] {
+			visited[key] = true
+			a.writeCallTree(result, graph, next, callers, level+1, depth, visited)
+			delete(visited, key)
+		}
+	}
+
+	return count
+}
+
+// CallGraph returns the cached call graph, building it from type information if needed
+func (a *ProjectAnalyzer) CallGraph() *CallGraph {
+	a.callsMu.Lock()
+	defer a.callsMu.Unlock()
+
+	if a.calls != nil {
+		return a.calls
+	}
+
+	indexes := a.typeIndexes()
+	if len(indexes) == 0 {
+		return nil
+	}
+
+	a.calls = buildCallGraph(indexes)
+	return a.calls
+}
+
+// Lookup returns project functions matching Name, Type.Method, pkg.Name or pkg.Type.Method
+func (g *CallGraph) Lookup(symbol string) []*CallNode {
+	parts := strings.Split(strings.ReplaceAll(symbol, "*", ""), ".")
+
+	var found []*CallNode
+	for _, node := range g.Nodes {
+		if node.External {
+			continue
+		}
+		ok := false
+		switch len(parts) {
+		case 1:
+			ok = node.Func == parts[0]
+		case 2:
+			ok = node.Func == parts[1] && (node.Receiver == parts[0] || (node.Receiver == "" && node.Package == parts[0]))
+		case 3:
+			ok = node.Package == parts[0] && node.Receiver == parts[1] && node.Func == parts[2]
+		}
+		if ok {
+			found = append(found, node)
+		}
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		return found[i].Key < found[j].Key
+	})
+	return found
+}
+
+func buildCallGraph(indexes []*TypeIndex) *CallGraph {
+	g := &CallGraph{Nodes: make(map[string]*CallNode)}
+	for _, index := range indexes {
+		g.modules = append(g.modules, index.Modules...)
+	}
+
+	// Method implementations by name, for expanding interface calls
+	impls := make(map[string][]*types.Func)
+	for _, tn := range projectTypeNames(indexes) {
+		if types.IsInterface(tn.Type()) {
+			continue
+		}
+		mset := types.NewMethodSet(types.NewPointer(tn.Type()))
+		for i := 0; i < mset.Len(); i++ {
+			fn := mset.At(i).Obj().(*types.Func)
+			impls[fn.Name()] = append(impls[fn.Name()], fn)
+		}
+	}
+
+	for _, index := range indexes {
+		for _, pkg := range index.Packages {
+			if pkg.Info == nil {
+				continue
+			}
+
+			for _, file := range pkg.Files {
+				for _, decl := range file.Decls {
+					fd, ok := decl.(*ast.FuncDecl)
+					if !ok || fd.Body == nil {
+						continue
+					}
+					caller, ok := pkg.Info.Defs[fd.Name].(*types.Func)
+					if !ok {
+						continue
+					}
+					callerNode := g.node(index, caller, false)
+
+					ast.Inspect(fd.Body, func(n ast.Node) bool {
+						call, ok := n.(*ast.CallExpr)
+						if !ok {
+							return true
+						}
+
+						callee := calledFunc(pkg.Info, call)
+						if callee == nil {
+							return true
+						}
+
+						pos := index.Fset.Position(call.Lparen)
+						if recv := callee.Type().(*types.Signature).Recv(); recv != nil && types.IsInterface(recv.Type()) {
+							// Interface call: link the abstract method and each concrete implementation
+							g.addEdge(callerNode, g.node(index, callee, true), pos.Filename, pos.Line, false)
+							iface, _ := recv.Type().Underlying().(*types.Interface)
+							for _, impl := range impls[callee.Name()] {
+								implRecv := impl.Type().(*types.Signature).Recv()
+								if iface != nil && implRecv != nil && (types.Implements(implRecv.Type(), iface) || types.Implements(types.NewPointer(implRecv.Type()), iface)) {
+									g.addEdge(callerNode, g.node(index, impl, false), pos.Filename, pos.Line, true)
+								}
+							}
+							return true
+						}
+
+						g.addEdge(callerNode, g.node(index, callee, false), pos.Filename, pos.Line, false)
+						return true
+					})
+				}
+			}
+		}
+	}
+
+	for _, node := range g.Nodes {
+		sortSites(node.In)
+		sortSites(node.Out)
+	}
+
+	return g
+}
+
+// calledFunc resolves the static callee of a call expression, if any
+func calledFunc(info *types.Info, call *ast.CallExpr) *types.Func {
+	fun := call.Fun
+	for {
+		paren, ok := fun.(*ast.ParenExpr)
+		if !ok {
+			break
+		}
+		fun = paren.X
+	}
+
+	// Strip explicit generic instantiation
+	switch f := fun.(type) {
+	case *ast.IndexExpr:
+		fun = f.X
+	case *ast.IndexListExpr:
+		fun = f.X
+	}
+
+	var ident *ast.Ident
+	switch f := fun.(type) {
+	case *ast.Ident:
+		ident = f
+	case *ast.SelectorExpr:
+		ident = f.Sel
+	default:
+		return nil
+	}
+
+	fn, ok := info.Uses[ident].(*types.Func)
+	if !ok {
+		return nil
+	}
+	return fn.Origin()
+}
+
+func (g *CallGraph) node(index *TypeIndex, fn *types.Func, abstract bool) *CallNode {
+	key := fn.FullName()
+	if node, exists := g.Nodes[key]; exists {
+		return node
+	}
+
+	node := &CallNode{
+		Key:  key,
+		Func: fn.Name(),
+	}
+
+	if fn.Pkg() != nil {
+		node.Package = fn.Pkg().Name()
+		node.External = !g.isProjectPackage(fn.Pkg().Path())
+	}
+
+	node.Name = node.Package + "." + fn.Name()
+	if recv := fn.Type().(*types.Signature).Recv(); recv != nil {
+		recvType := recv.Type()
+		pointer := false
+		if ptr, ok := recvType.(*types.Pointer); ok {
+			recvType = ptr.Elem()
+			pointer = true
+		}
+
+		prefix := node.Package + "."
+		if named, ok := recvType.(*types.Named); ok {
+			node.Receiver = named.Obj().Name()
+			if named.Obj().Pkg() == nil {
+				prefix = ""
+			}
+		} else {
+			node.Receiver = "interface{...}"
+			prefix = ""
+		}
+
+		switch {
+		case abstract:
+			node.Name = fmt.Sprintf("%s%s.%s (interface)", prefix, node.Receiver, fn.Name())
+		case pointer:
+			node.Name = fmt.Sprintf("%s(*%s).%s", prefix, node.Receiver, fn.Name())
+		default:
+			node.Name = fmt.Sprintf("%s%s.%s", prefix, node.Receiver, fn.Name())
+		}
+	}
+
+	if fn.Pos().IsValid() && !node.External {
+		pos := index.Fset.Position(fn.Pos())
+		node.Path = pos.Filename
+		node.Line = pos.Line
+	}
+
+	g.Nodes[key] = node
+	return node
+}
+
+func (g *CallGraph) addEdge(caller, callee *CallNode, path string, line int, dynamic bool) {
+	// Repeated calls on the same line are reported once
+	for _, existing := range caller.Out {
+		if existing.Callee == callee.Key && existing.Path == path && existing.Line == line {
+			return
+		}
+	}
+
+	site := CallSite{
+		Caller:  caller.Key,
+		Callee:  callee.Key,
+		Path:    path,
+		Line:    line,
+		Dynamic: dynamic,
+	}
+	caller.Out = append(caller.Out, site)
+	callee.In = append(callee.In, site)
+}
+
+func (g *CallGraph) isProjectPackage(path string) bool {
+	for _, module := range g.modules {
+		if path == module || strings.HasPrefix(path, module+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func sortSites(sites []CallSite) {
+	sort.SliceStable(sites, func(i, j int) bool {
+		if sites[i].Path != sites[j].Path {
+			return sites[i].Path < sites[j].Path
+		}
+		return sites[i].Line < sites[j].Line
+	})
+}
@@ -0,0 +1,122 @@
+package analyzer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCallGraphLookup(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, shopFiles)
+	graph := newTestAnalyzer(t, root).CallGraph()
+	if graph == nil {
+		t.Fatal("no call graph")
+	}
+
+	tests := []struct {
+		symbol string
+		want   []string
+	}{
+		{"Checkout", []string{"app.Checkout"}},
+		{"app.subtotal", []string{"app.subtotal"}},
+		{"*Product.Price", []string{"store.Product.Price"}},
+		{"store.Pricer.Price", []string{"store.Pricer.Price (interface)"}},
+		{"Price", []string{"store.Pricer.Price (interface)", "store.Product.Price"}},
+		{"*Base.Touch", []string{"store.(*Base).Touch"}},
+		{"Nowhere", nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, node := range graph.Lookup(tt.symbol) {
+			got = append(got, node.Name)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Lookup(%q) = %q, want %q", tt.symbol, got, tt.want)
+		}
+	}
+}
+
+func TestCallersAndCallees(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, shopFiles)
+	a := newTestAnalyzer(t, root)
+
+	tests := []struct {
+		name    string
+		callers bool
+		symbol  string
+		depth   int
+		want    string
+	}{
+		{"callees depth 1", false, "Checkout", 1, "" +
+			"- `app.subtotal` at `app/app.go:6`\n" +
+			"- `store.Product.Price` at `app/app.go:6`\n\n"},
+		{"callees through an interface", false, "Checkout", 3, "" +
+			"- `app.subtotal` at `app/app.go:6`\n" +
+			"  - `store.Total` at `app/app.go:11`\n" +
+			"    - `store.Pricer.Price (interface)` at `store/store.go:32`\n" +
+			"    - `store.Product.Price` at `store/store.go:32` _(via interface)_\n" +
+			"- `store.Product.Price` at `app/app.go:6`\n\n"},
+		{"callers through an interface", true, "Product.Price", 5, "" +
+			"- `app.Checkout` at `app/app.go:6`\n" +
+			"- `store.Total` at `store/store.go:32` _(via interface)_\n" +
+			"  - `app.subtotal` at `app/app.go:11`\n" +
+			"    - `app.Checkout` at `app/app.go:6`\n\n"},
+		{"no callers", true, "Checkout", 2, "No callers found in the project.\n"},
+		{"no callees", false, "Product.Price", 2, "No calls found.\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			render := a.Callees
+			if tt.callers {
+				render = a.Callers
+			}
+			got, err := render(tt.symbol, tt.depth)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(got, "`\n\n"+tt.want) {
+				t.Errorf("got:\n%s\nwant tree:\n%s", got, tt.want)
+			}
+		})
+	}
+
+	if got, _ := a.Callers("Nobody", 1); !strings.Contains(got, "No function found.") {
+		t.Errorf("Callers(Nobody) = %q", got)
+	}
+}
+
+func TestCallGraphRecursion(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.mod": "module example.com/rec\n\ngo 1.21\n",
+		"rec.go": `package rec
+
+func Even(n int) bool {
+	if n == 0 {
+		return true
+	}
+	return Odd(n - 1)
+}
+
+func Odd(n int) bool {
+	if n == 0 {
+		return false
+	}
+	return Even(n - 1)
+}
+`,
+	})
+
+	got, err := newTestAnalyzer(t, root).Callees("Even", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "" +
+		"- `rec.Odd` at `rec.go:7`\n" +
+		"  - `rec.Even` at `rec.go:14` _(recursive)_\n\n"
+	if !strings.Contains(got, want) {
+		t.Errorf("got:\n%s\nwant tree:\n%s", got, want)
+	}
+}
@@ -0,0 +1,284 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+func init() {
+	mustRegisterDependencyParser(cargoParser{})
+}
+
+// cargoParser reads Cargo.toml manifests, including workspace members, with
+// versions resolved from Cargo.lock
+type cargoParser struct{}
+
+// cargoManifest is the subset of Cargo.toml used for dependency analysis
+type cargoManifest struct {
+	Package struct {
+		Name string `toml:"name"`
+	} `toml:"package"`
+	Workspace struct {
+		Members      []string               `toml:"members"`
+		Exclude      []string               `toml:"exclude"`
+		Dependencies map[string]interface{} `toml:"dependencies"`
+	} `toml:"workspace"`
+	Dependencies      map[string]interface{} `toml:"dependencies"`
+	DevDependencies   map[string]interface{} `toml:"dev-dependencies"`
+	BuildDependencies map[string]interface{} `toml:"build-dependencies"`
+	Target            map[string]struct {
+		Dependencies      map[string]interface{} `toml:"dependencies"`
+		DevDependencies   map[string]interface{} `toml:"dev-dependencies"`
+		BuildDependencies map[string]interface{} `toml:"build-dependencies"`
+	} `toml:"target"`
+}
+
+// cargoSection is a dependency table; build dependencies count as dev ones
+type cargoSection struct {
+	table   map[string]interface{}
+	depType string
+	target  string
+}
+
+func (cargoParser) Ecosystem() string { return "cargo" }
+
+func (cargoParser) Detect(root string) bool {
+	_, err := os.Stat(filepath.Join(root, "Cargo.toml"))
+	return err == nil
+}
+
+func (cargoParser) Parse(root string, includeTransitive bool) ([]Dependency, error) {
+	rootPath := filepath.Join(root, "Cargo.toml")
+	var rootManifest cargoManifest
+	if _, err := toml.DecodeFile(rootPath, &rootManifest); err != nil {
+		return nil, err
+	}
+
+	manifests := []string{rootPath}
+	manifests = append(manifests, cargoWorkspaceMembers(root, &rootManifest)...)
+
+	locked, lockFile := readCargoLock(root)
+
+	var deps []Dependency
+	declared := make(map[string]bool)
+	members := make(map[string]bool)
+	for _, path := range manifests {
+		manifest := &rootManifest
+		if path != rootPath {
+			manifest = new(cargoManifest)
+			if _, err := toml.DecodeFile(path, manifest); err != nil {
+				continue
+			}
+		}
+		// A virtual workspace root has no package of its own
+		if manifest.Package.Name == "" && path == rootPath && len(manifest.Workspace.Members) > 0 {
+			continue
+		}
+
+		module := manifest.Package.Name
+		if module == "" {
+			module = filepath.Base(filepath.Dir(path))
+		}
+		members[module] = true
+
+		sections := []cargoSection{
+			{manifest.Dependencies, "direct", ""},
+			{manifest.BuildDependencies, "dev", ""},
+			{manifest.DevDependencies, "dev", ""},
+		}
+		// Platform-specific dependencies keep their cfg() as markers
+		for _, target := range sortedKeys(manifest.Target) {
+			t := manifest.Target[target]
+			sections = append(sections,
+				cargoSection{t.Dependencies, "direct", target},
+				cargoSection{t.BuildDependencies, "dev", target},
+				cargoSection{t.DevDependencies, "dev", target})
+		}
+
+		seen := make(map[string]bool)
+		for _, section := range sections {
+			for _, key := range sortedKeys(section.table) {
+				dep := cargoDependency(key, section.table[key], rootManifest.Workspace.Dependencies)
+				if seen[dep.Name] {
+					continue
+				}
+				seen[dep.Name] = true
+
+				if dep.Type == "" {
+					dep.Type = section.depType
+				}
+				dep.Path = path
+				dep.Module = module
+				dep.Ecosystem = "cargo"
+				dep.Markers = section.target
+
+				dep.Version = cargoLockedVersion(locked[dep.Name], dep.Constraint)
+				if dep.Version == "" {
+					dep.Version = dep.Constraint
+				}
+				if dep.Version == dep.Constraint {
+					dep.Constraint = ""
+				}
+				declared[dep.Name+"@"+dep.Version] = true
+				deps = append(deps, dep)
+			}
+		}
+	}
+
+	// Other locked crates are transitive; workspace members are not dependencies
+	if includeTransitive && lockFile != "" {
+		module := rootManifest.Package.Name
+		if module == "" {
+			module = filepath.Base(root)
+		}
+		for _, name := range sortedKeys(locked) {
+			if members[name] {
+				continue
+			}
+			for _, version := range locked[name] {
+				if declared[name+"@"+version] {
+					continue
+				}
+				deps = append(deps, Dependency{
+					Name:      name,
+					Version:   version,
+					Type:      "indirect",
+					Path:      lockFile,
+					Module:    module,
+					Ecosystem: "cargo",
+				})
+			}
+		}
+	}
+
+	return deps, nil
+}
+
+// cargoDependency reads a dependency specification: a version string or a
+// table with version, features, optional, path, git, package and workspace keys
+func cargoDependency(key string, spec interface{}, workspaceDeps map[string]interface{}) Dependency {
+	dep := Dependency{Name: key}
+
+	table, ok := spec.(map[string]interface{})
+	if !ok {
+		dep.Constraint, _ = spec.(string)
+		return dep
+	}
+
+	// workspace = true inherits the workspace specification, adding features
+	if inherit, _ := table["workspace"].(bool); inherit {
+		base := cargoDependency(key, workspaceDeps[key], nil)
+		dep.Name, dep.Constraint, dep.Extras = base.Name, base.Constraint, base.Extras
+	}
+
+	// A renamed dependency is declared under its alias
+	if pkg, ok := table["package"].(string); ok {
+		dep.Name = pkg
+	}
+	if version, ok := table["version"].(string); ok {
+		dep.Constraint = version
+	} else if dep.Constraint == "" {
+		if git, ok := table["git"].(string); ok {
+			dep.Constraint = git
+		} else if path, ok := table["path"].(string); ok {
+			dep.Constraint = path
+		}
+	}
+	if features, ok := table["features"].([]interface{}); ok {
+		for _, feature := range features {
+			if s, ok := feature.(string); ok {
+				dep.Extras = append(dep.Extras, s)
+			}
+		}
+	}
+	if optional, _ := table["optional"].(bool); optional {
+		dep.Type = "optional"
+	}
+
+	return dep
+}
+
+// cargoWorkspaceMembers expands [workspace] members into member manifests
+func cargoWorkspaceMembers(root string, manifest *cargoManifest) []string {
+	excluded := make(map[string]bool)
+	for _, ex := range manifest.Workspace.Exclude {
+		excluded[filepath.Join(root, filepath.FromSlash(ex))] = true
+	}
+
+	var members []string
+	seen := make(map[string]bool)
+	for _, pattern := range manifest.Workspace.Members {
+		matches, _ := filepath.Glob(filepath.Join(root, filepath.FromSlash(pattern)))
+		for _, dir := range matches {
+			path := filepath.Join(dir, "Cargo.toml")
+			if dir == root || excluded[dir] || seen[path] {
+				continue
+			}
+			if _, err := os.Stat(path); err == nil {
+				seen[path] = true
+				members = append(members, path)
+			}
+		}
+	}
+	sort.Strings(members)
+	return members
+}
+
+// readCargoLock returns the locked versions of every crate in Cargo.lock
+func readCargoLock(root string) (map[string][]string, string) {
+	path := filepath.Join(root, "Cargo.lock")
+	var lock struct {
+		Package []struct {
+			Name    string `toml:"name"`
+			Version string `toml:"version"`
+		} `toml:"package"`
+	}
+	if _, err := toml.DecodeFile(path, &lock); err != nil {
+		return nil, ""
+	}
+
+	locked := make(map[string][]string)
+	for _, pkg := range lock.Package {
+		locked[pkg.Name] = append(locked[pkg.Name], pkg.Version)
+	}
+	return locked, path
+}
+
+// cargoLockedVersion picks the locked version matching a requirement. Cargo
+// requirements are caret ranges by default, so the leading non-zero version
+// component must match.
+func cargoLockedVersion(versions []string, constraint string) string {
+	if len(versions) <= 1 {
+		if len(versions) == 1 {
+			return versions[0]
+		}
+		return ""
+	}
+
+	want := strings.TrimLeft(constraint, "^~=>< ")
+	if i := strings.IndexAny(want, ", "); i >= 0 {
+		want = want[:i]
+	}
+	wantParts := strings.Split(want, ".")
+	for _, version := range versions {
+		parts := strings.Split(version, ".")
+		match := true
+		for i, part := range wantParts {
+			if i >= len(parts) || part != parts[i] {
+				match = false
+				break
+			}
+			if part != "0" {
+				break
+			}
+		}
+		if match {
+			return version
+		}
+	}
+	return ""
+}
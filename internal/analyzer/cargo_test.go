@@ -0,0 +1,179 @@
+package analyzer
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCargoParser(t *testing.T) {
+	lock := `version = 3
+
+[[package]]
+name = "app"
+version = "0.1.0"
+
+[[package]]
+name = "core"
+version = "0.1.0"
+
+[[package]]
+name = "serde"
+version = "1.0.190"
+
+[[package]]
+name = "serde_json"
+version = "1.0.108"
+
+[[package]]
+name = "rand"
+version = "0.7.3"
+
+[[package]]
+name = "rand"
+version = "0.8.5"
+
+[[package]]
+name = "libc"
+version = "0.2.150"
+`
+
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  []string
+	}{
+		{
+			name: "workspace members and excludes",
+			files: map[string]string{
+				"Cargo.toml": `[workspace]
+members = ["crates/*"]
+exclude = ["crates/skip"]
+
+[workspace.dependencies]
+serde = { version = "1.0", features = ["derive"] }
+rand = "0.8"
+`,
+				"crates/core/Cargo.toml": `[package]
+name = "core"
+
+[dependencies]
+serde = { workspace = true, features = ["rc"] }
+rand = { workspace = true, optional = true }
+`,
+				"crates/app/Cargo.toml": `[package]
+name = "app"
+
+[dependencies]
+core = { path = "../core" }
+rand = "0.7"
+`,
+				"crates/skip/Cargo.toml": `[package]
+name = "skip"
+
+[dependencies]
+skipped = "1"
+`,
+				"Cargo.lock": lock,
+			},
+			want: []string{
+				"core@0.1.0 direct app crates/app/Cargo.toml [../core]",
+				"rand@0.7.3 direct app crates/app/Cargo.toml [0.7]",
+				"rand@0.8.5 optional core crates/core/Cargo.toml [0.8]",
+				"serde@1.0.190 direct core crates/core/Cargo.toml [1.0] extras=[derive rc]",
+				"libc@0.2.150 indirect ws Cargo.lock",
+				"serde_json@1.0.108 indirect ws Cargo.lock",
+			},
+		},
+		{
+			name: "renamed and target-specific dependencies",
+			files: map[string]string{
+				"Cargo.toml": `[package]
+name = "app"
+
+[dependencies]
+json = { package = "serde_json", version = "1.0" }
+fork = { git = "https://github.com/example/fork" }
+
+[build-dependencies]
+cc = "1.0"
+
+[dev-dependencies]
+json = "0.1"
+
+[target.'cfg(unix)'.dependencies]
+libc = "0.2"
+
+[target.'cfg(windows)'.dev-dependencies]
+winapi = { version = "0.3", features = ["winuser"] }
+`,
+				"Cargo.lock": lock,
+			},
+			want: []string{
+				"fork@https://github.com/example/fork direct app Cargo.toml",
+				"serde_json@1.0.108 direct app Cargo.toml [1.0]",
+				"cc@1.0 dev app Cargo.toml",
+				"json@0.1 dev app Cargo.toml",
+				"libc@0.2.150 direct app Cargo.toml [0.2] ; cfg(unix)",
+				"winapi@0.3 dev app Cargo.toml extras=[winuser] ; cfg(windows)",
+				"core@0.1.0 indirect app Cargo.lock",
+				"rand@0.7.3 indirect app Cargo.lock",
+				"rand@0.8.5 indirect app Cargo.lock",
+				"serde@1.0.190 indirect app Cargo.lock",
+			},
+		},
+		{
+			name: "without a lockfile",
+			files: map[string]string{
+				"Cargo.toml": "[package]\nname = \"app\"\n\n[dependencies]\nserde = \"1\"\n",
+			},
+			want: []string{"serde@1 direct app Cargo.toml"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Transitive dependencies of a virtual workspace belong to its directory
+			root := filepath.Join(t.TempDir(), "ws")
+			writeFiles(t, root, tt.files)
+			if !(cargoParser{}).Detect(root) {
+				t.Fatal("Detect() = false")
+			}
+			deps, err := cargoParser{}.Parse(root, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := depStrings(root, deps); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"Cargo.toml": "[package\n"})
+	if _, err := (cargoParser{}).Parse(root, false); err == nil {
+		t.Error("expected an error for a malformed Cargo.toml")
+	}
+}
+
+func TestCargoLockedVersion(t *testing.T) {
+	tests := []struct {
+		versions   []string
+		constraint string
+		want       string
+	}{
+		{nil, "1.0", ""},
+		// A single locked version is used whatever the requirement says
+		{[]string{"1.0.0"}, "2", "1.0.0"},
+		{[]string{"0.7.3", "0.8.5"}, "0.8", "0.8.5"},
+		{[]string{"0.7.3", "0.8.5"}, "^0.7.1", "0.7.3"},
+		{[]string{"0.0.1", "0.0.2"}, "=0.0.2", "0.0.2"},
+		{[]string{"1.2.0", "2.0.1"}, ">=2.0, <3", "2.0.1"},
+		{[]string{"1.2.0", "2.0.1"}, "~1", "1.2.0"},
+		{[]string{"1.2.0", "2.0.1"}, "3", ""},
+	}
+	for _, tt := range tests {
+		if got := cargoLockedVersion(tt.versions, tt.constraint); got != tt.want {
+			t.Errorf("cargoLockedVersion(%q, %q) = %q, want %q", tt.versions, tt.constraint, got, tt.want)
+		}
+	}
+}
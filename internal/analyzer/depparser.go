@@ -0,0 +1,52 @@
+package analyzer
+
+import (
+	"fmt"
+	"sync"
+)
+
+// DependencyParser reads the dependency manifests of one ecosystem. Parsers
+// register themselves with RegisterDependencyParser.
+type DependencyParser interface {
+	// Ecosystem names the ecosystem, as reported in Dependency.Ecosystem
+	Ecosystem() string
+	// Detect reports whether root holds a manifest the parser understands
+	Detect(root string) bool
+	// Parse returns the dependencies declared by the manifests of root
+	Parse(root string, includeTransitive bool) ([]Dependency, error)
+}
+
+var (
+	dependencyParsersMu sync.RWMutex
+	dependencyParsers   []DependencyParser
+)
+
+// RegisterDependencyParser adds a parser for a new ecosystem. Parsers run in
+// registration order.
+func RegisterDependencyParser(parser DependencyParser) error {
+	dependencyParsersMu.Lock()
+	defer dependencyParsersMu.Unlock()
+
+	for _, p := range dependencyParsers {
+		if p.Ecosystem() == parser.Ecosystem() {
+			return fmt.Errorf("dependency parser %s already registered", parser.Ecosystem())
+		}
+	}
+	dependencyParsers = append(dependencyParsers, parser)
+	return nil
+}
+
+// DependencyParsers returns the registered parsers
+func DependencyParsers() []DependencyParser {
+	dependencyParsersMu.RLock()
+	defer dependencyParsersMu.RUnlock()
+
+	return append([]DependencyParser(nil), dependencyParsers...)
+}
+
+// mustRegisterDependencyParser registers a built-in parser
+func mustRegisterDependencyParser(parser DependencyParser) {
+	if err := RegisterDependencyParser(parser); err != nil {
+		panic(err)
+	}
+}
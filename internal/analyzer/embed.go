@@ -0,0 +1,267 @@
+package analyzer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/scopweb/mcp-go-context/internal/config"
+)
+
+// Embedder turns texts into vectors whose cosine similarity reflects how
+// related the texts are. Providers register with RegisterEmbeddingProvider.
+type Embedder interface {
+	// Name identifies the model; vectors of different names are not comparable
+	Name() string
+	// Embed returns one vector per text
+	Embed(texts []string) ([][]float32, error)
+}
+
+// EmbeddingProvider creates an Embedder from the embedding configuration
+type EmbeddingProvider func(cfg config.EmbeddingConfig) (Embedder, error)
+
+var (
+	embeddingProvidersMu sync.RWMutex
+	embeddingProviders   = make(map[string]EmbeddingProvider)
+)
+
+func init() {
+	mustRegisterEmbeddingProvider("hash", newHashEmbedder)
+	mustRegisterEmbeddingProvider("openai", newHTTPEmbedder)
+}
+
+// RegisterEmbeddingProvider makes a provider selectable by name in
+// context.embeddings.provider
+func RegisterEmbeddingProvider(name string, provider EmbeddingProvider) error {
+	embeddingProvidersMu.Lock()
+	defer embeddingProvidersMu.Unlock()
+
+	if _, exists := embeddingProviders[name]; exists {
+		return fmt.Errorf("embedding provider %s already registered", name)
+	}
+	embeddingProviders[name] = provider
+	return nil
+}
+
+// mustRegisterEmbeddingProvider registers a built-in provider
+func mustRegisterEmbeddingProvider(name string, provider EmbeddingProvider) {
+	if err := RegisterEmbeddingProvider(name, provider); err != nil {
+		panic(err)
+	}
+}
+
+// newEmbedder creates the configured embedder; it returns nil when semantic
+// search is turned off
+func newEmbedder(cfg config.EmbeddingConfig) (Embedder, error) {
+	name := cfg.Provider
+	switch name {
+	case "off", "none":
+		return nil, nil
+	case "":
+		name = "hash"
+	}
+
+	embeddingProvidersMu.RLock()
+	provider, ok := embeddingProviders[name]
+	embeddingProvidersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown embedding provider %q", name)
+	}
+	return provider(cfg)
+}
+
+// hashEmbedder is a dependency-free embedder using feature hashing of terms
+// and their character trigrams. It captures lexical rather than semantic
+// similarity, but tolerates different word forms and identifier styles.
+type hashEmbedder struct {
+	dimensions int
+}
+
+// trigramWeight is the weight of a character trigram relative to a term
+const trigramWeight = 0.5
+
+func newHashEmbedder(cfg config.EmbeddingConfig) (Embedder, error) {
+	dimensions := cfg.Dimensions
+	if dimensions <= 0 {
+		dimensions = 256
+	}
+	return &hashEmbedder{dimensions: dimensions}, nil
+}
+
+func (e *hashEmbedder) Name() string {
+	return fmt.Sprintf("hash-%d", e.dimensions)
+}
+
+func (e *hashEmbedder) Embed(texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vector := make([]float32, e.dimensions)
+		counts := make(map[string]int)
+		for _, term := range searchTerms(text) {
+			counts[term]++
+		}
+		for term, count := range counts {
+			// Sublinear term frequency keeps repeated words from dominating
+			weight := 1 + math.Log(float64(count))
+			e.add(vector, term, weight)
+			padded := "^" + term + "$"
+			for j := 0; j+3 <= len(padded); j++ {
+				e.add(vector, padded[j:j+3], weight*trigramWeight)
+			}
+		}
+		vectors[i] = normalizeVector(vector)
+	}
+	return vectors, nil
+}
+
+// add adds weight to the bucket of feature, with a sign from another hash
+// bit so that collisions cancel out on average
+func (e *hashEmbedder) add(vector []float32, feature string, weight float64) {
+	h := fnv.New64a()
+	h.Write([]byte(feature))
+	sum := h.Sum64()
+	if sum>>63 == 1 {
+		weight = -weight
+	}
+	vector[sum%uint64(e.dimensions)] += float32(weight)
+}
+
+// httpEmbedder calls the /embeddings endpoint of an OpenAI-compatible API,
+// as served by OpenAI, Ollama, llama.cpp or vLLM
+type httpEmbedder struct {
+	url       string
+	model     string
+	apiKey    string
+	batchSize int
+	client    *http.Client
+}
+
+func newHTTPEmbedder(cfg config.EmbeddingConfig) (Embedder, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("embedding provider openai requires a url")
+	}
+	timeout := time.Duration(cfg.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	e := &httpEmbedder{
+		url:       strings.TrimSuffix(cfg.URL, "/") + "/embeddings",
+		model:     cfg.Model,
+		batchSize: cfg.BatchSize,
+		client:    &http.Client{Timeout: timeout},
+	}
+	if cfg.APIKeyEnv != "" {
+		e.apiKey = os.Getenv(cfg.APIKeyEnv)
+	}
+	if e.batchSize <= 0 {
+		e.batchSize = 32
+	}
+	return e, nil
+}
+
+func (e *httpEmbedder) Name() string {
+	return "openai-" + e.model
+}
+
+func (e *httpEmbedder) Embed(texts []string) ([][]float32, error) {
+	vectors := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += e.batchSize {
+		batch := texts[start:min(len(texts), start+e.batchSize)]
+		embedded, err := e.embedBatch(batch)
+		if err != nil {
+			return nil, err
+		}
+		vectors = append(vectors, embedded...)
+	}
+	return vectors, nil
+}
+
+func (e *httpEmbedder) embedBatch(texts []string) ([][]float32, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"model": e.model,
+		"input": texts,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", e.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+e.apiKey)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("embedding request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("embedding request failed: HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(data[:min(len(data), 200)])))
+	}
+
+	var result struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("invalid embedding response: %w", err)
+	}
+	if len(result.Data) != len(texts) {
+		return nil, fmt.Errorf("invalid embedding response: %d vectors for %d inputs", len(result.Data), len(texts))
+	}
+
+	vectors := make([][]float32, len(texts))
+	for i, item := range result.Data {
+		index := item.Index
+		if index < 0 || index >= len(texts) || vectors[index] != nil {
+			index = i
+		}
+		vectors[index] = normalizeVector(item.Embedding)
+	}
+	return vectors, nil
+}
+
+// normalizeVector scales a vector to unit length so that dot products are
+// cosine similarities
+func normalizeVector(vector []float32) []float32 {
+	var norm float64
+	for _, x := range vector {
+		norm += float64(x) * float64(x)
+	}
+	if norm == 0 {
+		return vector
+	}
+	scale := float32(1 / math.Sqrt(norm))
+	for i := range vector {
+		vector[i] *= scale
+	}
+	return vector
+}
+
+// dotProduct is the cosine similarity of two unit vectors
+func dotProduct(a, b []float32) float64 {
+	var sum float64
+	for i := 0; i < len(a) && i < len(b); i++ {
+		sum += float64(a[i]) * float64(b[i])
+	}
+	return sum
+}
@@ -0,0 +1,331 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// markerTokens is the estimated cost of a "... (lines N-M)" marker
+const markerTokens = 8
+
+// declaration is a top-level declaration of a file, by 0-based line
+type declaration struct {
+	name   string
+	start  int // first line of the comments above it
+	header int // line that declares it
+	end    int // exclusive
+}
+
+// How much of a declaration an excerpt shows
+const (
+	showHidden = iota
+	showSignature
+	showPartial
+	showFull
+)
+
+// excerptFile fits a file that is too large into budget tokens. The
+// declarations matching the query are shown whole with their doc comments,
+// the others by their first line, nearest to the matches first; everything
+// else is elided with line markers. Without a match the file is shown from
+// the top, declaration by declaration.
+func (a *ProjectAnalyzer) excerptFile(path, content, query string, budget int) string {
+	lines := strings.Split(content, "\n")
+	decls := fileDeclarations(path, content, lines)
+	terms := uniqueStrings(searchTerms(query))
+
+	if len(decls) == 0 {
+		// No structure to go by: the lines densest in query terms
+		start, snippet, _ := extractSnippet(content, terms)
+		if start > 1 {
+			snippet = fmt.Sprintf("... (lines 1-%d)\n", start-1) + snippet
+		}
+		return a.tokenizer.Truncate(snippet, budget-2*markerTokens) + "\n... (truncated)"
+	}
+
+	scores := make([]float64, len(decls))
+	matched := false
+	for i, d := range decls {
+		scores[i] = declarationScore(d, lines, terms)
+		matched = matched || scores[i] > 0
+	}
+
+	// Estimated costs; the rendered excerpt is checked at the end
+	fullCost := make([]int, len(decls))
+	signatureCost := make([]int, len(decls))
+	for i, d := range decls {
+		fullCost[i] = a.tokenizer.Count(strings.Join(lines[d.start:d.end], "\n")) + 1
+		signatureCost[i] = a.tokenizer.Count(lines[d.header]) + markerTokens
+	}
+	modes := make([]int, len(decls))
+	used := 2 * markerTokens
+	if p := packageLine(path, lines, decls); p >= 0 {
+		used += a.tokenizer.Count(lines[p])
+	}
+	var added []int // in order of addition, for trimming
+
+	// Whole declarations: the best matches, or those at the top of the file
+	order := make([]int, len(decls))
+	for i := range order {
+		order[i] = i
+	}
+	if matched {
+		sort.SliceStable(order, func(i, j int) bool { return scores[order[i]] > scores[order[j]] })
+	}
+	for _, i := range order {
+		if matched && scores[i] == 0 {
+			break
+		}
+		if used+fullCost[i]+markerTokens > budget {
+			if !matched {
+				break
+			}
+			continue
+		}
+		modes[i] = showFull
+		used += fullCost[i] + markerTokens
+		added = append(added, i)
+	}
+
+	// The best match is shown in part when it is too large to show whole,
+	// leaving a quarter of the budget for the signatures around it
+	partial := 0
+	if matched && len(added) == 0 {
+		best := order[0]
+		modes[best] = showPartial
+		partial = (budget-used)*3/4 - markerTokens
+		used += partial + markerTokens
+		added = append(added, best)
+	}
+
+	// Signatures of the remaining declarations, nearest to a shown one first
+	distance := func(i int) int {
+		nearest := math.MaxInt
+		for _, j := range added {
+			nearest = min(nearest, abs(i-j))
+		}
+		return nearest
+	}
+	var rest []int
+	for i := range decls {
+		if modes[i] == showHidden {
+			rest = append(rest, i)
+		}
+	}
+	sort.SliceStable(rest, func(i, j int) bool { return distance(rest[i]) < distance(rest[j]) })
+	for _, i := range rest {
+		if used+signatureCost[i] > budget {
+			continue
+		}
+		modes[i] = showSignature
+		used += signatureCost[i]
+		added = append(added, i)
+	}
+
+	// Drop the last additions until the rendered excerpt fits
+	for {
+		excerpt := a.renderExcerpt(path, lines, decls, modes, partial)
+		if a.tokenizer.Count(excerpt) <= budget {
+			return excerpt
+		}
+		if len(added) == 0 {
+			// Even the package clause and markers exceed the budget
+			return a.tokenizer.Truncate(excerpt, budget-2*markerTokens) + "\n... (truncated)"
+		}
+		last := added[len(added)-1]
+		added = added[:len(added)-1]
+		if modes[last] == showPartial {
+			partial -= a.tokenizer.Count(excerpt) - budget
+			if partial > 0 {
+				added = append(added, last)
+				continue
+			}
+		}
+		modes[last] = showHidden
+	}
+}
+
+// renderExcerpt writes the shown lines in file order, with a marker for
+// each elided range that is not blank
+func (a *ProjectAnalyzer) renderExcerpt(path string, lines []string, decls []declaration, modes []int, partial int) string {
+	var out []string
+	next := 0 // first line not yet shown or elided
+	elide := func(start, end int) {
+		for i := start; i < end; i++ {
+			if strings.TrimSpace(lines[i]) != "" {
+				out = append(out, fmt.Sprintf("... (lines %d-%d)", start+1, end))
+				return
+			}
+		}
+	}
+
+	if p := packageLine(path, lines, decls); p >= 0 {
+		elide(0, p)
+		out = append(out, lines[p])
+		next = p + 1
+	}
+
+	for i, d := range decls {
+		switch modes[i] {
+		case showFull:
+			elide(next, d.start)
+			out = append(out, lines[d.start:d.end]...)
+			next = d.end
+		case showPartial:
+			elide(next, d.start)
+			text := a.tokenizer.Truncate(strings.Join(lines[d.start:d.end], "\n"), partial)
+			shown := strings.Count(text, "\n") // complete lines only
+			out = append(out, lines[d.start:d.start+shown]...)
+			elide(d.start+shown, d.end)
+			next = d.end
+		case showSignature:
+			elide(next, d.start)
+			out = append(out, fmt.Sprintf("%s ... (lines %d-%d)", strings.TrimRight(lines[d.header], " \t{"), d.start+1, d.end))
+			next = d.end
+		}
+	}
+	elide(next, len(lines))
+	return strings.Join(out, "\n")
+}
+
+// declarationScore weighs the query terms in a declaration: those in its
+// name count most, the others sublinearly in their frequency
+func declarationScore(d declaration, lines []string, terms []string) float64 {
+	if len(terms) == 0 {
+		return 0
+	}
+	counts := make(map[string]int)
+	for _, term := range searchTerms(strings.Join(lines[d.start:d.end], "\n")) {
+		counts[term]++
+	}
+	names := make(map[string]bool)
+	for _, term := range searchTerms(d.name) {
+		names[term] = true
+	}
+
+	score := 0.0
+	for _, term := range terms {
+		if names[term] {
+			score += nameBoost
+		}
+		if n := counts[term]; n > 0 {
+			score += 1 + math.Log(float64(n))
+		}
+	}
+	return score
+}
+
+// fileDeclarations locates the top-level declarations of a file: parsed for
+// Go, by lines that look like declarations otherwise. Comments directly above
+// a declaration belong to it.
+func fileDeclarations(path, content string, lines []string) []declaration {
+	var decls []declaration
+	if filepath.Ext(path) == ".go" {
+		fset := token.NewFileSet()
+		node, err := parser.ParseFile(fset, path, content, parser.ParseComments)
+		if err == nil {
+			for _, decl := range node.Decls {
+				name, ok := declarationName(decl)
+				start, end := fset.Position(decl.Pos()).Line, fset.Position(decl.End()).Line
+				if ok && start > 0 && end <= len(lines) {
+					decls = append(decls, declaration{name: name, header: start - 1, end: end})
+				}
+			}
+		}
+	}
+	if decls == nil {
+		for i, line := range lines {
+			if declarationPattern.MatchString(line) {
+				decls = append(decls, declaration{name: strings.TrimSpace(line), header: i})
+			}
+		}
+		// Each declaration runs to the next one, without trailing blank lines
+		for i := range decls {
+			end := len(lines)
+			if i+1 < len(decls) {
+				end = decls[i+1].header
+			}
+			for end > decls[i].header+1 && strings.TrimSpace(lines[end-1]) == "" {
+				end--
+			}
+			decls[i].end = end
+		}
+	}
+
+	// Declarations in a group (const, var) can share lines; keep the first
+	sort.SliceStable(decls, func(i, j int) bool { return decls[i].header < decls[j].header })
+	var located []declaration
+	floor := 0
+	for _, d := range decls {
+		if d.header < floor {
+			continue
+		}
+		d.start = d.header
+		for d.start > floor && commentPattern.MatchString(lines[d.start-1]) {
+			d.start--
+		}
+		if d.end < d.header+1 {
+			d.end = d.header + 1
+		}
+		located = append(located, d)
+		floor = d.end
+	}
+	return located
+}
+
+// declarationName names a top-level Go declaration for scoring: a function
+// by its qualified name, a const, var or type declaration by the names it
+// declares. A group is one declaration, from its keyword to its closing
+// parenthesis, so that it is always shown as valid Go. Imports are skipped.
+func declarationName(decl ast.Decl) (string, bool) {
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		if d.Recv != nil && len(d.Recv.List) > 0 {
+			return receiverName(d.Recv.List[0].Type) + "." + d.Name.Name, true
+		}
+		return d.Name.Name, true
+	case *ast.GenDecl:
+		if d.Tok == token.IMPORT {
+			return "", false
+		}
+		var names []string
+		for _, spec := range d.Specs {
+			switch s := spec.(type) {
+			case *ast.TypeSpec:
+				names = append(names, s.Name.Name)
+			case *ast.ValueSpec:
+				for _, name := range s.Names {
+					names = append(names, name.Name)
+				}
+			}
+		}
+		return strings.Join(names, " "), true
+	}
+	return "", false
+}
+
+// packageLine returns the line of the package clause of a Go file, or -1
+func packageLine(path string, lines []string, decls []declaration) int {
+	if filepath.Ext(path) != ".go" || len(decls) == 0 {
+		return -1
+	}
+	for i, line := range lines[:decls[0].start] {
+		if strings.HasPrefix(line, "package ") {
+			return i
+		}
+	}
+	return -1
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,83 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+func TestFileDeclarationsGroups(t *testing.T) {
+	content := `package p
+
+import "fmt"
+
+// Modes of a widget
+const (
+	ModeA = iota
+	ModeB
+)
+
+// Widget is a widget
+type Widget struct{}
+
+func (w *Widget) Print() { fmt.Println(w) }
+`
+	lines := strings.Split(content, "\n")
+	decls := fileDeclarations("p.go", content, lines)
+
+	want := []declaration{
+		{name: "ModeA ModeB", start: 4, header: 5, end: 9},
+		{name: "Widget", start: 10, header: 11, end: 12},
+		{name: "Widget.Print", start: 13, header: 13, end: 14},
+	}
+	if len(decls) != len(want) {
+		t.Fatalf("fileDeclarations() = %+v, want %+v", decls, want)
+	}
+	for i := range want {
+		if decls[i] != want[i] {
+			t.Errorf("declaration %d = %+v, want %+v", i, decls[i], want[i])
+		}
+	}
+}
+
+func TestExcerptFileGroupIsValidGo(t *testing.T) {
+	a := &ProjectAnalyzer{tokenizer: charTokenizer{}}
+
+	var content strings.Builder
+	content.WriteString("package p\n\n")
+	for i := 0; i < 40; i++ {
+		fmt.Fprintf(&content, "// Helper%d does nothing useful\nfunc Helper%d() int { return %d }\n\n", i, i, i)
+	}
+	content.WriteString("// Retry limits\nconst (\n\tMaxRetries = 3\n\tRetryDelay = 5\n)\n")
+
+	excerpt := a.excerptFile("p.go", content.String(), "retries retry", 200)
+	if a.tokenizer.Count(excerpt) > 200 {
+		t.Errorf("excerpt is %d tokens, want at most 200", a.tokenizer.Count(excerpt))
+	}
+	if !strings.Contains(excerpt, "const (\n\tMaxRetries = 3\n\tRetryDelay = 5\n)") {
+		t.Fatalf("excerpt lacks the whole const group:\n%s", excerpt)
+	}
+
+	// Without the elision markers the shown code parses
+	var code []string
+	for _, line := range strings.Split(excerpt, "\n") {
+		if !strings.Contains(line, "... (lines") {
+			code = append(code, line)
+		}
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), "p.go", strings.Join(code, "\n"), 0); err != nil {
+		t.Errorf("excerpt is not valid Go: %v\n%s", err, excerpt)
+	}
+}
+
+func TestExcerptFileTinyBudget(t *testing.T) {
+	a := &ProjectAnalyzer{tokenizer: charTokenizer{}}
+	content := "package averyveryverylongpackagenamethatdoesnotfit\n\nfunc F() {}\n"
+	for _, budget := range []int{0, 5, 20} {
+		if n := a.tokenizer.Count(a.excerptFile("p.go", content, "f", budget)); n > max(budget, 5) {
+			t.Errorf("budget %d: excerpt is %d tokens", budget, n)
+		}
+	}
+}
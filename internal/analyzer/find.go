@@ -0,0 +1,332 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	maxSnippetLines = 60
+	maxReferences   = 100
+)
+
+// Reference is a use of a symbol somewhere in the project
+type Reference struct {
+	Path string
+	Line int
+	Text string
+}
+
+// FindSymbol locates definitions matching query (Name, Type.Method, pkg.Name or
+// pkg.Type.Method) and renders them as markdown, optionally with references
+func (a *ProjectAnalyzer) FindSymbol(query string, includeReferences bool) (string, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return "", fmt.Errorf("symbol name required")
+	}
+
+	a.ensureIndexed()
+
+	matches := a.lookupSymbols(query)
+	if len(matches) == 0 {
+		return fmt.Sprintf("# Symbol: %s\n\nNo definition found.\n", query), nil
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("# Symbol: %s\n\n", query))
+	if len(matches) > 1 {
+		result.WriteString(fmt.Sprintf("%d definitions found.\n\n", len(matches)))
+	}
+
+	for _, sym := range matches {
+		result.WriteString(fmt.Sprintf("## %s `%s.%s`\n\n", sym.Kind, sym.Package, sym.QualifiedName()))
+		result.WriteString(fmt.Sprintf("**Defined at**: `%s:%d`\n\n", a.displayPath(sym.Path), sym.StartLine))
+		result.WriteString(fmt.Sprintf("```go\n%s\n```\n\n", sym.Signature))
+
+		if sym.Doc != "" {
+			result.WriteString(sym.Doc)
+			result.WriteString("\n\n")
+		}
+
+		if snippet := readLines(sym.Path, sym.StartLine, sym.EndLine, maxSnippetLines); snippet != "" {
+			result.WriteString("### Source\n\n")
+			result.WriteString(fmt.Sprintf("```go\n%s```\n\n", snippet))
+		}
+
+		if includeReferences {
+			refs := a.findReferences(sym)
+			result.WriteString(fmt.Sprintf("### References (%d)\n\n", len(refs)))
+			for i, ref := range refs {
+				if i >= maxReferences {
+					result.WriteString(fmt.Sprintf("- ... and %d more\n", len(refs)-maxReferences))
+					break
+				}
+				result.WriteString(fmt.Sprintf("- `%s:%d` %s\n", a.displayPath(ref.Path), ref.Line, ref.Text))
+			}
+			result.WriteString("\n")
+		}
+	}
+
+	return result.String(), nil
+}
+
+// ensureIndexed analyzes the configured project paths when nothing is cached yet
+func (a *ProjectAnalyzer) ensureIndexed() {
+	if a.cache.Len() > 0 {
+		return
+	}
+	for _, root := range a.config.ProjectPaths {
+		a.AnalyzeProject(root, 0)
+	}
+}
+
+// lookupSymbols returns symbols matching a possibly qualified name
+func (a *ProjectAnalyzer) lookupSymbols(query string) []Symbol {
+	parts := strings.Split(query, ".")
+
+	match := func(fold bool) []Symbol {
+		eq := func(x, y string) bool {
+			if fold {
+				return strings.EqualFold(x, y)
+			}
+			return x == y
+		}
+
+		var found []Symbol
+		for _, file := range a.cache.Snapshot() {
+			for _, sym := range file.Symbols {
+				ok := false
+				switch len(parts) {
+				case 1:
+					ok = eq(sym.Name, parts[0])
+				case 2:
+					ok = eq(sym.Name, parts[1]) && (eq(sym.Receiver, parts[0]) || (sym.Receiver == "" && eq(sym.Package, parts[0])))
+				case 3:
+					ok = eq(sym.Package, parts[0]) && eq(sym.Receiver, parts[1]) && eq(sym.Name, parts[2])
+				}
+				if ok {
+					found = append(found, sym)
+				}
+			}
+		}
+		return found
+	}
+
+	found := match(false)
+	if len(found) == 0 {
+		found = match(true)
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].Exported != found[j].Exported {
+			return found[i].Exported
+		}
+		if found[i].Path != found[j].Path {
+			return found[i].Path < found[j].Path
+		}
+		return found[i].StartLine < found[j].StartLine
+	})
+
+	return found
+}
+
+// findReferences returns uses of sym, resolved through type information when
+// the module loads, otherwise by syntactic matching
+func (a *ProjectAnalyzer) findReferences(sym Symbol) []Reference {
+	if refs, ok := a.typedReferences(sym); ok {
+		return refs
+	}
+	return a.syntacticReferences(sym)
+}
+
+// typedReferences finds the object declared by sym and collects every use of it
+func (a *ProjectAnalyzer) typedReferences(sym Symbol) ([]Reference, bool) {
+	indexes := a.typeIndexes()
+
+	var target types.Object
+	for _, index := range indexes {
+		for _, pkg := range index.Packages {
+			if pkg.Info == nil || pkg.Dir != filepath.Dir(sym.Path) {
+				continue
+			}
+			for ident, obj := range pkg.Info.Defs {
+				if obj == nil || ident.Name != sym.Name {
+					continue
+				}
+				p := index.Fset.Position(ident.Pos())
+				if p.Filename == sym.Path && p.Line >= sym.StartLine && p.Line <= sym.EndLine {
+					target = obj
+					break
+				}
+			}
+		}
+	}
+	if target == nil {
+		return nil, false
+	}
+
+	var refs []Reference
+	lines := make(map[string][]string)
+	seen := make(map[string]bool)
+
+	for _, index := range indexes {
+		for _, pkg := range index.Packages {
+			if pkg.Info == nil {
+				continue
+			}
+			for ident, obj := range pkg.Info.Uses {
+				if obj != target {
+					continue
+				}
+				p := index.Fset.Position(ident.Pos())
+				key := fmt.Sprintf("%s:%d", p.Filename, p.Line)
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+
+				if _, ok := lines[p.Filename]; !ok {
+					content, _ := os.ReadFile(p.Filename)
+					lines[p.Filename] = strings.Split(string(content), "\n")
+				}
+				text := ""
+				if p.Line-1 < len(lines[p.Filename]) {
+					text = strings.TrimSpace(lines[p.Filename][p.Line-1])
+				}
+				refs = append(refs, Reference{Path: p.Filename, Line: p.Line, Text: "`" + text + "`"})
+			}
+		}
+	}
+
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].Path != refs[j].Path {
+			return refs[i].Path < refs[j].Path
+		}
+		return refs[i].Line < refs[j].Line
+	})
+
+	return refs, true
+}
+
+// syntacticReferences scans Go files for identifiers naming sym: same-package
+// uses by bare name, other packages via pkg.Name, and methods or fields via
+// any selector with the same name
+func (a *ProjectAnalyzer) syntacticReferences(sym Symbol) []Reference {
+	var refs []Reference
+
+	for _, file := range a.goFiles() {
+		path := file.Path
+		content, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+
+		fset := token.NewFileSet()
+		node, err := parser.ParseFile(fset, path, content, 0)
+		if node == nil {
+			continue
+		}
+
+		samePackage := file.Package == sym.Package && filepath.Dir(path) == filepath.Dir(sym.Path)
+		lines := strings.Split(string(content), "\n")
+		seen := make(map[int]bool)
+
+		add := func(pos token.Pos) {
+			p := fset.Position(pos)
+			if p.Filename == sym.Path && p.Line >= sym.StartLine && p.Line <= sym.EndLine {
+				return
+			}
+			if seen[p.Line] {
+				return
+			}
+			seen[p.Line] = true
+
+			text := ""
+			if p.Line-1 < len(lines) {
+				text = strings.TrimSpace(lines[p.Line-1])
+			}
+			refs = append(refs, Reference{Path: path, Line: p.Line, Text: "`" + text + "`"})
+		}
+
+		ast.Inspect(node, func(n ast.Node) bool {
+			switch x := n.(type) {
+			case *ast.SelectorExpr:
+				if x.Sel.Name != sym.Name {
+					return true
+				}
+				if sym.Receiver != "" {
+					add(x.Sel.Pos())
+				} else if pkg, ok := x.X.(*ast.Ident); ok && pkg.Name == sym.Package && !samePackage {
+					add(x.Sel.Pos())
+				}
+				return false
+			case *ast.Ident:
+				if samePackage && sym.Receiver == "" && x.Name == sym.Name {
+					add(x.Pos())
+				}
+			}
+			return true
+		})
+	}
+
+	return refs
+}
+
+// goFiles returns the cached Go files sorted by path
+func (a *ProjectAnalyzer) goFiles() []*FileInfo {
+	var files []*FileInfo
+	for _, file := range a.cache.Snapshot() {
+		if file.Language == "go" {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// displayPath shortens path relative to the first project root containing it
+func (a *ProjectAnalyzer) displayPath(path string) string {
+	for _, root := range a.config.ProjectPaths {
+		absRoot, err := filepath.Abs(root)
+		if err != nil {
+			continue
+		}
+		if rel, err := filepath.Rel(absRoot, path); err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return path
+}
+
+// readLines returns lines start..end (1-based, inclusive), capped at max lines
+func readLines(path string, start, end, max int) string {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+
+	lines := strings.Split(string(content), "\n")
+	if start < 1 || start > len(lines) {
+		return ""
+	}
+	if end > len(lines) {
+		end = len(lines)
+	}
+
+	var b strings.Builder
+	for i := start; i <= end; i++ {
+		if i-start >= max {
+			b.WriteString(fmt.Sprintf("// ... (%d more lines)\n", end-i+1))
+			break
+		}
+		b.WriteString(lines[i-1])
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,167 @@
+package analyzer
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// shopFiles is a two-package module: app calls into store, whose Product
+// embeds Base and satisfies Pricer
+var shopFiles = map[string]string{
+	"go.mod": "module example.com/shop\n\ngo 1.21\n",
+	"store/store.go": `package store
+
+// Pricer reports a price
+type Pricer interface {
+	Price() int
+}
+
+// Base carries the identity of stocked things
+type Base struct{}
+
+func (Base) ID() string { return "" }
+
+func (*Base) Touch() {}
+
+// Product is a priced Base
+type Product struct {
+	Base
+	price int
+}
+
+func (p Product) Price() int { return p.price }
+
+// Item is a catalogue entry
+type Item struct {
+	Price int
+}
+
+// Total sums the prices of items
+func Total(items []Pricer) int {
+	sum := 0
+	for _, it := range items {
+		sum += it.Price()
+	}
+	return sum
+}
+`,
+	"app/app.go": `package app
+
+import "example.com/shop/store"
+
+func Checkout() int {
+	return subtotal() + store.Product{}.Price()
+}
+
+func subtotal() int {
+	item := store.Item{Price: 1}
+	return store.Total([]store.Pricer{store.Product{}}) + item.Price
+}
+`,
+}
+
+func TestLookupSymbols(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, shopFiles)
+	a := newTestAnalyzer(t, root)
+	a.ensureIndexed()
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"Total", []string{"store.Total func"}},
+		{"Product.Price", []string{"store.Product.Price method"}},
+		{"store.Pricer", []string{"store.Pricer interface"}},
+		{"store.Base.Touch", []string{"store.Base.Touch method"}},
+		// Exported definitions first; the case-insensitive match is a fallback
+		{"checkout", []string{"app.Checkout func"}},
+		{"Subtotal", []string{"app.subtotal func"}},
+		{"store.Missing", nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, sym := range a.lookupSymbols(tt.query) {
+			got = append(got, sym.Package+"."+sym.QualifiedName()+" "+sym.Kind)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("lookupSymbols(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestFindSymbol(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, shopFiles)
+	a := newTestAnalyzer(t, root)
+
+	got, err := a.FindSymbol("store.Total", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"## func `store.Total`\n",
+		"**Defined at**: `store/store.go:29`\n",
+		"Total sums the prices of items\n",
+		"### References (1)\n\n- `app/app.go:11` `return store.Total([]store.Pricer{store.Product{}}) + item.Price`\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("FindSymbol missing %q:\n%s", want, got)
+		}
+	}
+
+	if got, _ := a.FindSymbol("Nowhere", false); !strings.Contains(got, "No definition found.") {
+		t.Errorf("FindSymbol(Nowhere) = %q", got)
+	}
+	if _, err := a.FindSymbol(" ", false); err == nil {
+		t.Error("expected an error for an empty query")
+	}
+}
+
+func TestFindReferences(t *testing.T) {
+	tests := []struct {
+		name  string
+		goMod bool
+		query string
+		want  []string
+	}{
+		// Without a module, methods match any selector of the same name
+		{"syntactic method", false, "Product.Price", []string{"app/app.go:6", "app/app.go:11", "store/store.go:32"}},
+		{"syntactic func", false, "Total", []string{"app/app.go:11"}},
+		{"syntactic same package", false, "store.Base", []string{"store/store.go:11", "store/store.go:13", "store/store.go:17"}},
+		// Type information tells the method from the field and the interface method
+		{"typed method", true, "Product.Price", []string{"app/app.go:6"}},
+		{"typed func", true, "Total", []string{"app/app.go:11"}},
+		{"typed embedded", true, "store.Base", []string{"store/store.go:11", "store/store.go:13", "store/store.go:17"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			files := make(map[string]string)
+			for name, content := range shopFiles {
+				if name != "go.mod" || tt.goMod {
+					files[name] = content
+				}
+			}
+			writeFiles(t, root, files)
+			a := newTestAnalyzer(t, root)
+			a.ensureIndexed()
+
+			syms := a.lookupSymbols(tt.query)
+			if len(syms) != 1 {
+				t.Fatalf("lookupSymbols(%q) = %d symbols", tt.query, len(syms))
+			}
+			var got []string
+			for _, ref := range a.findReferences(syms[0]) {
+				rel, _ := filepath.Rel(root, ref.Path)
+				got = append(got, fmt.Sprintf("%s:%d", filepath.ToSlash(rel), ref.Line))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("references = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
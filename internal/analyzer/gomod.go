@@ -0,0 +1,219 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+
+	"golang.org/x/mod/modfile"
+)
+
+// GoModFile is a parsed go.mod file
+type GoModFile struct {
+	Path      string // location of go.mod
+	Module    string
+	GoVersion string
+	Toolchain string
+	Requires  []Dependency
+	Replaces  []Replacement
+	Excludes  []ModuleVersion
+	Retracts  []Retraction
+}
+
+// ModuleVersion identifies a module version; Version may be empty
+type ModuleVersion struct {
+	Path    string
+	Version string
+}
+
+// Replacement is a replace directive
+type Replacement struct {
+	Old   ModuleVersion // empty Version replaces every version
+	New   ModuleVersion // empty Version for local directories
+	Local bool
+	Dir   string // absolute directory of a local replacement
+}
+
+// Retraction is a retract directive; Low equals High for a single version
+type Retraction struct {
+	Low       string
+	High      string
+	Rationale string
+}
+
+// GoModFiles parses the go.mod of every project module
+func (a *ProjectAnalyzer) GoModFiles() []GoModFile {
+	var files []GoModFile
+	for _, module := range a.GoModules() {
+		if mf, err := readGoModFile(filepath.Join(module.Dir, "go.mod")); err == nil {
+			files = append(files, *mf)
+		}
+	}
+	return files
+}
+
+// readGoModFile parses a main-module go.mod file
+func readGoModFile(path string) (*GoModFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := modfile.Parse(path, data, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	mf := &GoModFile{Path: path}
+	if f.Module != nil {
+		mf.Module = f.Module.Mod.Path
+	}
+	if f.Go != nil {
+		mf.GoVersion = f.Go.Version
+	}
+	if f.Toolchain != nil {
+		mf.Toolchain = f.Toolchain.Name
+	}
+
+	for _, req := range f.Require {
+		depType := "direct"
+		if req.Indirect {
+			depType = "indirect"
+		}
+		mf.Requires = append(mf.Requires, Dependency{
+			Name:      req.Mod.Path,
+			Version:   req.Mod.Version,
+			Type:      depType,
+			Path:      path,
+			Module:    mf.Module,
+			Ecosystem: "go",
+		})
+	}
+
+	for _, rep := range f.Replace {
+		r := Replacement{
+			Old: ModuleVersion{Path: rep.Old.Path, Version: rep.Old.Version},
+			New: ModuleVersion{Path: rep.New.Path, Version: rep.New.Version},
+		}
+		// A replacement without version is a directory
+		if rep.New.Version == "" {
+			r.Local = true
+			r.Dir = rep.New.Path
+			if !filepath.IsAbs(r.Dir) {
+				r.Dir = filepath.Join(filepath.Dir(path), filepath.FromSlash(r.Dir))
+			}
+		}
+		mf.Replaces = append(mf.Replaces, r)
+	}
+
+	for _, ex := range f.Exclude {
+		mf.Excludes = append(mf.Excludes, ModuleVersion{Path: ex.Mod.Path, Version: ex.Mod.Version})
+	}
+
+	for _, ret := range f.Retract {
+		mf.Retracts = append(mf.Retracts, Retraction{
+			Low:       ret.Low,
+			High:      ret.High,
+			Rationale: ret.Rationale,
+		})
+	}
+
+	return mf, nil
+}
+
+// Replacement returns the replace directive applying to a required module version
+func (mf *GoModFile) Replacement(path, version string) *Replacement {
+	var match *Replacement
+	for i, r := range mf.Replaces {
+		if r.Old.Path != path {
+			continue
+		}
+		// A version-specific replacement wins over a wildcard one
+		if r.Old.Version == version {
+			return &mf.Replaces[i]
+		}
+		if r.Old.Version == "" {
+			match = &mf.Replaces[i]
+		}
+	}
+	return match
+}
+
+func init() {
+	mustRegisterDependencyParser(goModParser{})
+}
+
+// goModParser reads the go.mod requirements of the modules governing a
+// project path, including every module of its go.work workspace
+type goModParser struct{}
+
+func (goModParser) Ecosystem() string { return "go" }
+
+func (goModParser) Detect(root string) bool {
+	return len(goModulesIn(root)) > 0
+}
+
+func (goModParser) Parse(root string, includeTransitive bool) ([]Dependency, error) {
+	var deps []Dependency
+	for _, module := range goModulesIn(root) {
+		mf, err := readGoModFile(filepath.Join(module.Dir, "go.mod"))
+		if err != nil {
+			return nil, err
+		}
+		for _, dep := range mf.Requires {
+			if includeTransitive || dep.Type == "direct" {
+				deps = append(deps, dep)
+			}
+		}
+	}
+	return deps, nil
+}
+
+// readModulePath returns the module path declared in a go.mod file
+func readModulePath(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return modfile.ModulePath(data)
+}
+
+// readWorkspaceModules returns the absolute module directories named by the
+// use directives of a go.work file
+func readWorkspaceModules(workFile string) []string {
+	data, err := os.ReadFile(workFile)
+	if err != nil {
+		return nil
+	}
+
+	wf, err := modfile.ParseWork(workFile, data, nil)
+	if err != nil {
+		return nil
+	}
+
+	var dirs []string
+	for _, use := range wf.Use {
+		dir := use.Path
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(filepath.Dir(workFile), filepath.FromSlash(dir))
+		}
+		dirs = append(dirs, filepath.Clean(dir))
+	}
+	return dirs
+}
+
+// moduleSourceDir returns where the source of a required module lives,
+// following replace directives
+func moduleSourceDir(mf *GoModFile, dep Dependency, modCache string) string {
+	path, version := dep.Name, dep.Version
+	if r := mf.Replacement(dep.Name, dep.Version); r != nil {
+		if r.Local {
+			return r.Dir
+		}
+		path, version = r.New.Path, r.New.Version
+	}
+
+	if modCache == "" {
+		return ""
+	}
+	return filepath.Join(modCache, escapeModulePath(path)+"@"+version)
+}
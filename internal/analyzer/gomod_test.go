@@ -0,0 +1,173 @@
+package analyzer
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// depStrings renders dependencies compactly for comparison:
+// name@version type module path [constraint] extras=[...] ; markers
+func depStrings(root string, deps []Dependency) []string {
+	var out []string
+	for _, dep := range deps {
+		rel, _ := filepath.Rel(root, dep.Path)
+		s := fmt.Sprintf("%s@%s %s %s %s", dep.Name, dep.Version, dep.Type, dep.Module, filepath.ToSlash(rel))
+		if dep.Constraint != "" {
+			s += " [" + dep.Constraint + "]"
+		}
+		if len(dep.Extras) > 0 {
+			s += fmt.Sprintf(" extras=%v", dep.Extras)
+		}
+		if dep.Markers != "" {
+			s += " ; " + dep.Markers
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+const testGoMod = `module example.com/app
+
+go 1.22
+
+toolchain go1.22.3
+
+require (
+	github.com/pkg/errors v0.9.1
+	golang.org/x/text v0.14.0 // indirect
+)
+
+replace (
+	github.com/pkg/errors => ../errors
+	golang.org/x/text v0.14.0 => golang.org/x/text v0.13.0
+	golang.org/x/text => golang.org/x/text v0.12.0
+)
+
+exclude golang.org/x/net v0.1.0
+
+retract (
+	v1.0.1 // published by mistake
+	[v1.1.0, v1.1.5]
+)
+`
+
+func TestReadGoModFile(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"app/go.mod": testGoMod})
+	path := filepath.Join(root, "app", "go.mod")
+
+	mf, err := readGoModFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mf.Module != "example.com/app" || mf.GoVersion != "1.22" || mf.Toolchain != "go1.22.3" {
+		t.Errorf("header = %q %q %q", mf.Module, mf.GoVersion, mf.Toolchain)
+	}
+	if got := depStrings(root, mf.Requires); !reflect.DeepEqual(got, []string{
+		"github.com/pkg/errors@v0.9.1 direct example.com/app app/go.mod",
+		"golang.org/x/text@v0.14.0 indirect example.com/app app/go.mod",
+	}) {
+		t.Errorf("Requires = %q", got)
+	}
+	if want := []ModuleVersion{{Path: "golang.org/x/net", Version: "v0.1.0"}}; !reflect.DeepEqual(mf.Excludes, want) {
+		t.Errorf("Excludes = %+v", mf.Excludes)
+	}
+	if want := []Retraction{
+		{Low: "v1.0.1", High: "v1.0.1", Rationale: "published by mistake"},
+		{Low: "v1.1.0", High: "v1.1.5"},
+	}; !reflect.DeepEqual(mf.Retracts, want) {
+		t.Errorf("Retracts = %+v", mf.Retracts)
+	}
+
+	tests := []struct {
+		path, version string
+		want          *Replacement
+	}{
+		{"github.com/pkg/errors", "v0.9.1", &Replacement{
+			Old:   ModuleVersion{Path: "github.com/pkg/errors"},
+			New:   ModuleVersion{Path: "../errors"},
+			Local: true,
+			Dir:   filepath.Join(root, "errors"),
+		}},
+		{"golang.org/x/text", "v0.14.0", &Replacement{
+			Old: ModuleVersion{Path: "golang.org/x/text", Version: "v0.14.0"},
+			New: ModuleVersion{Path: "golang.org/x/text", Version: "v0.13.0"},
+		}},
+		{"golang.org/x/text", "v0.15.0", &Replacement{
+			Old: ModuleVersion{Path: "golang.org/x/text"},
+			New: ModuleVersion{Path: "golang.org/x/text", Version: "v0.12.0"},
+		}},
+		{"golang.org/x/net", "v0.1.0", nil},
+	}
+	for _, tt := range tests {
+		if got := mf.Replacement(tt.path, tt.version); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Replacement(%s, %s) = %+v, want %+v", tt.path, tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestGoModParser(t *testing.T) {
+	t.Setenv("GOWORK", "")
+
+	tests := []struct {
+		name       string
+		files      map[string]string
+		dir        string // parsed directory, relative to the temp root
+		transitive bool
+		want       []string
+	}{
+		{
+			name:  "direct requirements only",
+			files: map[string]string{"go.mod": testGoMod},
+			want:  []string{"github.com/pkg/errors@v0.9.1 direct example.com/app go.mod"},
+		},
+		{
+			name:       "indirect requirements when transitive",
+			files:      map[string]string{"go.mod": testGoMod, "cmd/main.go": "package main\n"},
+			dir:        "cmd",
+			transitive: true,
+			want: []string{
+				"github.com/pkg/errors@v0.9.1 direct example.com/app go.mod",
+				"golang.org/x/text@v0.14.0 indirect example.com/app go.mod",
+			},
+		},
+		{
+			name: "go.work modules",
+			files: map[string]string{
+				"go.work":        "go 1.22\n\nuse (\n\t./api\n\t./worker\n\t./missing\n)\n",
+				"api/go.mod":     "module example.com/api\n\ngo 1.22\n\nrequire github.com/gorilla/mux v1.8.1\n",
+				"worker/go.mod":  "module example.com/worker\n\ngo 1.22\n\nrequire example.com/api v0.0.0\n",
+				"worker/main.go": "package main\n",
+			},
+			dir: "worker",
+			want: []string{
+				"github.com/gorilla/mux@v1.8.1 direct example.com/api api/go.mod",
+				"example.com/api@v0.0.0 direct example.com/worker worker/go.mod",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeFiles(t, root, tt.files)
+			dir := filepath.Join(root, tt.dir)
+			if !(goModParser{}).Detect(dir) {
+				t.Fatal("Detect() = false")
+			}
+			deps, err := goModParser{}.Parse(dir, tt.transitive)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := depStrings(root, deps); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+
+	if (goModParser{}).Detect(t.TempDir()) {
+		t.Error("Detect() = true without go.mod")
+	}
+}
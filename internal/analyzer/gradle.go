@@ -0,0 +1,408 @@
+package analyzer
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+func init() {
+	mustRegisterDependencyParser(gradleParser{})
+}
+
+// gradleParser reads build.gradle and build.gradle.kts files of the root
+// project and the subprojects included by settings.gradle(.kts). Versions
+// come from the gradle/libs.versions.toml catalog, properties and
+// gradle.lockfile when present.
+type gradleParser struct{}
+
+var (
+	// implementation("group:name:version"), testImplementation 'group:name:version', ...
+	gradleCoordinatePattern = regexp.MustCompile(`(?m)^\s*(\w+)\s*\(?\s*(?:(?:platform|enforcedPlatform)\s*\(\s*)?["']([^"':\s]+:[^"':\s]+(?::[^"'\s]*)?)["']`)
+	// implementation group: 'g', name: 'n', version: 'v'
+	gradleMapPattern = regexp.MustCompile(`(?m)^\s*(\w+)\s*\(?\s*group\s*[:=]\s*["']([^"']+)["']\s*,\s*name\s*[:=]\s*["']([^"']+)["'](?:\s*,\s*version\s*[:=]\s*["']([^"']+)["'])?`)
+	// implementation(libs.some.library)
+	gradleCatalogPattern = regexp.MustCompile(`(?m)^\s*(\w+)\s*\(?\s*(?:(?:platform|enforcedPlatform)\s*\(\s*)?libs\.([\w.]+)`)
+	// include(":app", ":lib") or include ':app', ':lib'
+	gradleIncludePattern = regexp.MustCompile(`(?m)^\s*include\s*\(?([^)\n]*)`)
+	gradleQuotedPattern  = regexp.MustCompile(`["']([^"']+)["']`)
+	// rootProject.name = "name"
+	gradleRootNamePattern = regexp.MustCompile(`rootProject\.name\s*=\s*["']([^"']+)["']`)
+	// val kotlinVersion = "1.9.0", def x = '1', ext.x = '1', x = '1' inside ext { }
+	gradleVariablePattern = regexp.MustCompile(`(?m)^\s*(?:val|var|def|ext\.)?\s*(\w+)\s*=\s*["']([^"'$]+)["']`)
+	gradleInterpolation   = regexp.MustCompile(`\$\{?(\w+(?:\.\w+)*)\}?`)
+)
+
+// gradleCatalog is gradle/libs.versions.toml
+type gradleCatalog struct {
+	Versions  map[string]interface{} `toml:"versions"`
+	Libraries map[string]interface{} `toml:"libraries"`
+}
+
+type gradleProject struct {
+	name string
+	dir  string
+}
+
+func (gradleParser) Ecosystem() string { return "gradle" }
+
+func (gradleParser) Detect(root string) bool {
+	if gradleBuildFile(root) != "" {
+		return true
+	}
+	// Multi-project builds may only have settings at the root
+	for _, name := range []string{"settings.gradle.kts", "settings.gradle"} {
+		if _, err := os.Stat(filepath.Join(root, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (gradleParser) Parse(root string, includeTransitive bool) ([]Dependency, error) {
+	catalog := readGradleCatalog(root)
+	properties := readGradleProperties(filepath.Join(root, "gradle.properties"))
+	rootName, subprojects := readGradleSettings(root)
+	if rootName == "" {
+		rootName = filepath.Base(root)
+	}
+
+	// Subproject :a:b lives in a/b
+	projects := []gradleProject{{rootName, root}}
+	for _, sub := range subprojects {
+		sub = strings.TrimPrefix(sub, ":")
+		dir := filepath.Join(root, filepath.FromSlash(strings.ReplaceAll(sub, ":", "/")))
+		projects = append(projects, gradleProject{":" + sub, dir})
+	}
+
+	var deps []Dependency
+	declared := make(map[string]bool)
+	var lockFiles []string
+	locked := make(map[string]string)
+
+	for _, project := range projects {
+		buildFile := gradleBuildFile(project.dir)
+		if buildFile == "" {
+			continue
+		}
+		data, err := os.ReadFile(buildFile)
+		if err != nil {
+			if project.dir == root {
+				return nil, err
+			}
+			continue
+		}
+
+		lockFile := filepath.Join(project.dir, "gradle.lockfile")
+		projectLocked := readGradleLockfile(lockFile)
+		if projectLocked != nil {
+			lockFiles = append(lockFiles, lockFile)
+			for k, v := range projectLocked {
+				locked[k] = v
+			}
+		}
+
+		content := stripGradleComments(string(data))
+		variables := make(map[string]string)
+		for k, v := range properties {
+			variables[k] = v
+		}
+		for _, m := range gradleVariablePattern.FindAllStringSubmatch(content, -1) {
+			variables[m[1]] = m[2]
+		}
+
+		seen := make(map[string]bool)
+		add := func(configuration, group, name, version string) {
+			depType, ok := gradleConfigurationType(configuration)
+			if !ok {
+				return
+			}
+			key := group + ":" + name
+			if seen[key+"\x00"+depType] {
+				return
+			}
+			seen[key+"\x00"+depType] = true
+
+			dep := Dependency{
+				Name:       key,
+				Type:       depType,
+				Path:       buildFile,
+				Module:     project.name,
+				Ecosystem:  "gradle",
+				Constraint: interpolateGradle(version, variables),
+			}
+			dep.Version = dep.Constraint
+			if pinned, ok := projectLocked[key]; ok {
+				dep.Version = pinned
+			}
+			if dep.Version == "" {
+				dep.Version = "managed"
+			}
+			if dep.Version == dep.Constraint {
+				dep.Constraint = ""
+			}
+			declared[key] = true
+			deps = append(deps, dep)
+		}
+
+		for _, m := range gradleCoordinatePattern.FindAllStringSubmatch(content, -1) {
+			parts := strings.SplitN(m[2], ":", 3)
+			version := ""
+			if len(parts) == 3 {
+				version = parts[2]
+			}
+			add(m[1], parts[0], parts[1], version)
+		}
+		for _, m := range gradleMapPattern.FindAllStringSubmatch(content, -1) {
+			add(m[1], m[2], m[3], m[4])
+		}
+		for _, m := range gradleCatalogPattern.FindAllStringSubmatch(content, -1) {
+			if lib, ok := catalog[strings.ToLower(m[2])]; ok {
+				group, name, _ := strings.Cut(lib.Path, ":")
+				add(m[1], group, name, lib.Version)
+			}
+		}
+	}
+
+	// Locked modules nobody declares are transitive
+	if includeTransitive && len(lockFiles) > 0 {
+		for _, key := range sortedKeys(locked) {
+			if declared[key] {
+				continue
+			}
+			deps = append(deps, Dependency{
+				Name:      key,
+				Version:   locked[key],
+				Type:      "indirect",
+				Path:      lockFiles[0],
+				Module:    rootName,
+				Ecosystem: "gradle",
+			})
+		}
+	}
+
+	return deps, nil
+}
+
+// gradleBuildFile returns the Groovy or Kotlin build script of dir
+func gradleBuildFile(dir string) string {
+	for _, name := range []string{"build.gradle.kts", "build.gradle"} {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// gradleConfigurationType maps a dependency configuration to a dependency
+// type. compileOnly dependencies are supplied at runtime by the host, like
+// npm peer dependencies; annotation processors are build tools.
+func gradleConfigurationType(configuration string) (string, bool) {
+	lower := strings.ToLower(configuration)
+	switch {
+	case strings.HasPrefix(lower, "test"), strings.HasPrefix(lower, "androidtest"):
+		return "dev", true
+	case lower == "annotationprocessor", lower == "kapt", lower == "ksp", lower == "classpath":
+		return "dev", true
+	case lower == "compileonly" || lower == "compileonlyapi":
+		return "peer", true
+	case lower == "implementation", lower == "api", lower == "compile", lower == "runtime",
+		lower == "runtimeonly", strings.HasSuffix(lower, "implementation"), strings.HasSuffix(lower, "api"):
+		return "direct", true
+	}
+	return "", false
+}
+
+// readGradleSettings returns rootProject.name and the included subprojects
+func readGradleSettings(root string) (string, []string) {
+	for _, name := range []string{"settings.gradle.kts", "settings.gradle"} {
+		data, err := os.ReadFile(filepath.Join(root, name))
+		if err != nil {
+			continue
+		}
+		content := stripGradleComments(string(data))
+
+		rootName := ""
+		if m := gradleRootNamePattern.FindStringSubmatch(content); m != nil {
+			rootName = m[1]
+		}
+
+		var projects []string
+		for _, m := range gradleIncludePattern.FindAllStringSubmatch(content, -1) {
+			for _, q := range gradleQuotedPattern.FindAllStringSubmatch(m[1], -1) {
+				projects = append(projects, q[1])
+			}
+		}
+		return rootName, projects
+	}
+	return "", nil
+}
+
+// readGradleCatalog reads gradle/libs.versions.toml, keyed by the accessor
+// used in build scripts (my-lib and my_lib are both libs.my.lib)
+func readGradleCatalog(root string) map[string]ModuleVersion {
+	var catalog gradleCatalog
+	if _, err := toml.DecodeFile(filepath.Join(root, "gradle", "libs.versions.toml"), &catalog); err != nil {
+		return nil
+	}
+
+	versionOf := func(v interface{}) string {
+		switch version := v.(type) {
+		case string:
+			return version
+		case map[string]interface{}:
+			if ref, ok := version["ref"].(string); ok {
+				s, _ := catalog.Versions[ref].(string)
+				return s
+			}
+			for _, key := range []string{"strictly", "require", "prefer"} {
+				if s, ok := version[key].(string); ok {
+					return s
+				}
+			}
+		}
+		return ""
+	}
+
+	libraries := make(map[string]ModuleVersion)
+	for alias, spec := range catalog.Libraries {
+		var lib ModuleVersion
+		switch v := spec.(type) {
+		case string:
+			parts := strings.SplitN(v, ":", 3)
+			if len(parts) < 2 {
+				continue
+			}
+			lib.Path = parts[0] + ":" + parts[1]
+			if len(parts) == 3 {
+				lib.Version = parts[2]
+			}
+		case map[string]interface{}:
+			if module, ok := v["module"].(string); ok {
+				lib.Path = module
+			} else {
+				group, _ := v["group"].(string)
+				name, _ := v["name"].(string)
+				lib.Path = group + ":" + name
+			}
+			// version.ref = "x" decodes as version = {ref = "x"}
+			lib.Version = versionOf(v["version"])
+		}
+		if !strings.Contains(lib.Path, ":") {
+			continue
+		}
+		accessor := strings.ToLower(strings.NewReplacer("-", ".", "_", ".").Replace(alias))
+		libraries[accessor] = lib
+	}
+	return libraries
+}
+
+// readGradleProperties reads a gradle.properties file
+func readGradleProperties(path string) map[string]string {
+	properties := make(map[string]string)
+	file, err := os.Open(path)
+	if err != nil {
+		return properties
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") {
+			continue
+		}
+		if i := strings.IndexAny(line, "=:"); i > 0 {
+			properties[strings.TrimSpace(line[:i])] = strings.TrimSpace(line[i+1:])
+		}
+	}
+	return properties
+}
+
+// readGradleLockfile reads the group:name:version=configurations lines of a
+// gradle.lockfile, or returns nil if there is none
+func readGradleLockfile(path string) map[string]string {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	locked := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "empty=") {
+			continue
+		}
+		coordinate := strings.SplitN(line, "=", 2)[0]
+		parts := strings.Split(coordinate, ":")
+		if len(parts) == 3 {
+			locked[parts[0]+":"+parts[1]] = parts[2]
+		}
+	}
+	return locked
+}
+
+// interpolateGradle replaces $name and ${name} references with known values
+func interpolateGradle(value string, variables map[string]string) string {
+	return gradleInterpolation.ReplaceAllStringFunc(value, func(ref string) string {
+		name := strings.Trim(ref, "${}")
+		name = strings.TrimPrefix(name, "project.")
+		if v, ok := variables[name]; ok {
+			return v
+		}
+		return ref
+	})
+}
+
+// stripGradleComments removes // and /* */ comments from a build script
+func stripGradleComments(content string) string {
+	var b strings.Builder
+	inBlock := false
+	for _, line := range strings.Split(content, "\n") {
+		var code strings.Builder
+		for line != "" {
+			if inBlock {
+				end := strings.Index(line, "*/")
+				if end < 0 {
+					break
+				}
+				line = line[end+2:]
+				inBlock = false
+				continue
+			}
+			start := strings.Index(line, "/*")
+			if start < 0 {
+				code.WriteString(line)
+				break
+			}
+			code.WriteString(line[:start])
+			line = line[start+2:]
+			inBlock = true
+		}
+
+		// A // inside a string (such as a URL) is left alone
+		text := code.String()
+		for from := 0; ; {
+			i := strings.Index(text[from:], "//")
+			if i < 0 {
+				break
+			}
+			i += from
+			if strings.Count(text[:i], `"`)%2 == 0 && strings.Count(text[:i], "'")%2 == 0 {
+				text = text[:i]
+				break
+			}
+			from = i + 2
+		}
+		b.WriteString(text)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
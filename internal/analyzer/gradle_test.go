@@ -0,0 +1,153 @@
+package analyzer
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGradleParser(t *testing.T) {
+	tests := []struct {
+		name       string
+		files      map[string]string
+		transitive bool
+		want       []string
+	}{
+		{
+			name: "groovy script with variables and map notation",
+			files: map[string]string{
+				"build.gradle": `ext {
+    springVersion = '6.1.1'
+}
+def junitVersion = "5.10.1"
+
+dependencies {
+    implementation "org.springframework:spring-core:$springVersion"
+    implementation group: 'commons-io', name: 'commons-io', version: '2.15.1'
+    // implementation 'commented:out:1.0'
+    testImplementation "org.junit.jupiter:junit-jupiter:${junitVersion}"
+    compileOnly 'org.projectlombok:lombok:1.18.30'
+    annotationProcessor 'org.projectlombok:lombok:1.18.30'
+    implementation platform('org.springframework.boot:spring-boot-dependencies:3.2.0')
+    implementation 'org.slf4j:slf4j-api'
+    /* implementation 'block:comment:1.0' */
+}
+`,
+			},
+			want: []string{
+				"org.springframework:spring-core@6.1.1 direct %ROOT% build.gradle",
+				"org.junit.jupiter:junit-jupiter@5.10.1 dev %ROOT% build.gradle",
+				"org.projectlombok:lombok@1.18.30 peer %ROOT% build.gradle",
+				"org.projectlombok:lombok@1.18.30 dev %ROOT% build.gradle",
+				"org.springframework.boot:spring-boot-dependencies@3.2.0 direct %ROOT% build.gradle",
+				"org.slf4j:slf4j-api@managed direct %ROOT% build.gradle",
+				"commons-io:commons-io@2.15.1 direct %ROOT% build.gradle",
+			},
+		},
+		{
+			name: "kotlin multi-project build with catalog and lockfile",
+			files: map[string]string{
+				"settings.gradle.kts": `rootProject.name = "shop"
+include(":app", ":libs:model")
+`,
+				"gradle.properties": "okhttpVersion=4.12.0\n",
+				"gradle/libs.versions.toml": `[versions]
+kotlin = "1.9.21"
+
+[libraries]
+kotlin-stdlib = { module = "org.jetbrains.kotlin:kotlin-stdlib", version.ref = "kotlin" }
+kotlinx_coroutines = { group = "org.jetbrains.kotlinx", name = "kotlinx-coroutines-core", version = { strictly = "1.7.3" } }
+guava = "com.google.guava:guava:33.0-jre"
+`,
+				"app/build.gradle.kts": `dependencies {
+    implementation(libs.kotlin.stdlib)
+    implementation(libs.kotlinx.coroutines)
+    implementation("com.squareup.okhttp3:okhttp:${project.okhttpVersion}")
+    testImplementation(libs.guava)
+}
+`,
+				"app/gradle.lockfile": `# Gradle lockfile
+org.jetbrains.kotlin:kotlin-stdlib:1.9.21=compileClasspath,runtimeClasspath
+com.squareup.okhttp3:okhttp:4.12.0=runtimeClasspath
+com.squareup.okio:okio:3.6.0=runtimeClasspath
+empty=
+`,
+				"libs/model/build.gradle.kts": `dependencies {
+    api("com.google.code.gson:gson:2.10.1")
+}
+`,
+			},
+			transitive: true,
+			want: []string{
+				"com.squareup.okhttp3:okhttp@4.12.0 direct :app app/build.gradle.kts",
+				"org.jetbrains.kotlin:kotlin-stdlib@1.9.21 direct :app app/build.gradle.kts",
+				"org.jetbrains.kotlinx:kotlinx-coroutines-core@1.7.3 direct :app app/build.gradle.kts",
+				"com.google.guava:guava@33.0-jre dev :app app/build.gradle.kts",
+				"com.google.code.gson:gson@2.10.1 direct :libs:model libs/model/build.gradle.kts",
+				"com.squareup.okio:okio@3.6.0 indirect shop app/gradle.lockfile",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeFiles(t, root, tt.files)
+			if !(gradleParser{}).Detect(root) {
+				t.Fatal("Detect() = false")
+			}
+			deps, err := gradleParser{}.Parse(root, tt.transitive)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := make([]string, len(tt.want))
+			for i, w := range tt.want {
+				want[i] = replaceRoot(w, filepath.Base(root))
+			}
+			if got := depStrings(root, deps); !reflect.DeepEqual(got, want) {
+				t.Errorf("Parse() =\n%q\nwant\n%q", got, want)
+			}
+		})
+	}
+}
+
+func TestGradleConfigurationType(t *testing.T) {
+	tests := []struct {
+		configuration string
+		want          string
+		ok            bool
+	}{
+		{"implementation", "direct", true},
+		{"api", "direct", true},
+		{"runtimeOnly", "direct", true},
+		{"debugImplementation", "direct", true},
+		{"testImplementation", "dev", true},
+		{"androidTestImplementation", "dev", true},
+		{"kapt", "dev", true},
+		{"compileOnly", "peer", true},
+		{"version", "", false},
+		{"id", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := gradleConfigurationType(tt.configuration)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("gradleConfigurationType(%q) = %q, %v; want %q, %v", tt.configuration, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestStripGradleComments(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"a // comment", "a \n"},
+		{`url "https://example.com" // repo`, `url "https://example.com" ` + "\n"},
+		{"a /* b */ c", "a  c\n"},
+		{"a /* b\nc */ d", "a \n d\n"},
+	}
+	for _, tt := range tests {
+		if got := stripGradleComments(tt.in); got != tt.want {
+			t.Errorf("stripGradleComments(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package analyzer
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// ignoreFiles are read in every directory, in order; later files take precedence
+var ignoreFiles = []string{".gitignore", ".mcpignore"}
+
+// ignoreRule is a single gitignore-style pattern
+type ignoreRule struct {
+	pattern string // doublestar pattern relative to base
+	base    string // slash path of the directory declaring the rule, "" for the root
+	negate  bool
+	dirOnly bool
+}
+
+// ignoreMatcher applies gitignore semantics: nested ignore files, negation,
+// "**", anchoring and directory-only patterns. Paths are matched relative to
+// the repository root (the nearest ancestor containing .git) when there is one.
+type ignoreMatcher struct {
+	root   string
+	global []ignoreRule
+	rules  map[string][]ignoreRule // directory (slash path) -> rules from its ignore files
+}
+
+// newIgnoreMatcher builds a matcher for walks starting at walkRoot. Configured
+// IgnorePatterns behave like entries of a root .gitignore.
+func (a *ProjectAnalyzer) newIgnoreMatcher(walkRoot string) *ignoreMatcher {
+	root := findRepoRoot(walkRoot)
+	if root == "" {
+		root = walkRoot
+	}
+
+	m := &ignoreMatcher{
+		root:  root,
+		rules: make(map[string][]ignoreRule),
+	}
+
+	for _, pattern := range a.config.IgnorePatterns {
+		if rule, ok := parseIgnoreLine(pattern, ""); ok {
+			m.global = append(m.global, rule)
+		}
+	}
+	m.global = append(m.global, readIgnoreFile(filepath.Join(root, ".git", "info", "exclude"), "")...)
+
+	return m
+}
+
+// Match reports whether the absolute path should be skipped
+func (m *ignoreMatcher) Match(absPath string, isDir bool) bool {
+	rel, err := filepath.Rel(m.root, absPath)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+
+	if isDir && path.Base(rel) == ".git" {
+		return true
+	}
+
+	ignored := false
+	apply := func(rules []ignoreRule) {
+		for _, rule := range rules {
+			if rule.matches(rel, isDir) {
+				ignored = !rule.negate
+			}
+		}
+	}
+
+	apply(m.global)
+
+	// Ignore files from the root down to the path's parent; deeper files win
+	dir := ""
+	apply(m.dirRules(dir))
+	parts := strings.Split(rel, "/")
+	for _, part := range parts[:len(parts)-1] {
+		dir = path.Join(dir, part)
+		apply(m.dirRules(dir))
+	}
+
+	return ignored
+}
+
+// dirRules loads (once) the ignore files of a directory
+func (m *ignoreMatcher) dirRules(dir string) []ignoreRule {
+	if rules, loaded := m.rules[dir]; loaded {
+		return rules
+	}
+
+	var rules []ignoreRule
+	for _, name := range ignoreFiles {
+		rules = append(rules, readIgnoreFile(filepath.Join(m.root, filepath.FromSlash(dir), name), dir)...)
+	}
+
+	m.rules[dir] = rules
+	return rules
+}
+
+func (r ignoreRule) matches(rel string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+
+	sub := rel
+	if r.base != "" {
+		if !strings.HasPrefix(rel, r.base+"/") {
+			return false
+		}
+		sub = strings.TrimPrefix(rel, r.base+"/")
+	}
+
+	ok, _ := doublestar.Match(r.pattern, sub)
+	return ok
+}
+
+func readIgnoreFile(file, base string) []ignoreRule {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var rules []ignoreRule
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if rule, ok := parseIgnoreLine(scanner.Text(), base); ok {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// parseIgnoreLine converts one gitignore line into a rule
+func parseIgnoreLine(line, base string) (ignoreRule, bool) {
+	line = strings.TrimSuffix(line, "\r")
+
+	// Trailing spaces are ignored unless escaped
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, "\\ ") {
+		line = strings.TrimSuffix(line, " ")
+	}
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false
+	}
+
+	rule := ignoreRule{base: base}
+
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, "\\!") || strings.HasPrefix(line, "\\#") {
+		line = line[1:]
+	}
+
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return ignoreRule{}, false
+	}
+
+	// A slash at the start or in the middle anchors the pattern to base
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+	if !anchored {
+		line = "**/" + line
+	}
+
+	rule.pattern = line
+	return rule, true
+}
+
+// findRepoRoot returns the nearest ancestor of dir containing .git
+func findRepoRoot(dir string) string {
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,81 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/scopweb/mcp-go-context/internal/config"
+)
+
+func TestIgnoreMatcher(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFiles(t, root, map[string]string{
+		".gitignore":     "# build output\n*.log\n!keep.log\nbuild/\n/root-only.txt\ndocs/**/*.tmp\n\n",
+		".mcpignore":     "secret.txt\n",
+		"sub/.gitignore": "local.txt\n!*.log\n",
+	})
+	a, err := New(config.ContextConfig{IgnorePatterns: []string{"node_modules"}, Tokenizer: "chars"}, config.CacheConfig{}, config.DependencyConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Walks below the repository root still match relative to it
+	m := a.newIgnoreMatcher(filepath.Join(root, "sub"))
+
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"app.log", false, true},
+		{"deep/app.log", false, true},
+		{"keep.log", false, false},
+		{"build", true, true},
+		{"build", false, false},
+		{"src/build", true, true},
+		{"root-only.txt", false, true},
+		{"sub/root-only.txt", false, false},
+		{"docs/a/b/x.tmp", false, true},
+		{"x.tmp", false, false},
+		{"sub/local.txt", false, true},
+		{"local.txt", false, false},
+		{"sub/x.log", false, false},
+		{"secret.txt", false, true},
+		{"node_modules", true, true},
+		{"web/node_modules", true, true},
+		{".git", true, true},
+		{"main.go", false, false},
+	}
+	for _, tt := range tests {
+		if got := m.Match(filepath.Join(root, filepath.FromSlash(tt.path)), tt.isDir); got != tt.want {
+			t.Errorf("Match(%q, dir=%v) = %v, want %v", tt.path, tt.isDir, got, tt.want)
+		}
+	}
+}
+
+func TestParseIgnoreLine(t *testing.T) {
+	tests := []struct {
+		line string
+		want ignoreRule
+		ok   bool
+	}{
+		{"", ignoreRule{}, false},
+		{"# comment", ignoreRule{}, false},
+		{"*.go", ignoreRule{pattern: "**/*.go"}, true},
+		{"/vendor/", ignoreRule{pattern: "vendor", dirOnly: true}, true},
+		{"a/b", ignoreRule{pattern: "a/b"}, true},
+		{"!important.md", ignoreRule{pattern: "**/important.md", negate: true}, true},
+		{"\\#hash", ignoreRule{pattern: "**/#hash"}, true},
+		{"trailing   ", ignoreRule{pattern: "**/trailing"}, true},
+		{"crlf\r", ignoreRule{pattern: "**/crlf"}, true},
+	}
+	for _, tt := range tests {
+		got, ok := parseIgnoreLine(tt.line, "")
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseIgnoreLine(%q) = %+v, %v; want %+v, %v", tt.line, got, ok, tt.want, tt.ok)
+		}
+	}
+}
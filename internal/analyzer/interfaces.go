@@ -0,0 +1,117 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/types"
+	"sort"
+	"strings"
+)
+
+// InterfaceImpl maps a project interface to the concrete types satisfying it
+type InterfaceImpl struct {
+	Interface       string // pkg.Name
+	Path            string
+	Line            int
+	Implementations []string // pkg.Type, or *pkg.Type when only the pointer satisfies it
+}
+
+// InterfaceMap computes, for every interface declared in the module containing
+// rootPath, the concrete project types that satisfy it
+func (a *ProjectAnalyzer) InterfaceMap(rootPath string) ([]InterfaceImpl, error) {
+	index, err := a.TypeIndex(rootPath)
+	if err != nil {
+		return nil, err
+	}
+	if index.SyntaxOnly {
+		return nil, nil
+	}
+
+	return interfaceMap([]*TypeIndex{index}), nil
+}
+
+// InterfaceImplementations renders the interface map of all project paths, in
+// both directions, optionally filtered by interface or type name
+func (a *ProjectAnalyzer) InterfaceImplementations(filter string) (string, error) {
+	indexes := a.typeIndexes()
+	if len(indexes) == 0 {
+		return "", fmt.Errorf("type information unavailable: no Go module found in project paths")
+	}
+
+	impls := interfaceMap(indexes)
+	filter = strings.ToLower(strings.TrimSpace(filter))
+	matches := func(name string) bool {
+		return filter == "" || strings.Contains(strings.ToLower(strings.TrimPrefix(name, "*")), filter)
+	}
+
+	var result strings.Builder
+	result.WriteString("# 🔌 Interface Implementations\n\n")
+
+	result.WriteString("## Interfaces → Types\n\n")
+	for _, impl := range impls {
+		if !matches(impl.Interface) {
+			continue
+		}
+		result.WriteString(fmt.Sprintf("### `%s` (`%s:%d`)\n", impl.Interface, a.displayPath(impl.Path), impl.Line))
+		if len(impl.Implementations) == 0 {
+			result.WriteString("- _no implementations in project_\n")
+		}
+		for _, name := range impl.Implementations {
+			result.WriteString(fmt.Sprintf("- `%s`\n", name))
+		}
+		result.WriteString("\n")
+	}
+
+	result.WriteString("## Types → Interfaces\n\n")
+	byType := make(map[string][]string)
+	for _, impl := range impls {
+		for _, name := range impl.Implementations {
+			byType[name] = append(byType[name], impl.Interface)
+		}
+	}
+
+	typeNames := make([]string, 0, len(byType))
+	for name := range byType {
+		typeNames = append(typeNames, name)
+	}
+	sort.Strings(typeNames)
+
+	for _, name := range typeNames {
+		if !matches(name) {
+			continue
+		}
+		result.WriteString(fmt.Sprintf("- `%s` → `%s`\n", name, strings.Join(byType[name], "`, `")))
+	}
+
+	result.WriteString(fmt.Sprintf("\n_Type information: %s_\n", typeStatus(indexes)))
+	return result.String(), nil
+}
+
+func interfaceMap(indexes []*TypeIndex) []InterfaceImpl {
+	var impls []InterfaceImpl
+
+	for _, tn := range projectTypeNames(indexes) {
+		it, ok := tn.Type().Underlying().(*types.Interface)
+		if !ok || it.NumMethods() == 0 || isGeneric(tn) {
+			continue
+		}
+
+		pos := indexes[0].Fset.Position(tn.Pos())
+		impl := InterfaceImpl{
+			Interface: qualifiedTypeName(tn),
+			Path:      pos.Filename,
+			Line:      pos.Line,
+		}
+		for _, obj := range implementersOf(indexes, tn) {
+			impl.Implementations = append(impl.Implementations, obj.Name)
+		}
+		sort.Strings(impl.Implementations)
+
+		impls = append(impls, impl)
+	}
+
+	sort.Slice(impls, func(i, j int) bool {
+		return impls[i].Interface < impls[j].Interface
+	})
+
+	return impls
+}
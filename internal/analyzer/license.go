@@ -0,0 +1,892 @@
+package analyzer
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// DependencyLicense is the license found for a dependency
+type DependencyLicense struct {
+	Dependency Dependency
+	License    string // SPDX expression, empty when none was found
+	Source     string // file or metadata field the license was read from
+}
+
+// FileLicense is a license declared by a project file
+type FileLicense struct {
+	Path    string
+	License string
+}
+
+// LicenseViolation is a dependency or project file breaking the license policy
+type LicenseViolation struct {
+	Subject string // name@version of a dependency or path of a project file
+	License string
+	Reason  string
+}
+
+// LicenseReport lists the licenses of the project, its source files and its
+// dependencies, checked against the configured allow and deny lists
+type LicenseReport struct {
+	Project      []FileLicense // LICENSE files at the project roots
+	Files        []FileLicense // source files with a license header
+	Unlabeled    int           // source files without a license header
+	Dependencies []DependencyLicense
+	Violations   []LicenseViolation
+	Policy       bool // whether an allow or deny list is configured
+}
+
+// maxHeaderBytes bounds how much of a source file is searched for a header
+const maxHeaderBytes = 4096
+
+var (
+	spdxIdentifierPattern = regexp.MustCompile(`(?im)SPDX-License-Identifier:\s*([^\n\r*]+?)\s*(?:\*/|-->)?\s*$`)
+	licenseSeparators     = regexp.MustCompile(`[\s*#/;]+`)
+	licenseFilePattern    = regexp.MustCompile(`(?i)^(un)?licen[cs]e|^copying`)
+)
+
+// licenseFingerprint identifies a license by phrases its text contains
+type licenseFingerprint struct {
+	id      string
+	phrases []string
+}
+
+// licenseFingerprints are checked in order, and the phrases of a match are
+// removed before checking the next ones, so licenses whose text quotes
+// another one (LGPL quoting the GPL, ISC extending 0BSD) come first
+var licenseFingerprints = normalizeFingerprints([]licenseFingerprint{
+	{"AGPL-3.0", []string{"gnu affero general public license", "version 3"}},
+	{"LGPL-3.0", []string{"gnu lesser general public license", "version 3"}},
+	{"LGPL-2.1", []string{"gnu lesser general public license", "version 2.1"}},
+	{"LGPL-2.0", []string{"gnu library general public license"}},
+	{"GPL-3.0", []string{"gnu general public license", "version 3, 29 june 2007"}},
+	{"GPL-3.0", []string{"gnu general public license", "version 3 of the license"}},
+	{"GPL-2.0", []string{"gnu general public license", "version 2, june 1991"}},
+	{"GPL-2.0", []string{"gnu general public license", "version 2 of the license"}},
+	{"Apache-2.0", []string{"apache license", "version 2.0"}},
+	{"MPL-2.0", []string{"mozilla public license", "2.0"}},
+	{"EPL-2.0", []string{"eclipse public license", "v 2.0"}},
+	{"EPL-2.0", []string{"eclipse public license", "version 2.0"}},
+	{"EPL-1.0", []string{"eclipse public license", "v 1.0"}},
+	{"BSL-1.0", []string{"boost software license", "version 1.0"}},
+	{"CC0-1.0", []string{"cc0 1.0 universal"}},
+	{"Unlicense", []string{"this is free and unencumbered software released into the public domain"}},
+	{"BSD-3-Clause", []string{"redistribution and use in source and binary forms", "neither the name"}},
+	{"BSD-3-Clause", []string{"redistribution and use in source and binary forms", "the names of its contributors may not be used"}},
+	{"BSD-2-Clause", []string{"redistribution and use in source and binary forms", "this list of conditions and the following disclaimer"}},
+	{"ISC", []string{"permission to use, copy, modify, and/or distribute this software for any purpose", "provided that the above copyright notice and this permission notice appear in all copies"}},
+	{"0BSD", []string{"permission to use, copy, modify, and/or distribute this software for any purpose with or without fee is hereby granted"}},
+	{"MIT", []string{"permission is hereby granted, free of charge, to any person obtaining a copy", "the above copyright notice and this permission notice shall be included"}},
+	{"MIT-0", []string{"permission is hereby granted, free of charge, to any person obtaining a copy"}},
+	{"Zlib", []string{"altered source versions must be plainly marked as such"}},
+	{"PSF-2.0", []string{"python software foundation license"}},
+})
+
+// licenseNames maps free-form license names, as found in POMs and package
+// metadata, to SPDX identifiers; the first match wins
+var licenseNames = []struct {
+	pattern *regexp.Regexp
+	id      string
+}{
+	{regexp.MustCompile(`affero|agpl`), "AGPL-3.0"},
+	{regexp.MustCompile(`(lesser|lgpl).*\bv?3`), "LGPL-3.0"},
+	{regexp.MustCompile(`(lesser|library|lgpl).*\bv?2\.1`), "LGPL-2.1"},
+	{regexp.MustCompile(`(general public license|gpl).*\bv?3`), "GPL-3.0"},
+	{regexp.MustCompile(`(general public license|gpl).*\bv?2`), "GPL-2.0"},
+	{regexp.MustCompile(`apache.*\b1\.1`), "Apache-1.1"},
+	{regexp.MustCompile(`apache`), "Apache-2.0"},
+	{regexp.MustCompile(`(mozilla|\bmpl).*\b1\.1`), "MPL-1.1"},
+	{regexp.MustCompile(`mozilla|\bmpl`), "MPL-2.0"},
+	{regexp.MustCompile(`eclipse distribution`), "BSD-3-Clause"},
+	{regexp.MustCompile(`(eclipse public|\bepl).*\bv?1\.0`), "EPL-1.0"},
+	{regexp.MustCompile(`eclipse public|\bepl`), "EPL-2.0"},
+	{regexp.MustCompile(`(cddl|common development and distribution).*\b1\.1`), "CDDL-1.1"},
+	{regexp.MustCompile(`cddl|common development and distribution`), "CDDL-1.0"},
+	{regexp.MustCompile(`bsd.*\b2|simplified bsd|freebsd`), "BSD-2-Clause"},
+	{regexp.MustCompile(`\bbsd`), "BSD-3-Clause"},
+	{regexp.MustCompile(`\bisc\b`), "ISC"},
+	{regexp.MustCompile(`\bmit\b`), "MIT"},
+	{regexp.MustCompile(`unlicense`), "Unlicense"},
+	{regexp.MustCompile(`\bcc0`), "CC0-1.0"},
+	{regexp.MustCompile(`boost`), "BSL-1.0"},
+	{regexp.MustCompile(`\bzlib`), "Zlib"},
+	{regexp.MustCompile(`python software foundation|\bpsf\b`), "PSF-2.0"},
+}
+
+// licenseURLs maps well-known license URLs used instead of names
+var licenseURLs = map[string]string{
+	"opensource.org/licenses/mit":          "MIT",
+	"opensource.org/licenses/mit-license":  "MIT",
+	"www.apache.org/licenses/license-2.0":  "Apache-2.0",
+	"opensource.org/licenses/apache-2.0":   "Apache-2.0",
+	"opensource.org/licenses/bsd-3-clause": "BSD-3-Clause",
+	"opensource.org/licenses/bsd-2-clause": "BSD-2-Clause",
+	"www.gnu.org/licenses/gpl-3.0":         "GPL-3.0",
+	"www.gnu.org/licenses/lgpl-3.0":        "LGPL-3.0",
+	"www.mozilla.org/mpl/2.0":              "MPL-2.0",
+	"www.eclipse.org/legal/epl-2.0":        "EPL-2.0",
+}
+
+// Licenses detects the licenses of every resolved dependency, of the project
+// itself and of project source files, and checks them against the policy
+func (a *ProjectAnalyzer) Licenses() (*LicenseReport, error) {
+	deps, warnings, err := a.AnalyzeDependencies(true)
+	if err != nil {
+		return nil, err
+	}
+	for _, warning := range warnings {
+		log.Printf("analyzer: %s", warning)
+	}
+
+	report := &LicenseReport{Policy: len(a.deps.LicenseAllow) > 0 || len(a.deps.LicenseDeny) > 0}
+
+	for _, root := range a.config.ProjectPaths {
+		absRoot, err := filepath.Abs(root)
+		if err != nil {
+			continue
+		}
+		for _, file := range licenseFiles(absRoot) {
+			if license := detectLicense(readLicenseFile(file, 0)); license != "" {
+				report.Project = append(report.Project, FileLicense{Path: a.displayPath(file), License: license})
+			}
+		}
+	}
+
+	a.ensureIndexed()
+	for _, file := range a.cache.Snapshot() {
+		if licenseFilePattern.MatchString(filepath.Base(file.Path)) {
+			continue
+		}
+		if license := detectLicense(sourceHeader(readLicenseFile(file.Path, maxHeaderBytes))); license != "" {
+			report.Files = append(report.Files, FileLicense{Path: a.displayPath(file.Path), License: license})
+		} else {
+			report.Unlabeled++
+		}
+	}
+
+	// The same package version is usually required by several modules
+	found := make(map[string]DependencyLicense)
+	for _, dep := range deps {
+		key := dep.Ecosystem + "\x00" + dep.Name + "\x00" + dep.Version
+		known, ok := found[key]
+		if !ok {
+			known.License, known.Source = dependencyLicense(dep, a.config.ProjectPaths)
+			found[key] = known
+		}
+		report.Dependencies = append(report.Dependencies, DependencyLicense{Dependency: dep, License: known.License, Source: known.Source})
+	}
+	sort.SliceStable(report.Dependencies, func(i, j int) bool {
+		return report.Dependencies[i].Dependency.Name < report.Dependencies[j].Dependency.Name
+	})
+
+	checked := make(map[string]bool)
+	for _, file := range append(append([]FileLicense{}, report.Project...), report.Files...) {
+		if reason := a.checkLicense(file.License); reason != "" {
+			report.Violations = append(report.Violations, LicenseViolation{Subject: file.Path, License: file.License, Reason: reason})
+		}
+	}
+	for _, dl := range report.Dependencies {
+		subject := dl.Dependency.Name + "@" + dl.Dependency.Version
+		if checked[subject] {
+			continue
+		}
+		checked[subject] = true
+		if reason := a.checkLicense(dl.License); reason != "" {
+			report.Violations = append(report.Violations, LicenseViolation{Subject: subject, License: dl.License, Reason: reason})
+		}
+	}
+
+	return report, nil
+}
+
+// checkLicense returns why an SPDX expression breaks the policy, or "" if
+// it complies. An OR expression complies when one alternative does, an AND
+// expression when all of its operands do.
+func (a *ProjectAnalyzer) checkLicense(expression string) string {
+	allow, deny := a.deps.LicenseAllow, a.deps.LicenseDeny
+	if len(allow) == 0 && len(deny) == 0 {
+		return ""
+	}
+	if expression == "" {
+		if len(allow) > 0 {
+			return "no license found in local package caches"
+		}
+		return ""
+	}
+
+	expr, err := parseLicenseExpression(expression)
+	if err != nil {
+		// Free-form names that are not expressions are checked as a whole
+		expr = &licenseExpr{id: strings.TrimSpace(expression)}
+	}
+	return expr.violation(allow, deny)
+}
+
+// licenseExpr is a parsed SPDX license expression: a license, or the OR or
+// AND of its operands
+type licenseExpr struct {
+	op       string // "OR", "AND", or empty for a license
+	id       string // license identifier without its WITH exception
+	operands []*licenseExpr
+}
+
+// violation returns why the expression breaks the allow and deny lists, or ""
+func (e *licenseExpr) violation(allow, deny []string) string {
+	switch e.op {
+	case "OR":
+		var reasons []string
+		for _, operand := range e.operands {
+			reason := operand.violation(allow, deny)
+			if reason == "" {
+				return ""
+			}
+			reasons = append(reasons, reason)
+		}
+		return strings.Join(reasons, "; ")
+	case "AND":
+		for _, operand := range e.operands {
+			if reason := operand.violation(allow, deny); reason != "" {
+				return reason
+			}
+		}
+		return ""
+	}
+
+	switch {
+	case matchLicense(deny, e.id):
+		return "denied license " + e.id
+	case len(allow) > 0 && !matchLicense(allow, e.id):
+		return "license " + e.id + " is not allowed"
+	}
+	return ""
+}
+
+// parseLicenseExpression parses an SPDX expression. AND binds tighter than
+// OR, and operators are matched case-insensitively as in package metadata.
+func parseLicenseExpression(expression string) (*licenseExpr, error) {
+	p := &licenseParser{tokens: licenseTokenPattern.FindAllString(expression, -1)}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q in license expression", p.tokens[p.pos])
+	}
+	return expr, nil
+}
+
+// licenseParser is a recursive-descent parser over the tokens of an SPDX expression
+type licenseParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *licenseParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *licenseParser) parseOr() (*licenseExpr, error) {
+	return p.parseOperator("OR", p.parseAnd)
+}
+
+func (p *licenseParser) parseAnd() (*licenseExpr, error) {
+	return p.parseOperator("AND", p.parseLicense)
+}
+
+// parseOperator parses operands joined by op into one expression
+func (p *licenseParser) parseOperator(op string, operand func() (*licenseExpr, error)) (*licenseExpr, error) {
+	first, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	expr := &licenseExpr{op: op, operands: []*licenseExpr{first}}
+	for strings.EqualFold(p.peek(), op) {
+		p.pos++
+		next, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		expr.operands = append(expr.operands, next)
+	}
+	if len(expr.operands) == 1 {
+		return first, nil
+	}
+	return expr, nil
+}
+
+// parseLicense parses a parenthesized expression or a license with an
+// optional exception, which narrows the license it applies to
+func (p *licenseParser) parseLicense() (*licenseExpr, error) {
+	token := p.peek()
+	switch {
+	case token == "":
+		return nil, fmt.Errorf("license expression ends early")
+	case token == "(":
+		p.pos++
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, fmt.Errorf("missing ) in license expression")
+		}
+		p.pos++
+		return expr, nil
+	case token == ")" || isLicenseOperator(token):
+		return nil, fmt.Errorf("unexpected %q in license expression", token)
+	}
+
+	p.pos++
+	if strings.EqualFold(p.peek(), "WITH") {
+		p.pos++
+		if exception := p.peek(); exception == "" || exception == "(" || exception == ")" || isLicenseOperator(exception) {
+			return nil, fmt.Errorf("missing exception after WITH in license expression")
+		}
+		p.pos++
+	}
+	return &licenseExpr{id: token}, nil
+}
+
+func isLicenseOperator(token string) bool {
+	return strings.EqualFold(token, "OR") || strings.EqualFold(token, "AND") || strings.EqualFold(token, "WITH")
+}
+
+// matchLicense reports whether id is in the list. Entries match their
+// -only, -or-later and + variants, and a trailing * matches any suffix.
+func matchLicense(list []string, id string) bool {
+	for _, entry := range list {
+		entry = strings.TrimSpace(entry)
+		if prefix, ok := strings.CutSuffix(entry, "*"); ok {
+			if strings.HasPrefix(strings.ToLower(id), strings.ToLower(prefix)) {
+				return true
+			}
+			continue
+		}
+		if strings.EqualFold(id, entry) {
+			return true
+		}
+		for _, suffix := range []string{"-only", "-or-later", "+"} {
+			if strings.EqualFold(id, entry+suffix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// dependencyLicense finds the license of a dependency in the local package
+// caches of its ecosystem, returning the license and where it was found
+func dependencyLicense(dep Dependency, projectPaths []string) (string, string) {
+	switch dep.Ecosystem {
+	case "go":
+		dir := filepath.Join(moduleCacheDir(), escapeModulePath(dep.Name)+"@"+escapeModulePath(dep.Version))
+		return licenseFromDir(dir)
+	case "npm":
+		return npmLicense(dep, projectPaths)
+	case "python":
+		return pythonLicense(dep, projectPaths)
+	case "cargo":
+		return cargoLicense(dep)
+	case "maven", "gradle":
+		return mavenLicense(dep)
+	case "nuget":
+		return nugetLicense(dep)
+	}
+	return "", ""
+}
+
+// licenseFiles returns the LICENSE, LICENCE, COPYING and UNLICENSE files of dir
+func licenseFiles(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && licenseFilePattern.MatchString(entry.Name()) {
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	return files
+}
+
+// licenseFromDir detects the licenses of the license files in dir; several
+// distinct licenses (LICENSE-MIT, LICENSE-APACHE) must all be honored
+func licenseFromDir(dir string) (string, string) {
+	var licenses, sources []string
+	for _, file := range licenseFiles(dir) {
+		if license := detectLicense(readLicenseFile(file, 0)); license != "" {
+			sources = append(sources, file)
+			if !containsString(licenses, license) {
+				licenses = append(licenses, license)
+			}
+		}
+	}
+	if len(licenses) == 0 {
+		return "", ""
+	}
+	return strings.Join(licenses, " AND "), strings.Join(sources, ", ")
+}
+
+// readLicenseFile reads a file, or its first limit bytes when limit > 0
+func readLicenseFile(path string, limit int64) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if limit > 0 {
+		r = io.LimitReader(f, limit)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// detectLicense identifies the licenses of a license file or source header,
+// preferring an SPDX-License-Identifier tag over the license text. A file
+// combining several license texts yields all of them.
+func detectLicense(text string) string {
+	if m := spdxIdentifierPattern.FindStringSubmatch(text); m != nil {
+		return strings.TrimSpace(m[1])
+	}
+
+	normalized := normalizeLicenseText(text)
+	var licenses []string
+	for _, fp := range licenseFingerprints {
+		matched := true
+		for _, phrase := range fp.phrases {
+			if !strings.Contains(normalized, phrase) {
+				matched = false
+				break
+			}
+		}
+		if !matched {
+			continue
+		}
+		if !containsString(licenses, fp.id) {
+			licenses = append(licenses, fp.id)
+		}
+		for _, phrase := range fp.phrases {
+			normalized = strings.ReplaceAll(normalized, phrase, " ")
+		}
+	}
+	return strings.Join(licenses, " AND ")
+}
+
+// sourceHeader returns the comments at the start of a source file, where
+// license headers live, so that license texts quoted in code do not count
+func sourceHeader(text string) string {
+	var header strings.Builder
+	block := ""
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case block != "":
+			if strings.Contains(trimmed, block) {
+				block = ""
+			}
+		case trimmed == "" || strings.HasPrefix(trimmed, "#!"):
+			continue
+		case strings.HasPrefix(trimmed, "/*"):
+			if !strings.Contains(trimmed[2:], "*/") {
+				block = "*/"
+			}
+		case strings.HasPrefix(trimmed, "<!--"):
+			if !strings.Contains(trimmed[4:], "-->") {
+				block = "-->"
+			}
+		case strings.HasPrefix(trimmed, "//"), strings.HasPrefix(trimmed, "#"),
+			strings.HasPrefix(trimmed, "--"), strings.HasPrefix(trimmed, ";"):
+		default:
+			return header.String()
+		}
+		header.WriteString(trimmed)
+		header.WriteString("\n")
+	}
+	return header.String()
+}
+
+// normalizeLicenseText lowercases text and folds whitespace and comment
+// markers so that wrapped and commented license texts match alike
+func normalizeLicenseText(text string) string {
+	return licenseSeparators.ReplaceAllString(strings.ToLower(text), " ")
+}
+
+func normalizeFingerprints(fps []licenseFingerprint) []licenseFingerprint {
+	for i := range fps {
+		for j, phrase := range fps[i].phrases {
+			fps[i].phrases[j] = normalizeLicenseText(phrase)
+		}
+	}
+	return fps
+}
+
+// licenseFromName converts a license name or URL from package metadata to
+// an SPDX expression, returning names it does not recognize unchanged
+func licenseFromName(name string) string {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return ""
+	}
+
+	// Bare words in an expression may still be names ("Apache OR MIT")
+	if spdxExpressionPattern.MatchString(name) {
+		return spdxTermPattern.ReplaceAllStringFunc(name, func(term string) string {
+			if term == "OR" || term == "AND" || term == "WITH" || strings.ContainsAny(term, "-0123456789") {
+				return term
+			}
+			if id, ok := matchLicenseName(term); ok {
+				return id
+			}
+			return term
+		})
+	}
+
+	lower := strings.ToLower(name)
+	if _, url, ok := strings.Cut(lower, "://"); ok {
+		url = strings.TrimSuffix(url, "/")
+		for _, ext := range []string{".txt", ".php", ".html"} {
+			url = strings.TrimSuffix(url, ext)
+		}
+		if id, ok := licenseURLs[url]; ok {
+			return id
+		}
+		return name
+	}
+	if id, ok := matchLicenseName(name); ok {
+		return id
+	}
+	return name
+}
+
+func matchLicenseName(name string) (string, bool) {
+	lower := strings.ToLower(name)
+	for _, entry := range licenseNames {
+		if entry.pattern.MatchString(lower) {
+			return entry.id, true
+		}
+	}
+	return "", false
+}
+
+var (
+	// spdxExpressionPattern matches names that already are SPDX expressions
+	spdxExpressionPattern = regexp.MustCompile(`^\(?[A-Za-z0-9.+-]+\)?(\s+(OR|AND|WITH)\s+\(?[A-Za-z0-9.+-]+\)?)*$`)
+	spdxTermPattern       = regexp.MustCompile(`[A-Za-z0-9.+-]+`)
+	licenseTokenPattern   = regexp.MustCompile(`[()]|[^\s()]+`)
+)
+
+// npmLicense reads the license field of a package installed in node_modules
+// next to the manifest or in a parent directory of it
+func npmLicense(dep Dependency, projectPaths []string) (string, string) {
+	dirs := []string{}
+	if dep.Path != "" {
+		for dir := filepath.Dir(dep.Path); ; dir = filepath.Dir(dir) {
+			dirs = append(dirs, dir)
+			if filepath.Dir(dir) == dir {
+				break
+			}
+		}
+	}
+	for _, root := range projectPaths {
+		if absRoot, err := filepath.Abs(root); err == nil {
+			dirs = append(dirs, absRoot)
+		}
+	}
+
+	for _, dir := range dirs {
+		pkgDir := filepath.Join(dir, "node_modules", filepath.FromSlash(dep.Name))
+		data, err := os.ReadFile(filepath.Join(pkgDir, "package.json"))
+		if err != nil {
+			continue
+		}
+		var manifest struct {
+			License  json.RawMessage `json:"license"`
+			Licenses []struct {
+				Type string `json:"type"`
+			} `json:"licenses"`
+		}
+		if json.Unmarshal(data, &manifest) == nil {
+			// "license" is an SPDX expression or, in old packages, {"type": ...}
+			var license string
+			var typed struct {
+				Type string `json:"type"`
+			}
+			if json.Unmarshal(manifest.License, &license) != nil && json.Unmarshal(manifest.License, &typed) == nil {
+				license = typed.Type
+			}
+			if license == "" && len(manifest.Licenses) > 0 {
+				var types []string
+				for _, l := range manifest.Licenses {
+					types = append(types, licenseFromName(l.Type))
+				}
+				license = strings.Join(types, " OR ")
+			}
+			if license != "" && !strings.HasPrefix(license, "SEE LICENSE IN") {
+				return licenseFromName(license), filepath.Join(pkgDir, "package.json")
+			}
+		}
+		return licenseFromDir(pkgDir)
+	}
+	return "", ""
+}
+
+// pythonLicense reads the metadata of a distribution installed in a
+// virtual environment of a project path
+func pythonLicense(dep Dependency, projectPaths []string) (string, string) {
+	name := normalizePythonName(dep.Name)
+	for _, root := range projectPaths {
+		absRoot, err := filepath.Abs(root)
+		if err != nil {
+			continue
+		}
+		var sitePackages []string
+		for _, venv := range []string{".venv", "venv", "env", ".env"} {
+			matches, _ := filepath.Glob(filepath.Join(absRoot, venv, "lib", "python*", "site-packages"))
+			sitePackages = append(sitePackages, matches...)
+			sitePackages = append(sitePackages, filepath.Join(absRoot, venv, "Lib", "site-packages"))
+		}
+
+		for _, dir := range sitePackages {
+			distInfos, _ := filepath.Glob(filepath.Join(dir, "*.dist-info"))
+			for _, distInfo := range distInfos {
+				dist := strings.TrimSuffix(filepath.Base(distInfo), ".dist-info")
+				if normalizePythonName(strings.SplitN(dist, "-", 2)[0]) != name {
+					continue
+				}
+				if license := pythonMetadataLicense(filepath.Join(distInfo, "METADATA")); license != "" {
+					return license, filepath.Join(distInfo, "METADATA")
+				}
+				if license, source := licenseFromDir(filepath.Join(distInfo, "licenses")); license != "" {
+					return license, source
+				}
+				return licenseFromDir(distInfo)
+			}
+		}
+	}
+	return "", ""
+}
+
+// pythonMetadataLicense reads License-Expression, license classifiers or a
+// short License field from a METADATA file
+func pythonMetadataLicense(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	var license string
+	var classifiers []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			break // the description body follows the headers
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch key {
+		case "License-Expression":
+			return value
+		case "License":
+			if len(value) < 64 {
+				license = value
+			}
+		case "Classifier":
+			if name, ok := strings.CutPrefix(value, "License :: OSI Approved :: "); ok {
+				classifiers = append(classifiers, licenseFromName(name))
+			}
+		}
+	}
+	if len(classifiers) > 0 {
+		return strings.Join(classifiers, " OR ")
+	}
+	if license != "" && !strings.EqualFold(license, "UNKNOWN") {
+		return licenseFromName(license)
+	}
+	return ""
+}
+
+// cargoLicense reads the license field of a crate in the cargo registry
+func cargoLicense(dep Dependency) (string, string) {
+	cargoHome := os.Getenv("CARGO_HOME")
+	if cargoHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", ""
+		}
+		cargoHome = filepath.Join(home, ".cargo")
+	}
+
+	dirs, _ := filepath.Glob(filepath.Join(cargoHome, "registry", "src", "*", dep.Name+"-"+dep.Version))
+	for _, dir := range dirs {
+		var manifest struct {
+			Package struct {
+				License string `toml:"license"`
+			} `toml:"package"`
+		}
+		manifestPath := filepath.Join(dir, "Cargo.toml")
+		if _, err := toml.DecodeFile(manifestPath, &manifest); err == nil && manifest.Package.License != "" {
+			// Older crates separate alternatives with a slash
+			return strings.ReplaceAll(manifest.Package.License, "/", " OR "), manifestPath
+		}
+		return licenseFromDir(dir)
+	}
+	return "", ""
+}
+
+// mavenLicense reads the licenses of an artifact's POM in the local Maven
+// repository or the Gradle cache, following parent POMs
+func mavenLicense(dep Dependency) (string, string) {
+	group, artifact, ok := strings.Cut(dep.Name, ":")
+	if !ok || !isConcreteVersion(dep.Version) {
+		return "", ""
+	}
+
+	for depth := 0; depth < 5; depth++ {
+		path := cachedPOM(group, artifact, dep.Version)
+		if path == "" {
+			return "", ""
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", ""
+		}
+		var pom struct {
+			Parent struct {
+				GroupID    string `xml:"groupId"`
+				ArtifactID string `xml:"artifactId"`
+				Version    string `xml:"version"`
+			} `xml:"parent"`
+			Licenses []struct {
+				Name string `xml:"name"`
+				URL  string `xml:"url"`
+			} `xml:"licenses>license"`
+		}
+		if err := xml.Unmarshal(data, &pom); err != nil {
+			return "", ""
+		}
+
+		if len(pom.Licenses) > 0 {
+			var licenses []string
+			for _, l := range pom.Licenses {
+				license := licenseFromName(l.Name)
+				if license == "" {
+					license = licenseFromName(l.URL)
+				}
+				if license != "" && !containsString(licenses, license) {
+					licenses = append(licenses, license)
+				}
+			}
+			// Multiple licenses in a POM are alternatives
+			return strings.Join(licenses, " OR "), path
+		}
+		if pom.Parent.ArtifactID == "" {
+			return "", ""
+		}
+		group, artifact, dep.Version = pom.Parent.GroupID, pom.Parent.ArtifactID, pom.Parent.Version
+	}
+	return "", ""
+}
+
+// cachedPOM locates the POM of an artifact version in ~/.m2 or the Gradle cache
+func cachedPOM(group, artifact, version string) string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	file := fmt.Sprintf("%s-%s.pom", artifact, version)
+
+	path := filepath.Join(home, ".m2", "repository", filepath.FromSlash(strings.ReplaceAll(group, ".", "/")), artifact, version, file)
+	if _, err := os.Stat(path); err == nil {
+		return path
+	}
+	gradleHome := os.Getenv("GRADLE_USER_HOME")
+	if gradleHome == "" {
+		gradleHome = filepath.Join(home, ".gradle")
+	}
+	matches, _ := filepath.Glob(filepath.Join(gradleHome, "caches", "modules-2", "files-2.1", group, artifact, version, "*", file))
+	if len(matches) > 0 {
+		return matches[0]
+	}
+	return ""
+}
+
+// nugetLicense reads the license of a package's nuspec in the global
+// packages folder
+func nugetLicense(dep Dependency) (string, string) {
+	packages := os.Getenv("NUGET_PACKAGES")
+	if packages == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", ""
+		}
+		packages = filepath.Join(home, ".nuget", "packages")
+	}
+	id := strings.ToLower(dep.Name)
+	dir := filepath.Join(packages, id, strings.ToLower(dep.Version))
+
+	data, err := os.ReadFile(filepath.Join(dir, id+".nuspec"))
+	if err != nil {
+		return "", ""
+	}
+	var nuspec struct {
+		Metadata struct {
+			License struct {
+				Type  string `xml:"type,attr"`
+				Value string `xml:",chardata"`
+			} `xml:"license"`
+			LicenseURL string `xml:"licenseUrl"`
+		} `xml:"metadata"`
+	}
+	if err := xml.Unmarshal(data, &nuspec); err != nil {
+		return "", ""
+	}
+	source := filepath.Join(dir, id+".nuspec")
+	license := nuspec.Metadata.License
+	switch {
+	case license.Type == "expression":
+		return strings.TrimSpace(license.Value), source
+	case license.Type == "file":
+		if detected := detectLicense(readLicenseFile(filepath.Join(dir, filepath.FromSlash(license.Value)), 0)); detected != "" {
+			return detected, filepath.Join(dir, license.Value)
+		}
+	case nuspec.Metadata.LicenseURL != "":
+		if detected := licenseFromName(nuspec.Metadata.LicenseURL); detected != nuspec.Metadata.LicenseURL {
+			return detected, source
+		}
+	}
+	return licenseFromDir(dir)
+}
+
+func containsString(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,249 @@
+package analyzer
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/scopweb/mcp-go-context/internal/config"
+)
+
+const (
+	mitText = `MIT License
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction.
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+`
+	apacheText = `                                 Apache License
+                           Version 2.0, January 2004
+                        http://www.apache.org/licenses/
+`
+	lgplText = `GNU LESSER GENERAL PUBLIC LICENSE
+Version 3, 29 June 2007
+
+This version of the GNU Lesser General Public License incorporates the terms
+and conditions of version 3 of the GNU General Public License.
+`
+)
+
+func TestDetectLicense(t *testing.T) {
+	tests := []struct {
+		name, text, want string
+	}{
+		{"mit text", mitText, "MIT"},
+		{"apache text", apacheText, "Apache-2.0"},
+		{"lgpl quoting the gpl", lgplText, "LGPL-3.0"},
+		{"combined texts", mitText + "\n" + apacheText, "Apache-2.0 AND MIT"},
+		{"spdx tag wins", "// SPDX-License-Identifier: MIT OR Apache-2.0\n" + lgplText, "MIT OR Apache-2.0"},
+		{"spdx tag in a block comment", "/* SPDX-License-Identifier: BSD-3-Clause */\n", "BSD-3-Clause"},
+		{"commented text", "// " + strings.ReplaceAll(mitText, "\n", "\n// "), "MIT"},
+		{"unknown", "All rights reserved.\n", ""},
+	}
+	for _, tt := range tests {
+		if got := detectLicense(tt.text); got != tt.want {
+			t.Errorf("%s: detectLicense() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLicenseFromName(t *testing.T) {
+	tests := map[string]string{
+		"":    "",
+		"MIT": "MIT",
+		"The Apache Software License, Version 2.0": "Apache-2.0",
+		"https://opensource.org/licenses/MIT":      "MIT",
+		"Apache OR MIT":                            "Apache-2.0 OR MIT",
+		"GPL-2.0 WITH Classpath-exception-2.0":     "GPL-2.0 WITH Classpath-exception-2.0",
+		"Proprietary":                              "Proprietary",
+	}
+	for name, want := range tests {
+		if got := licenseFromName(name); got != want {
+			t.Errorf("licenseFromName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestCheckLicense(t *testing.T) {
+	tests := []struct {
+		allow, deny []string
+		expression  string
+		want        string
+	}{
+		{nil, nil, "GPL-3.0", ""},
+		{[]string{"MIT"}, nil, "", "no license found in local package caches"},
+		{nil, []string{"GPL-3.0"}, "", ""},
+		{[]string{"MIT"}, nil, "MIT", ""},
+		{[]string{"MIT"}, nil, "GPL-3.0", "license GPL-3.0 is not allowed"},
+		{[]string{"MIT"}, nil, "MIT OR GPL-3.0", ""},
+		{[]string{"MIT"}, nil, "GPL-3.0 or LGPL-3.0", "license GPL-3.0 is not allowed; license LGPL-3.0 is not allowed"},
+		{[]string{"MIT", "Apache-2.0"}, nil, "MIT AND Apache-2.0", ""},
+		{nil, []string{"GPL*"}, "Apache-2.0 AND GPL-3.0-only", "denied license GPL-3.0-only"},
+		{nil, []string{"GPL-2.0"}, "GPL-2.0-or-later", "denied license GPL-2.0-or-later"},
+		{[]string{"GPL-2.0"}, nil, "GPL-2.0 WITH Classpath-exception-2.0", ""},
+		{[]string{"MIT"}, nil, "(MIT)", ""},
+		// Parentheses group operands; AND binds tighter than OR
+		{nil, []string{"MIT"}, "MIT AND (Apache-2.0 OR GPL-3.0)", "denied license MIT"},
+		{nil, []string{"GPL-3.0"}, "(MIT OR GPL-3.0) AND Apache-2.0", ""},
+		{nil, []string{"GPL-3.0"}, "MIT AND (Apache-2.0 OR GPL-3.0)", ""},
+		{nil, []string{"GPL-3.0"}, "MIT AND GPL-3.0 OR Apache-2.0", ""},
+		{nil, []string{"GPL-3.0"}, "(MIT AND GPL-3.0) OR (Apache-2.0 AND GPL-3.0)", "denied license GPL-3.0; denied license GPL-3.0"},
+		{[]string{"Apache-2.0"}, nil, "((MIT OR Apache-2.0))", ""},
+		{[]string{"MIT"}, nil, "MIT OR", "license MIT OR is not allowed"},
+	}
+	for _, tt := range tests {
+		a := &ProjectAnalyzer{deps: config.DependencyConfig{LicenseAllow: tt.allow, LicenseDeny: tt.deny}}
+		if got := a.checkLicense(tt.expression); got != tt.want {
+			t.Errorf("checkLicense(%q) with allow %v, deny %v = %q, want %q", tt.expression, tt.allow, tt.deny, got, tt.want)
+		}
+	}
+}
+
+func TestParseLicenseExpression(t *testing.T) {
+	tests := []struct {
+		expression string
+		want       string // operands in prefix form
+	}{
+		{"MIT", "MIT"},
+		{"MIT OR Apache-2.0", "OR(MIT Apache-2.0)"},
+		{"MIT AND Apache-2.0 OR GPL-3.0", "OR(AND(MIT Apache-2.0) GPL-3.0)"},
+		{"MIT AND (Apache-2.0 OR GPL-3.0)", "AND(MIT OR(Apache-2.0 GPL-3.0))"},
+		{"GPL-2.0 WITH Classpath-exception-2.0 OR MIT", "OR(GPL-2.0 MIT)"},
+		{"(", ""},
+		{"MIT AND", ""},
+		{"(MIT OR Apache-2.0", ""},
+		{"MIT)", ""},
+		{"MIT WITH", ""},
+		{"Apache License 2.0", ""},
+	}
+	var format func(e *licenseExpr) string
+	format = func(e *licenseExpr) string {
+		if e.op == "" {
+			return e.id
+		}
+		var operands []string
+		for _, operand := range e.operands {
+			operands = append(operands, format(operand))
+		}
+		return e.op + "(" + strings.Join(operands, " ") + ")"
+	}
+	for _, tt := range tests {
+		expr, err := parseLicenseExpression(tt.expression)
+		got := ""
+		if err == nil {
+			got = format(expr)
+		}
+		if got != tt.want {
+			t.Errorf("parseLicenseExpression(%q) = %q (%v), want %q", tt.expression, got, err, tt.want)
+		}
+	}
+}
+
+func TestNPMLicense(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"package.json":                                 `{"name": "app"}`,
+		"packages/web/package.json":                    `{"name": "web"}`,
+		"node_modules/left-pad/package.json":           `{"name": "left-pad", "license": "WTFPL OR MIT"}`,
+		"node_modules/@scope/pkg/package.json":         `{"name": "@scope/pkg", "license": {"type": "ISC"}}`,
+		"node_modules/old/package.json":                `{"name": "old", "licenses": [{"type": "MIT"}, {"type": "Apache 2.0"}]}`,
+		"node_modules/custom/package.json":             `{"name": "custom", "license": "SEE LICENSE IN LICENSE"}`,
+		"node_modules/custom/LICENSE":                  mitText,
+		"packages/web/node_modules/local/package.json": `{"name": "local", "license": "BSD-3-Clause"}`,
+	})
+
+	tests := []struct {
+		name, manifest  string
+		license, source string
+	}{
+		{"left-pad", "package.json", "WTFPL OR MIT", "node_modules/left-pad/package.json"},
+		{"@scope/pkg", "package.json", "ISC", "node_modules/@scope/pkg/package.json"},
+		{"old", "package.json", "MIT OR Apache-2.0", "node_modules/old/package.json"},
+		{"custom", "package.json", "MIT", "node_modules/custom/LICENSE"},
+		{"local", "packages/web/package.json", "BSD-3-Clause", "packages/web/node_modules/local/package.json"},
+		{"left-pad", "packages/web/package.json", "WTFPL OR MIT", "node_modules/left-pad/package.json"},
+		{"missing", "package.json", "", ""},
+	}
+	for _, tt := range tests {
+		dep := Dependency{Name: tt.name, Ecosystem: "npm", Path: filepath.Join(root, filepath.FromSlash(tt.manifest))}
+		license, source := npmLicense(dep, []string{root})
+		if rel, _ := filepath.Rel(root, source); source != "" {
+			source = filepath.ToSlash(rel)
+		}
+		if license != tt.license || source != tt.source {
+			t.Errorf("npmLicense(%s from %s) = %q, %q; want %q, %q", tt.name, tt.manifest, license, source, tt.license, tt.source)
+		}
+	}
+}
+
+func TestPythonLicense(t *testing.T) {
+	root := t.TempDir()
+	site := ".venv/lib/python3.12/site-packages/"
+	writeFiles(t, root, map[string]string{
+		site + "requests-2.31.0.dist-info/METADATA":  "Metadata-Version: 2.1\nName: requests\nLicense: Apache 2.0\n\nLicense: MIT in the body\n",
+		site + "Flask_Cors-4.0.0.dist-info/METADATA": "Metadata-Version: 2.4\nName: Flask-Cors\nLicense-Expression: MIT\n",
+		site + "attrs-23.1.0.dist-info/METADATA": "Metadata-Version: 2.1\nName: attrs\nLicense: UNKNOWN\n" +
+			"Classifier: License :: OSI Approved :: MIT License\nClassifier: License :: OSI Approved :: Apache Software License\n",
+		site + "six-1.16.0.dist-info/METADATA":         "Metadata-Version: 2.1\nName: six\n",
+		site + "six-1.16.0.dist-info/licenses/LICENSE": mitText,
+	})
+
+	tests := []struct {
+		name, license, source string
+	}{
+		{"requests", "Apache-2.0", site + "requests-2.31.0.dist-info/METADATA"},
+		{"flask_cors", "MIT", site + "Flask_Cors-4.0.0.dist-info/METADATA"},
+		{"attrs", "MIT OR Apache-2.0", site + "attrs-23.1.0.dist-info/METADATA"},
+		{"six", "MIT", site + "six-1.16.0.dist-info/licenses/LICENSE"},
+		{"numpy", "", ""},
+	}
+	for _, tt := range tests {
+		license, source := pythonLicense(Dependency{Name: tt.name, Ecosystem: "python"}, []string{root})
+		if rel, _ := filepath.Rel(root, source); source != "" {
+			source = filepath.ToSlash(rel)
+		}
+		if license != tt.license || source != tt.source {
+			t.Errorf("pythonLicense(%s) = %q, %q; want %q, %q", tt.name, license, source, tt.license, tt.source)
+		}
+	}
+}
+
+func TestCargoAndGoLicenses(t *testing.T) {
+	cargoHome := t.TempDir()
+	modCache := t.TempDir()
+	t.Setenv("CARGO_HOME", cargoHome)
+	t.Setenv("GOMODCACHE", modCache)
+	registry := "registry/src/index.crates.io-6f17d22bba15001f/"
+	writeFiles(t, cargoHome, map[string]string{
+		registry + "serde-1.0.193/Cargo.toml":   "[package]\nname = \"serde\"\nlicense = \"MIT OR Apache-2.0\"\n",
+		registry + "libc-0.2.40/Cargo.toml":     "[package]\nname = \"libc\"\nlicense = \"MIT/Apache-2.0\"\n",
+		registry + "ring-0.17.7/Cargo.toml":     "[package]\nname = \"ring\"\nlicense-file = \"LICENSE\"\n",
+		registry + "ring-0.17.7/LICENSE":        mitText,
+		registry + "ring-0.17.7/LICENSE-APACHE": apacheText,
+	})
+	writeFiles(t, modCache, map[string]string{
+		"github.com/!burnt!sushi/toml@v1.3.2/COPYING": mitText,
+		"golang.org/x/mod@v0.14.0/LICENSE":            "Redistribution and use in source and binary forms, with or without modification.\nNeither the name of Google Inc. nor the names of its contributors may be used.\n",
+	})
+
+	tests := []struct {
+		dep     Dependency
+		license string
+	}{
+		{Dependency{Name: "serde", Version: "1.0.193", Ecosystem: "cargo"}, "MIT OR Apache-2.0"},
+		{Dependency{Name: "libc", Version: "0.2.40", Ecosystem: "cargo"}, "MIT OR Apache-2.0"},
+		{Dependency{Name: "ring", Version: "0.17.7", Ecosystem: "cargo"}, "MIT AND Apache-2.0"},
+		{Dependency{Name: "serde", Version: "2.0.0", Ecosystem: "cargo"}, ""},
+		{Dependency{Name: "github.com/BurntSushi/toml", Version: "v1.3.2", Ecosystem: "go"}, "MIT"},
+		{Dependency{Name: "golang.org/x/mod", Version: "v0.14.0", Ecosystem: "go"}, "BSD-3-Clause"},
+		{Dependency{Name: "golang.org/x/mod", Version: "v0.15.0", Ecosystem: "go"}, ""},
+	}
+	for _, tt := range tests {
+		if license, _ := dependencyLicense(tt.dep, nil); license != tt.license {
+			t.Errorf("dependencyLicense(%s@%s) = %q, want %q", tt.dep.Name, tt.dep.Version, license, tt.license)
+		}
+	}
+}
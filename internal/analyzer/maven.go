@@ -0,0 +1,225 @@
+package analyzer
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+func init() {
+	mustRegisterDependencyParser(mavenParser{})
+}
+
+// mavenParser reads pom.xml files, following <modules> and local parent POMs
+// for inherited properties and dependencyManagement versions
+type mavenParser struct{}
+
+var mavenPropertyPattern = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+// mavenPOM is the subset of pom.xml used for dependency analysis
+type mavenPOM struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+	Parent     struct {
+		GroupID      string  `xml:"groupId"`
+		ArtifactID   string  `xml:"artifactId"`
+		Version      string  `xml:"version"`
+		RelativePath *string `xml:"relativePath"`
+	} `xml:"parent"`
+	Properties struct {
+		Entries []struct {
+			XMLName xml.Name
+			Value   string `xml:",chardata"`
+		} `xml:",any"`
+	} `xml:"properties"`
+	Dependencies         []mavenDependency `xml:"dependencies>dependency"`
+	DependencyManagement []mavenDependency `xml:"dependencyManagement>dependencies>dependency"`
+	Modules              []string          `xml:"modules>module"`
+}
+
+type mavenDependency struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+	Scope      string `xml:"scope"`
+	Optional   string `xml:"optional"`
+}
+
+// mavenProject is a POM merged with its local parents
+type mavenProject struct {
+	id         string // groupId:artifactId
+	properties map[string]string
+	managed    map[string]string // groupId:artifactId -> version
+	pom        *mavenPOM
+}
+
+func (mavenParser) Ecosystem() string { return "maven" }
+
+func (mavenParser) Detect(root string) bool {
+	_, err := os.Stat(filepath.Join(root, "pom.xml"))
+	return err == nil
+}
+
+func (mavenParser) Parse(root string, includeTransitive bool) ([]Dependency, error) {
+	var deps []Dependency
+
+	rootPOM := filepath.Join(root, "pom.xml")
+	visited := make(map[string]bool)
+	queue := []string{rootPOM}
+	for len(queue) > 0 {
+		path := queue[0]
+		queue = queue[1:]
+		if visited[path] {
+			continue
+		}
+		visited[path] = true
+
+		project, err := loadMavenProject(path, 0)
+		if err != nil {
+			if path == rootPOM {
+				return nil, err
+			}
+			continue
+		}
+
+		for _, d := range project.pom.Dependencies {
+			// Imported BOMs only carry versions
+			if d.Scope == "import" {
+				continue
+			}
+			name := project.resolve(d.GroupID) + ":" + project.resolve(d.ArtifactID)
+			constraint := project.resolve(d.Version)
+			if constraint == "" {
+				constraint = project.managed[name]
+			}
+
+			dep := Dependency{
+				Name:      name,
+				Version:   constraint,
+				Type:      mavenScopeType(d.Scope),
+				Path:      path,
+				Module:    project.id,
+				Ecosystem: "maven",
+			}
+			if strings.TrimSpace(d.Optional) == "true" && dep.Type == "direct" {
+				dep.Type = "optional"
+			}
+			// Ranges such as [1.0,2.0) stay ranges; there is no lockfile.
+			// Versions left out come from a BOM or parent not on disk.
+			if dep.Version == "" {
+				dep.Version = "managed"
+			}
+			deps = append(deps, dep)
+		}
+
+		for _, module := range project.pom.Modules {
+			modulePath := filepath.Join(filepath.Dir(path), filepath.FromSlash(strings.TrimSpace(module)))
+			if !strings.HasSuffix(modulePath, ".xml") {
+				modulePath = filepath.Join(modulePath, "pom.xml")
+			}
+			queue = append(queue, modulePath)
+		}
+	}
+
+	return deps, nil
+}
+
+// loadMavenProject reads a POM and merges properties and managed versions of
+// parents available on disk
+func loadMavenProject(path string, depth int) (*mavenProject, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var pom mavenPOM
+	if err := xml.Unmarshal(data, &pom); err != nil {
+		return nil, err
+	}
+
+	project := &mavenProject{
+		properties: make(map[string]string),
+		managed:    make(map[string]string),
+		pom:        &pom,
+	}
+
+	// Parent first, so the child's own values override inherited ones
+	if pom.Parent.ArtifactID != "" && depth < 10 {
+		relative := "../pom.xml"
+		if pom.Parent.RelativePath != nil {
+			relative = strings.TrimSpace(*pom.Parent.RelativePath)
+		}
+		if relative != "" {
+			parentPath := filepath.Join(filepath.Dir(path), filepath.FromSlash(relative))
+			if !strings.HasSuffix(parentPath, ".xml") {
+				parentPath = filepath.Join(parentPath, "pom.xml")
+			}
+			if parent, err := loadMavenProject(parentPath, depth+1); err == nil && parent.pom.ArtifactID == pom.Parent.ArtifactID {
+				for k, v := range parent.properties {
+					project.properties[k] = v
+				}
+				for k, v := range parent.managed {
+					project.managed[k] = v
+				}
+			}
+		}
+	}
+
+	groupID := pom.GroupID
+	if groupID == "" {
+		groupID = pom.Parent.GroupID
+	}
+	version := pom.Version
+	if version == "" {
+		version = pom.Parent.Version
+	}
+	project.id = groupID + ":" + pom.ArtifactID
+
+	project.properties["project.groupId"] = groupID
+	project.properties["project.artifactId"] = pom.ArtifactID
+	project.properties["project.version"] = version
+	project.properties["project.parent.groupId"] = pom.Parent.GroupID
+	project.properties["project.parent.version"] = pom.Parent.Version
+	for _, entry := range pom.Properties.Entries {
+		project.properties[entry.XMLName.Local] = strings.TrimSpace(entry.Value)
+	}
+
+	for _, d := range pom.DependencyManagement {
+		if d.Scope == "import" {
+			continue
+		}
+		name := project.resolve(d.GroupID) + ":" + project.resolve(d.ArtifactID)
+		project.managed[name] = project.resolve(d.Version)
+	}
+
+	return project, nil
+}
+
+// resolve interpolates ${property} references
+func (p *mavenProject) resolve(value string) string {
+	value = strings.TrimSpace(value)
+	for i := 0; i < 5 && strings.Contains(value, "${"); i++ {
+		value = mavenPropertyPattern.ReplaceAllStringFunc(value, func(ref string) string {
+			if v, ok := p.properties[ref[2:len(ref)-1]]; ok {
+				return v
+			}
+			return ref
+		})
+	}
+	return value
+}
+
+// mavenScopeType maps a Maven scope to a dependency type: provided
+// dependencies are supplied by the runtime, like npm peer dependencies
+func mavenScopeType(scope string) string {
+	switch strings.TrimSpace(scope) {
+	case "test":
+		return "dev"
+	case "provided":
+		return "peer"
+	default:
+		return "direct"
+	}
+}
@@ -0,0 +1,143 @@
+package analyzer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMavenParser(t *testing.T) {
+	parent := `<project>
+  <groupId>com.example</groupId>
+  <artifactId>parent</artifactId>
+  <version>1.0.0</version>
+  <packaging>pom</packaging>
+  <modules>
+    <module>core</module>
+    <module>web/pom.xml</module>
+  </modules>
+  <properties>
+    <jackson.version>2.16.0</jackson.version>
+  </properties>
+  <dependencyManagement>
+    <dependencies>
+      <dependency>
+        <groupId>com.fasterxml.jackson.core</groupId>
+        <artifactId>jackson-databind</artifactId>
+        <version>${jackson.version}</version>
+      </dependency>
+      <dependency>
+        <groupId>org.springframework.boot</groupId>
+        <artifactId>spring-boot-dependencies</artifactId>
+        <version>3.2.0</version>
+        <type>pom</type>
+        <scope>import</scope>
+      </dependency>
+    </dependencies>
+  </dependencyManagement>
+  <dependencies>
+    <dependency>
+      <groupId>org.junit.jupiter</groupId>
+      <artifactId>junit-jupiter</artifactId>
+      <version>5.10.1</version>
+      <scope>test</scope>
+    </dependency>
+  </dependencies>
+</project>`
+	core := `<project>
+  <parent>
+    <groupId>com.example</groupId>
+    <artifactId>parent</artifactId>
+    <version>1.0.0</version>
+  </parent>
+  <artifactId>core</artifactId>
+  <dependencies>
+    <dependency>
+      <groupId>com.fasterxml.jackson.core</groupId>
+      <artifactId>jackson-databind</artifactId>
+    </dependency>
+    <dependency>
+      <groupId>jakarta.servlet</groupId>
+      <artifactId>jakarta.servlet-api</artifactId>
+      <version>[6.0,7.0)</version>
+      <scope>provided</scope>
+    </dependency>
+    <dependency>
+      <groupId>com.google.guava</groupId>
+      <artifactId>guava</artifactId>
+      <version>33.0-jre</version>
+      <optional>true</optional>
+    </dependency>
+    <dependency>
+      <groupId>${project.groupId}</groupId>
+      <artifactId>shared</artifactId>
+      <version>${project.version}</version>
+    </dependency>
+  </dependencies>
+</project>`
+	web := `<project>
+  <parent>
+    <groupId>com.example</groupId>
+    <artifactId>parent</artifactId>
+    <version>1.0.0</version>
+    <relativePath>../pom.xml</relativePath>
+  </parent>
+  <artifactId>web</artifactId>
+  <properties>
+    <jackson.version>2.17.0</jackson.version>
+  </properties>
+  <dependencies>
+    <dependency>
+      <groupId>org.springframework.boot</groupId>
+      <artifactId>spring-boot-starter-web</artifactId>
+    </dependency>
+    <dependency>
+      <groupId>com.fasterxml.jackson.core</groupId>
+      <artifactId>jackson-core</artifactId>
+      <version>${jackson.version}</version>
+    </dependency>
+  </dependencies>
+</project>`
+
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"pom.xml":      parent,
+		"core/pom.xml": core,
+		"web/pom.xml":  web,
+	})
+	if !(mavenParser{}).Detect(root) {
+		t.Fatal("Detect() = false")
+	}
+	deps, err := mavenParser{}.Parse(root, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"org.junit.jupiter:junit-jupiter@5.10.1 dev com.example:parent pom.xml",
+		"com.fasterxml.jackson.core:jackson-databind@2.16.0 direct com.example:core core/pom.xml",
+		"jakarta.servlet:jakarta.servlet-api@[6.0,7.0) peer com.example:core core/pom.xml",
+		"com.google.guava:guava@33.0-jre optional com.example:core core/pom.xml",
+		"com.example:shared@1.0.0 direct com.example:core core/pom.xml",
+		"org.springframework.boot:spring-boot-starter-web@managed direct com.example:web web/pom.xml",
+		"com.fasterxml.jackson.core:jackson-core@2.17.0 direct com.example:web web/pom.xml",
+	}
+	if got := depStrings(root, deps); !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestMavenScopeType(t *testing.T) {
+	tests := map[string]string{
+		"":         "direct",
+		"compile":  "direct",
+		"runtime":  "direct",
+		"test":     "dev",
+		"provided": "peer",
+		" test ":   "dev",
+	}
+	for scope, want := range tests {
+		if got := mavenScopeType(scope); got != want {
+			t.Errorf("mavenScopeType(%q) = %q, want %q", scope, got, want)
+		}
+	}
+}
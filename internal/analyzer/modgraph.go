@@ -0,0 +1,389 @@
+package analyzer
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+const (
+	defaultGraphDepth = 3
+	maxGraphLines     = 500
+)
+
+// ModuleGraph is the module requirement graph of a main module, built offline
+// from go.mod files in the module cache (like `go mod graph`)
+type ModuleGraph struct {
+	Root     string                 // main module path
+	Nodes    map[string]*ModuleNode // keyed by path@version; the root is keyed by its path
+	Selected map[string]string      // module path -> version chosen by minimal version selection
+	NotInSum []string               // reachable module versions missing from go.sum
+}
+
+// ModuleNode is a module version in the graph
+type ModuleNode struct {
+	Key      string
+	Path     string
+	Version  string
+	Requires []string // keys of required nodes
+	Missing  bool     // go.mod not available offline; requirements unknown
+}
+
+// ModuleGraphOptions selects what ModuleGraphReport renders
+type ModuleGraphOptions struct {
+	Format string // "tree", "dot" or empty for a summary only
+	Why    string // module path to explain
+	Depth  int    // tree depth; 0 uses the default
+}
+
+// ModuleGraphReport renders the module graph of every project module as markdown
+func (a *ProjectAnalyzer) ModuleGraphReport(opts ModuleGraphOptions) (string, error) {
+	modules := a.GoModules()
+	if len(modules) == 0 {
+		return "", fmt.Errorf("no Go module found in project paths")
+	}
+	if opts.Depth <= 0 {
+		opts.Depth = defaultGraphDepth
+	}
+
+	var result strings.Builder
+	for _, mod := range modules {
+		graph, err := BuildModuleGraph(mod.Dir)
+		if err != nil {
+			result.WriteString(fmt.Sprintf("## 🕸️ Module Graph `%s`\n\nUnavailable: %v\n\n", mod.Path, err))
+			continue
+		}
+		graph.writeReport(&result, opts)
+	}
+
+	return result.String(), nil
+}
+
+// BuildModuleGraph builds the requirement graph of the module in moduleDir.
+// The main module's replace and exclude directives apply to the whole graph.
+func BuildModuleGraph(moduleDir string) (*ModuleGraph, error) {
+	mf, err := readGoModFile(filepath.Join(moduleDir, "go.mod"))
+	if err != nil {
+		return nil, err
+	}
+
+	g := &ModuleGraph{
+		Root:     mf.Module,
+		Nodes:    make(map[string]*ModuleNode),
+		Selected: make(map[string]string),
+	}
+
+	excluded := make(map[string]bool)
+	for _, ex := range mf.Excludes {
+		excluded[ex.Path+"@"+ex.Version] = true
+	}
+
+	sums := readGoSum(filepath.Join(moduleDir, "go.sum"))
+	modCache := moduleCacheDir()
+
+	// With graph pruning (go 1.17+), requirements of a go 1.17+ dependency are
+	// in the graph but their own go.mod files are not loaded
+	pruning := semver.Compare("v"+mf.GoVersion, "v1.17") >= 0
+
+	root := &ModuleNode{Key: mf.Module, Path: mf.Module}
+	g.Nodes[root.Key] = root
+
+	var queue []*ModuleNode
+	expanded := make(map[string]bool)
+	link := func(from *ModuleNode, path, version string, expand bool) {
+		key := path + "@" + version
+		if excluded[key] {
+			return
+		}
+		from.Requires = append(from.Requires, key)
+
+		node, exists := g.Nodes[key]
+		if !exists {
+			node = &ModuleNode{Key: key, Path: path, Version: version}
+			g.Nodes[key] = node
+		}
+		if expand && !expanded[key] {
+			expanded[key] = true
+			queue = append(queue, node)
+		}
+	}
+
+	for _, req := range mf.Requires {
+		link(root, req.Name, req.Version, true)
+	}
+
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+
+		if sums != nil && !sums[node.Key] {
+			g.NotInSum = append(g.NotInSum, node.Key)
+		}
+
+		reqs, goVersion, ok := moduleRequirements(mf, node.Path, node.Version, modCache)
+		if !ok {
+			node.Missing = true
+			continue
+		}
+		expand := !pruning || semver.Compare("v"+goVersion, "v1.17") < 0
+		for _, req := range reqs {
+			link(node, req.Path, req.Version, expand)
+		}
+	}
+
+	// Minimal version selection: the highest required version of each module wins
+	for _, node := range g.Nodes {
+		if node == root {
+			continue
+		}
+		if current, exists := g.Selected[node.Path]; !exists || semver.Compare(node.Version, current) > 0 {
+			g.Selected[node.Path] = node.Version
+		}
+	}
+
+	for _, node := range g.Nodes {
+		sort.Strings(node.Requires)
+	}
+	sort.Strings(g.NotInSum)
+
+	return g, nil
+}
+
+// moduleRequirements reads the requirements and go version of a module
+// version from its go.mod, following the main module's replace directives
+func moduleRequirements(main *GoModFile, path, version, modCache string) ([]ModuleVersion, string, bool) {
+	goModPath := ""
+	if r := main.Replacement(path, version); r != nil {
+		if r.Local {
+			goModPath = filepath.Join(r.Dir, "go.mod")
+		} else {
+			path, version = r.New.Path, r.New.Version
+		}
+	}
+
+	if goModPath == "" {
+		if modCache == "" {
+			return nil, "", false
+		}
+		escPath, err := module.EscapePath(path)
+		if err != nil {
+			return nil, "", false
+		}
+		escVersion, err := module.EscapeVersion(version)
+		if err != nil {
+			return nil, "", false
+		}
+
+		// The download cache keeps go.mod files even for modules never extracted
+		goModPath = filepath.Join(modCache, "cache", "download", escPath, "@v", escVersion+".mod")
+		if _, err := os.Stat(goModPath); err != nil {
+			goModPath = filepath.Join(modCache, escPath+"@"+escVersion, "go.mod")
+		}
+	}
+
+	data, err := os.ReadFile(goModPath)
+	if err != nil {
+		return nil, "", false
+	}
+
+	f, err := modfile.ParseLax(goModPath, data, nil)
+	if err != nil {
+		return nil, "", false
+	}
+
+	goVersion := ""
+	if f.Go != nil {
+		goVersion = f.Go.Version
+	}
+
+	var reqs []ModuleVersion
+	for _, req := range f.Require {
+		reqs = append(reqs, ModuleVersion{Path: req.Mod.Path, Version: req.Mod.Version})
+	}
+	return reqs, goVersion, true
+}
+
+// readGoSum returns the module versions whose go.mod is recorded in go.sum, or
+// nil when there is no go.sum
+func readGoSum(path string) map[string]bool {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	sums := make(map[string]bool)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 {
+			continue
+		}
+		version := strings.TrimSuffix(fields[1], "/go.mod")
+		sums[fields[0]+"@"+version] = true
+	}
+	return sums
+}
+
+// Why returns the shortest requirement path from the main module to any
+// version of the module path, or nil if it is not in the graph
+func (g *ModuleGraph) Why(path string) []string {
+	parent := map[string]string{g.Root: ""}
+	queue := []string{g.Root}
+
+	for len(queue) > 0 {
+		key := queue[0]
+		queue = queue[1:]
+
+		if node := g.Nodes[key]; node != nil && node.Path == path && key != g.Root {
+			var chain []string
+			for k := key; k != ""; k = parent[k] {
+				chain = append([]string{k}, chain...)
+			}
+			return chain
+		}
+
+		for _, next := range g.Nodes[key].Requires {
+			if _, seen := parent[next]; !seen {
+				parent[next] = key
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Duplicates returns module paths required at more than one version, each
+// with its versions in ascending order
+func (g *ModuleGraph) Duplicates() map[string][]string {
+	versions := make(map[string][]string)
+	for _, node := range g.Nodes {
+		if node.Key != g.Root {
+			versions[node.Path] = append(versions[node.Path], node.Version)
+		}
+	}
+
+	dups := make(map[string][]string)
+	for path, list := range versions {
+		if len(list) > 1 {
+			semver.Sort(list)
+			dups[path] = list
+		}
+	}
+	return dups
+}
+
+func (g *ModuleGraph) writeReport(result *strings.Builder, opts ModuleGraphOptions) {
+	result.WriteString(fmt.Sprintf("## 🕸️ Module Graph `%s`\n\n", g.Root))
+
+	missing := 0
+	for _, node := range g.Nodes {
+		if node.Missing {
+			missing++
+		}
+	}
+	result.WriteString(fmt.Sprintf("- **Modules**: %d (%d module versions)\n", len(g.Selected), len(g.Nodes)-1))
+	if missing > 0 {
+		result.WriteString(fmt.Sprintf("- **Incomplete**: %d go.mod files not available offline\n", missing))
+	}
+	if len(g.NotInSum) > 0 {
+		result.WriteString(fmt.Sprintf("- **Not in go.sum**: %d (run `go mod tidy`)\n", len(g.NotInSum)))
+	}
+	result.WriteString("\n")
+
+	if opts.Why != "" {
+		result.WriteString(fmt.Sprintf("### Why `%s`?\n\n", opts.Why))
+		if chain := g.Why(opts.Why); chain != nil {
+			result.WriteString("`" + strings.Join(chain, "` → `") + "`\n\n")
+		} else {
+			result.WriteString("Not in the module graph.\n\n")
+		}
+	}
+
+	dups := g.Duplicates()
+	if len(dups) > 0 {
+		paths := make([]string, 0, len(dups))
+		for path := range dups {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+
+		result.WriteString(fmt.Sprintf("### Duplicate Versions (%d)\n\n", len(dups)))
+		for _, path := range paths {
+			result.WriteString(fmt.Sprintf("- `%s`: %s (selected `%s`)\n", path, strings.Join(dups[path], ", "), g.Selected[path]))
+		}
+		result.WriteString("\n")
+	}
+
+	switch opts.Format {
+	case "tree":
+		result.WriteString("### Tree\n\n```\n")
+		lines := 0
+		g.writeTree(result, g.Root, 0, opts.Depth, make(map[string]bool), &lines)
+		result.WriteString("```\n\n")
+	case "dot":
+		result.WriteString("### DOT\n\n```dot\n")
+		g.writeDOT(result)
+		result.WriteString("```\n\n")
+	}
+}
+
+// writeTree prints requirements depth-first; subtrees already shown are marked (*)
+func (g *ModuleGraph) writeTree(result *strings.Builder, key string, level, depth int, expanded map[string]bool, lines *int) {
+	if *lines >= maxGraphLines {
+		if *lines == maxGraphLines {
+			result.WriteString("... (truncated)\n")
+			*lines++
+		}
+		return
+	}
+
+	node := g.Nodes[key]
+	line := strings.Repeat("  ", level) + key
+	if key != g.Root && g.Selected[node.Path] != node.Version {
+		line += fmt.Sprintf(" → %s", g.Selected[node.Path])
+	}
+	if node.Missing {
+		line += " (go.mod unavailable)"
+	}
+	if expanded[key] && len(node.Requires) > 0 {
+		result.WriteString(line + " (*)\n")
+		*lines++
+		return
+	}
+	result.WriteString(line + "\n")
+	*lines++
+
+	if level >= depth {
+		return
+	}
+	expanded[key] = true
+	for _, next := range node.Requires {
+		g.writeTree(result, next, level+1, depth, expanded, lines)
+	}
+}
+
+func (g *ModuleGraph) writeDOT(result *strings.Builder) {
+	keys := make([]string, 0, len(g.Nodes))
+	for key := range g.Nodes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	result.WriteString("digraph modules {\n")
+	result.WriteString(fmt.Sprintf("  %q [shape=box];\n", g.Root))
+	for _, key := range keys {
+		for _, next := range g.Nodes[key].Requires {
+			result.WriteString(fmt.Sprintf("  %q -> %q;\n", key, next))
+		}
+	}
+	result.WriteString("}\n")
+}
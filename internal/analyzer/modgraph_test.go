@@ -0,0 +1,194 @@
+package analyzer
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeModCache writes go.mod files into the download cache of a temporary
+// GOMODCACHE, keyed by path@version
+func writeModCache(t *testing.T, mods map[string]string) {
+	t.Helper()
+	cache := t.TempDir()
+	t.Setenv("GOMODCACHE", cache)
+
+	files := make(map[string]string)
+	for key, content := range mods {
+		path, version, _ := strings.Cut(key, "@")
+		files[filepath.Join("cache", "download", escapeModulePath(path), "@v", version+".mod")] = content
+	}
+	writeFiles(t, cache, files)
+}
+
+func TestBuildModuleGraph(t *testing.T) {
+	writeModCache(t, map[string]string{
+		"example.com/b@v1.0.0":     "module example.com/b\n\ngo 1.16\n\nrequire (\n\texample.com/c v1.2.0\n\texample.com/d v1.0.0\n)\n",
+		"example.com/c@v1.1.0":     "module example.com/c\n\ngo 1.16\n",
+		"example.com/c@v1.2.0":     "module example.com/c\n\ngo 1.16\n\nrequire (\n\texample.com/Upper v1.0.0\n\texample.com/x v1.0.0\n)\n",
+		"example.com/Upper@v1.0.0": "module example.com/Upper\n\ngo 1.16\n",
+		"example.com/x@v1.0.0":     "module example.com/x\n\ngo 1.16\n",
+	})
+
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"app/go.mod": `module example.com/app
+
+go 1.16
+
+require (
+	example.com/a v1.0.0
+	example.com/b v1.0.0
+)
+
+replace example.com/a => ../a
+
+exclude example.com/x v1.0.0
+`,
+		"app/go.sum": "example.com/b v1.0.0/go.mod h1:x=\nexample.com/c v1.2.0/go.mod h1:x=\nexample.com/c v1.2.0 h1:x=\n",
+		"a/go.mod":   "module example.com/a\n\ngo 1.16\n\nrequire example.com/c v1.1.0\n",
+	})
+
+	g, err := BuildModuleGraph(filepath.Join(root, "app"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The excluded module is dropped and d has no go.mod in the cache
+	if want := map[string]string{
+		"example.com/a":     "v1.0.0",
+		"example.com/b":     "v1.0.0",
+		"example.com/c":     "v1.2.0",
+		"example.com/d":     "v1.0.0",
+		"example.com/Upper": "v1.0.0",
+	}; !reflect.DeepEqual(g.Selected, want) {
+		t.Errorf("Selected = %v", g.Selected)
+	}
+	if got := g.Nodes["example.com/a@v1.0.0"].Requires; !reflect.DeepEqual(got, []string{"example.com/c@v1.1.0"}) {
+		t.Errorf("replaced a requires %q", got)
+	}
+	if got := g.Nodes["example.com/c@v1.2.0"].Requires; !reflect.DeepEqual(got, []string{"example.com/Upper@v1.0.0"}) {
+		t.Errorf("c@v1.2.0 requires %q", got)
+	}
+	if !g.Nodes["example.com/d@v1.0.0"].Missing || g.Nodes["example.com/b@v1.0.0"].Missing {
+		t.Error("only d should be missing its go.mod")
+	}
+	if want := []string{"example.com/Upper@v1.0.0", "example.com/a@v1.0.0", "example.com/c@v1.1.0", "example.com/d@v1.0.0"}; !reflect.DeepEqual(g.NotInSum, want) {
+		t.Errorf("NotInSum = %q", g.NotInSum)
+	}
+	if want := map[string][]string{"example.com/c": {"v1.1.0", "v1.2.0"}}; !reflect.DeepEqual(g.Duplicates(), want) {
+		t.Errorf("Duplicates = %v", g.Duplicates())
+	}
+
+	whyTests := []struct {
+		path string
+		want []string
+	}{
+		{"example.com/b", []string{"example.com/app", "example.com/b@v1.0.0"}},
+		// The shortest path wins over the one reaching the selected version
+		{"example.com/c", []string{"example.com/app", "example.com/a@v1.0.0", "example.com/c@v1.1.0"}},
+		{"example.com/Upper", []string{"example.com/app", "example.com/b@v1.0.0", "example.com/c@v1.2.0", "example.com/Upper@v1.0.0"}},
+		{"example.com/x", nil},
+		{"example.com/app", nil},
+	}
+	for _, tt := range whyTests {
+		if got := g.Why(tt.path); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Why(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestBuildModuleGraphPruning(t *testing.T) {
+	writeModCache(t, map[string]string{
+		"example.com/new@v1.0.0":  "module example.com/new\n\ngo 1.21\n\nrequire example.com/c v1.1.0\n",
+		"example.com/old@v1.0.0":  "module example.com/old\n\ngo 1.16\n\nrequire example.com/c v1.0.0\n",
+		"example.com/c@v1.0.0":    "module example.com/c\n\ngo 1.16\n\nrequire example.com/deep v1.0.0\n",
+		"example.com/c@v1.1.0":    "module example.com/c\n\ngo 1.16\n\nrequire example.com/deep v1.1.0\n",
+		"example.com/deep@v1.0.0": "module example.com/deep\n\ngo 1.16\n",
+		"example.com/deep@v1.1.0": "module example.com/deep\n\ngo 1.16\n",
+	})
+
+	tests := []struct {
+		goVersion string
+		want      map[string]string
+	}{
+		// Unpruned: every go.mod in the graph is loaded
+		{"1.16", map[string]string{
+			"example.com/new":  "v1.0.0",
+			"example.com/old":  "v1.0.0",
+			"example.com/c":    "v1.1.0",
+			"example.com/deep": "v1.1.0",
+		}},
+		// Pruned: requirements of the go 1.21 module are not expanded, those of
+		// the go 1.16 one still are
+		{"1.21", map[string]string{
+			"example.com/new":  "v1.0.0",
+			"example.com/old":  "v1.0.0",
+			"example.com/c":    "v1.1.0",
+			"example.com/deep": "v1.0.0",
+		}},
+	}
+	for _, tt := range tests {
+		root := t.TempDir()
+		writeFiles(t, root, map[string]string{
+			"go.mod": "module example.com/app\n\ngo " + tt.goVersion + "\n\nrequire (\n\texample.com/new v1.0.0\n\texample.com/old v1.0.0\n)\n",
+		})
+
+		g, err := BuildModuleGraph(root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(g.Selected, tt.want) {
+			t.Errorf("go %s: Selected = %v, want %v", tt.goVersion, g.Selected, tt.want)
+		}
+		if g.NotInSum != nil {
+			t.Errorf("go %s: NotInSum = %q without a go.sum", tt.goVersion, g.NotInSum)
+		}
+	}
+}
+
+func TestModuleGraphReport(t *testing.T) {
+	writeModCache(t, map[string]string{
+		"example.com/a@v1.0.0": "module example.com/a\n\ngo 1.16\n\nrequire example.com/c v1.1.0\n",
+		"example.com/b@v1.0.0": "module example.com/b\n\ngo 1.16\n\nrequire example.com/c v1.2.0\n",
+		"example.com/c@v1.1.0": "module example.com/c\n\ngo 1.16\n",
+		"example.com/c@v1.2.0": "module example.com/c\n\ngo 1.16\n",
+	})
+
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.mod": "module example.com/app\n\ngo 1.16\n\nrequire (\n\texample.com/a v1.0.0\n\texample.com/b v1.0.0\n\texample.com/gone v1.0.0\n)\n",
+	})
+	a := newTestAnalyzer(t, root)
+
+	report, err := a.ModuleGraphReport(ModuleGraphOptions{Format: "tree", Why: "example.com/c"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"- **Modules**: 4 (5 module versions)\n",
+		"- **Incomplete**: 1 go.mod files not available offline\n",
+		"`example.com/app` → `example.com/a@v1.0.0` → `example.com/c@v1.1.0`\n",
+		"- `example.com/c`: v1.1.0, v1.2.0 (selected `v1.2.0`)\n",
+		"example.com/app\n  example.com/a@v1.0.0\n    example.com/c@v1.1.0 → v1.2.0\n  example.com/b@v1.0.0\n    example.com/c@v1.2.0\n  example.com/gone@v1.0.0 (go.mod unavailable)\n",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report missing %q:\n%s", want, report)
+		}
+	}
+
+	report, err = a.ModuleGraphReport(ModuleGraphOptions{Format: "dot", Why: "example.com/none"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Not in the module graph.", `"example.com/b@v1.0.0" -> "example.com/c@v1.2.0";`} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report missing %q:\n%s", want, report)
+		}
+	}
+
+	if _, err := newTestAnalyzer(t, t.TempDir()).ModuleGraphReport(ModuleGraphOptions{}); err == nil {
+		t.Error("expected an error without a Go module")
+	}
+}
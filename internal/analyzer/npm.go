@@ -0,0 +1,502 @@
+package analyzer
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"golang.org/x/mod/semver"
+	"gopkg.in/yaml.v3"
+)
+
+// packageJSON is the subset of package.json used for dependency analysis
+type packageJSON struct {
+	Name                 string            `json:"name"`
+	Version              string            `json:"version"`
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	Workspaces           json.RawMessage   `json:"workspaces"`
+}
+
+// npmPackage is a package.json of the project
+type npmPackage struct {
+	Dir      string
+	RelDir   string // slash path relative to the workspace root, "" for the root
+	Manifest packageJSON
+}
+
+// npmLock maps declared dependencies to locked versions. Direct lookups use
+// the importing package directory and the declared range; all lists every
+// locked name@version.
+type npmLock struct {
+	file    string
+	resolve func(relDir, name, constraint string) string
+	all     []ModuleVersion
+}
+
+func init() {
+	mustRegisterDependencyParser(npmParser{})
+}
+
+// npmParser reads package.json files, including npm, yarn and pnpm
+// workspaces, resolving versions from the lockfile
+type npmParser struct{}
+
+func (npmParser) Ecosystem() string { return "npm" }
+
+func (npmParser) Detect(root string) bool {
+	_, err := os.Stat(filepath.Join(root, "package.json"))
+	return err == nil
+}
+
+func (npmParser) Parse(root string, includeTransitive bool) ([]Dependency, error) {
+	rootManifest, err := readPackageJSON(filepath.Join(root, "package.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	packages := []npmPackage{{Dir: root, Manifest: *rootManifest}}
+	for _, dir := range npmWorkspaceDirs(root, rootManifest) {
+		manifest, err := readPackageJSON(filepath.Join(dir, "package.json"))
+		if err != nil {
+			continue
+		}
+		rel, _ := filepath.Rel(root, dir)
+		packages = append(packages, npmPackage{Dir: dir, RelDir: filepath.ToSlash(rel), Manifest: *manifest})
+	}
+
+	lock := readNPMLock(root)
+
+	var deps []Dependency
+	for _, pkg := range packages {
+		module := pkg.Manifest.Name
+		if module == "" {
+			module = path.Join(filepath.Base(root), pkg.RelDir)
+		}
+		manifestPath := filepath.Join(pkg.Dir, "package.json")
+
+		// Later sections override earlier ones, as npm does for duplicates
+		declared := make(map[string]Dependency)
+		sections := []struct {
+			deps    map[string]string
+			depType string
+		}{
+			{pkg.Manifest.PeerDependencies, "peer"},
+			{pkg.Manifest.DevDependencies, "dev"},
+			{pkg.Manifest.OptionalDependencies, "optional"},
+			{pkg.Manifest.Dependencies, "direct"},
+		}
+		for _, section := range sections {
+			for name, constraint := range section.deps {
+				dep := Dependency{
+					Name:       name,
+					Version:    constraint,
+					Type:       section.depType,
+					Path:       manifestPath,
+					Module:     module,
+					Ecosystem:  "npm",
+					Constraint: constraint,
+				}
+				if lock != nil {
+					if version := lock.resolve(pkg.RelDir, name, constraint); version != "" {
+						dep.Version = version
+					}
+				}
+				if dep.Version == dep.Constraint {
+					dep.Constraint = ""
+				}
+				declared[name] = dep
+			}
+		}
+
+		names := make([]string, 0, len(declared))
+		for name := range declared {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			deps = append(deps, declared[name])
+		}
+	}
+
+	// Everything else in the lockfile is transitive; it belongs to the root package
+	if includeTransitive && lock != nil {
+		direct := make(map[string]bool)
+		for _, dep := range deps {
+			direct[dep.Name+"@"+dep.Version] = true
+		}
+		for _, pkg := range packages {
+			direct[pkg.Manifest.Name] = true
+		}
+
+		module := rootManifest.Name
+		if module == "" {
+			module = filepath.Base(root)
+		}
+		for _, mv := range lock.all {
+			if direct[mv.Path+"@"+mv.Version] || direct[mv.Path] {
+				continue
+			}
+			deps = append(deps, Dependency{
+				Name:      mv.Path,
+				Version:   mv.Version,
+				Type:      "indirect",
+				Path:      lock.file,
+				Module:    module,
+				Ecosystem: "npm",
+			})
+		}
+	}
+
+	return deps, nil
+}
+
+func readPackageJSON(path string) (*packageJSON, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var manifest packageJSON
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, err
+	}
+	return &manifest, nil
+}
+
+// npmWorkspaceDirs expands the workspace globs of package.json (npm, yarn) or
+// pnpm-workspace.yaml into package directories
+func npmWorkspaceDirs(root string, manifest *packageJSON) []string {
+	var patterns []string
+
+	if len(manifest.Workspaces) > 0 {
+		var list []string
+		var object struct {
+			Packages []string `json:"packages"`
+		}
+		if err := json.Unmarshal(manifest.Workspaces, &list); err == nil {
+			patterns = list
+		} else if err := json.Unmarshal(manifest.Workspaces, &object); err == nil {
+			patterns = object.Packages
+		}
+	}
+
+	if data, err := os.ReadFile(filepath.Join(root, "pnpm-workspace.yaml")); err == nil {
+		var ws struct {
+			Packages []string `yaml:"packages"`
+		}
+		if yaml.Unmarshal(data, &ws) == nil {
+			patterns = append(patterns, ws.Packages...)
+		}
+	}
+
+	var include, exclude []string
+	for _, pattern := range patterns {
+		pattern = strings.TrimPrefix(strings.TrimSuffix(pattern, "/"), "./")
+		if strings.HasPrefix(pattern, "!") {
+			exclude = append(exclude, strings.TrimPrefix(pattern[1:], "./"))
+		} else {
+			include = append(include, pattern)
+		}
+	}
+
+	seen := make(map[string]bool)
+	var dirs []string
+	fsys := os.DirFS(root)
+	for _, pattern := range include {
+		matches, _ := doublestar.Glob(fsys, pattern+"/package.json")
+		for _, match := range matches {
+			rel := path.Dir(match)
+			if rel == "." || seen[rel] || strings.Contains(rel, "node_modules") {
+				continue
+			}
+			excluded := false
+			for _, ex := range exclude {
+				if ok, _ := doublestar.Match(ex, rel); ok {
+					excluded = true
+					break
+				}
+			}
+			if !excluded {
+				seen[rel] = true
+				dirs = append(dirs, filepath.Join(root, filepath.FromSlash(rel)))
+			}
+		}
+	}
+
+	sort.Strings(dirs)
+	return dirs
+}
+
+// readNPMLock reads the first lockfile found in root
+func readNPMLock(root string) *npmLock {
+	readers := []struct {
+		name string
+		read func(string) *npmLock
+	}{
+		{"package-lock.json", readPackageLock},
+		{"npm-shrinkwrap.json", readPackageLock},
+		{"pnpm-lock.yaml", readPNPMLock},
+		{"yarn.lock", readYarnLock},
+	}
+
+	for _, reader := range readers {
+		file := filepath.Join(root, reader.name)
+		if _, err := os.Stat(file); err != nil {
+			continue
+		}
+		if lock := reader.read(file); lock != nil {
+			lock.file = file
+			return lock
+		}
+	}
+	return nil
+}
+
+// readPackageLock parses package-lock.json v1 (nested dependencies) and v2/v3
+// (flat "packages" keyed by node_modules path)
+func readPackageLock(file string) *npmLock {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil
+	}
+
+	type lockEntry struct {
+		Version      string                `json:"version"`
+		Link         bool                  `json:"link"`
+		Dependencies map[string]*lockEntry `json:"dependencies"`
+	}
+	var parsed struct {
+		Packages     map[string]*lockEntry `json:"packages"`
+		Dependencies map[string]*lockEntry `json:"dependencies"`
+	}
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return nil
+	}
+
+	versions := make(map[string]string) // node_modules path -> version
+	if len(parsed.Packages) > 0 {
+		for key, entry := range parsed.Packages {
+			if strings.Contains(key, "node_modules/") && !entry.Link {
+				versions[key] = entry.Version
+			}
+		}
+	} else {
+		var walk func(prefix string, deps map[string]*lockEntry)
+		walk = func(prefix string, deps map[string]*lockEntry) {
+			for name, entry := range deps {
+				key := prefix + "node_modules/" + name
+				versions[key] = entry.Version
+				walk(key+"/", entry.Dependencies)
+			}
+		}
+		walk("", parsed.Dependencies)
+	}
+
+	lock := &npmLock{
+		resolve: func(relDir, name, _ string) string {
+			if relDir != "" {
+				if version, ok := versions[relDir+"/node_modules/"+name]; ok {
+					return version
+				}
+			}
+			return versions["node_modules/"+name]
+		},
+	}
+
+	seen := make(map[string]bool)
+	for key, version := range versions {
+		name := key[strings.LastIndex(key, "node_modules/")+len("node_modules/"):]
+		if version == "" || seen[name+"@"+version] {
+			continue
+		}
+		seen[name+"@"+version] = true
+		lock.all = append(lock.all, ModuleVersion{Path: name, Version: version})
+	}
+	sortModuleVersions(lock.all)
+
+	return lock
+}
+
+// readYarnLock parses yarn.lock, both the classic v1 format and the YAML-like
+// berry format
+func readYarnLock(file string) *npmLock {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	resolved := make(map[string]string) // name@range -> version
+	var current []string
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		if !strings.HasPrefix(line, " ") {
+			current = nil
+			if !strings.HasSuffix(trimmed, ":") || strings.HasPrefix(trimmed, "__metadata") {
+				continue
+			}
+			for _, spec := range strings.Split(strings.TrimSuffix(trimmed, ":"), ",") {
+				current = append(current, strings.Trim(strings.TrimSpace(spec), `"`))
+			}
+			continue
+		}
+
+		if current != nil && (strings.HasPrefix(trimmed, "version ") || strings.HasPrefix(trimmed, "version:")) {
+			version := strings.Trim(strings.TrimSpace(strings.TrimLeft(strings.TrimPrefix(trimmed, "version"), ": ")), `"`)
+			for _, spec := range current {
+				resolved[spec] = version
+			}
+			current = nil
+		}
+	}
+
+	lock := &npmLock{
+		resolve: func(_, name, constraint string) string {
+			if version, ok := resolved[name+"@"+constraint]; ok {
+				return version
+			}
+			return resolved[name+"@npm:"+constraint]
+		},
+	}
+
+	seen := make(map[string]bool)
+	for spec, version := range resolved {
+		name := npmSpecName(spec)
+		if seen[name+"@"+version] {
+			continue
+		}
+		seen[name+"@"+version] = true
+		lock.all = append(lock.all, ModuleVersion{Path: name, Version: version})
+	}
+	sortModuleVersions(lock.all)
+
+	return lock
+}
+
+// readPNPMLock parses pnpm-lock.yaml (lockfile versions 5 to 9)
+func readPNPMLock(file string) *npmLock {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil
+	}
+
+	type importer struct {
+		Dependencies         map[string]interface{} `yaml:"dependencies"`
+		DevDependencies      map[string]interface{} `yaml:"devDependencies"`
+		OptionalDependencies map[string]interface{} `yaml:"optionalDependencies"`
+	}
+	var parsed struct {
+		importer  `yaml:",inline"`
+		Importers map[string]importer    `yaml:"importers"`
+		Packages  map[string]interface{} `yaml:"packages"`
+	}
+	if err := yaml.Unmarshal(data, &parsed); err != nil {
+		return nil
+	}
+
+	// Single-package lockfiles keep the root importer at the top level
+	if parsed.Importers == nil {
+		parsed.Importers = map[string]importer{".": parsed.importer}
+	}
+
+	direct := make(map[string]string) // relDir/name -> version
+	for dir, imp := range parsed.Importers {
+		if dir == "." {
+			dir = ""
+		}
+		for _, section := range []map[string]interface{}{imp.Dependencies, imp.DevDependencies, imp.OptionalDependencies} {
+			for name, value := range section {
+				version := ""
+				switch v := value.(type) {
+				case string:
+					version = v
+				case map[string]interface{}:
+					version, _ = v["version"].(string)
+				}
+				direct[dir+"/"+name] = pnpmVersion(version)
+			}
+		}
+	}
+
+	lock := &npmLock{
+		resolve: func(relDir, name, _ string) string {
+			return direct[relDir+"/"+name]
+		},
+	}
+
+	seen := make(map[string]bool)
+	for key := range parsed.Packages {
+		name, version := pnpmPackageKey(key)
+		if name == "" || seen[name+"@"+version] {
+			continue
+		}
+		seen[name+"@"+version] = true
+		lock.all = append(lock.all, ModuleVersion{Path: name, Version: version})
+	}
+	sortModuleVersions(lock.all)
+
+	return lock
+}
+
+// pnpmVersion strips peer dependency suffixes: 1.0.0(react@18.2.0) or 1.0.0_react@18.2.0
+func pnpmVersion(version string) string {
+	if i := strings.IndexAny(version, "(_"); i > 0 {
+		version = version[:i]
+	}
+	return version
+}
+
+// pnpmPackageKey splits a packages key: /name/1.0.0 (v5), /name@1.0.0 (v6) or name@1.0.0 (v9)
+func pnpmPackageKey(key string) (string, string) {
+	key = strings.TrimPrefix(key, "/")
+	if i := strings.Index(key, "("); i > 0 {
+		key = key[:i]
+	}
+	// v5 keys end with a version segment, possibly followed by _peer@version;
+	// a scoped v6 name may also start with a digit (/@scope/3d-lib@1.0.0)
+	if i := strings.LastIndex(key, "/"); i > 0 {
+		if version := pnpmVersion(key[i+1:]); semver.IsValid(canonicalSemver(version)) {
+			return key[:i], version
+		}
+	}
+	if i := strings.LastIndex(key, "@"); i > 0 {
+		return key[:i], pnpmVersion(key[i+1:])
+	}
+	return "", ""
+}
+
+// npmSpecName returns the package name of a name@range spec, keeping the
+// scope. The range may name another package (alias@npm:name@range).
+func npmSpecName(spec string) string {
+	start := 0
+	if strings.HasPrefix(spec, "@") {
+		start = strings.Index(spec, "/") + 1
+	}
+	if i := strings.Index(spec[start:], "@"); i > 0 {
+		return spec[:start+i]
+	}
+	return spec
+}
+
+func sortModuleVersions(list []ModuleVersion) {
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Path != list[j].Path {
+			return list[i].Path < list[j].Path
+		}
+		return list[i].Version < list[j].Version
+	})
+}
@@ -0,0 +1,230 @@
+package analyzer
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNPMParser(t *testing.T) {
+	manifest := `{
+  "name": "app",
+  "workspaces": ["packages/*", "!packages/skip"],
+  "dependencies": {"left-pad": "^1.3.0", "react": "^18.2.0"},
+  "devDependencies": {"jest": "^29.0.0", "react": "^18.0.0"},
+  "peerDependencies": {"@types/node": "*"}
+}`
+	workspace := `{"name": "@app/lib", "dependencies": {"lodash": "~4.17.0"}}`
+
+	tests := []struct {
+		name       string
+		files      map[string]string
+		transitive bool
+		want       []string
+	}{
+		{
+			name:  "no lockfile keeps declared ranges",
+			files: map[string]string{"package.json": `{"dependencies": {"left-pad": "^1.3.0"}, "optionalDependencies": {"fsevents": "2.3.3"}}`},
+			want: []string{
+				"fsevents@2.3.3 optional %ROOT% package.json",
+				"left-pad@^1.3.0 direct %ROOT% package.json",
+			},
+		},
+		{
+			name: "package-lock v3 with workspaces",
+			files: map[string]string{
+				"package.json":               manifest,
+				"packages/lib/package.json":  workspace,
+				"packages/skip/package.json": `{"name": "skip", "dependencies": {"x": "1"}}`,
+				"package-lock.json": `{"lockfileVersion": 3, "packages": {
+  "": {"name": "app"},
+  "node_modules/left-pad": {"version": "1.3.0"},
+  "node_modules/react": {"version": "18.2.0"},
+  "node_modules/loose-envify": {"version": "1.4.0"},
+  "node_modules/jest": {"version": "29.7.0"},
+  "node_modules/lodash": {"version": "4.17.21"},
+  "packages/lib/node_modules/lodash": {"version": "4.17.20"},
+  "node_modules/@app/lib": {"link": true}
+}}`,
+			},
+			transitive: true,
+			want: []string{
+				"@types/node@* peer app package.json",
+				"jest@29.7.0 dev app package.json [^29.0.0]",
+				"left-pad@1.3.0 direct app package.json [^1.3.0]",
+				"react@18.2.0 direct app package.json [^18.2.0]",
+				"lodash@4.17.20 direct @app/lib packages/lib/package.json [~4.17.0]",
+				"lodash@4.17.21 indirect app package-lock.json",
+				"loose-envify@1.4.0 indirect app package-lock.json",
+			},
+		},
+		{
+			name: "package-lock v1 nested dependencies",
+			files: map[string]string{
+				"package.json": `{"name": "app", "dependencies": {"a": "^1.0.0"}}`,
+				"package-lock.json": `{"lockfileVersion": 1, "dependencies": {
+  "a": {"version": "1.2.0", "dependencies": {"b": {"version": "2.0.0"}}}
+}}`,
+			},
+			transitive: true,
+			want: []string{
+				"a@1.2.0 direct app package.json [^1.0.0]",
+				"b@2.0.0 indirect app package-lock.json",
+			},
+		},
+		{
+			name: "yarn classic lockfile",
+			files: map[string]string{
+				"package.json": `{"name": "app", "dependencies": {"@scope/pkg": "^2.0.0", "left-pad": "^1.3.0"}}`,
+				"yarn.lock": `# yarn lockfile v1
+
+"@scope/pkg@^2.0.0", "@scope/pkg@^2.1.0":
+  version "2.1.4"
+  resolved "https://registry.yarnpkg.com/@scope/pkg/-/pkg-2.1.4.tgz"
+
+left-pad@^1.3.0:
+  version "1.3.0"
+
+tslib@^2.0.0:
+  version "2.6.2"
+`,
+			},
+			transitive: true,
+			want: []string{
+				"@scope/pkg@2.1.4 direct app package.json [^2.0.0]",
+				"left-pad@1.3.0 direct app package.json [^1.3.0]",
+				"tslib@2.6.2 indirect app yarn.lock",
+			},
+		},
+		{
+			name: "yarn berry lockfile",
+			files: map[string]string{
+				"package.json": `{"name": "app", "dependencies": {"left-pad": "^1.3.0"}}`,
+				"yarn.lock": `__metadata:
+  version: 6
+
+"left-pad@npm:^1.3.0":
+  version: 1.3.0
+  resolution: "left-pad@npm:1.3.0"
+`,
+			},
+			want: []string{"left-pad@1.3.0 direct app package.json [^1.3.0]"},
+		},
+		{
+			name: "pnpm v9 workspace lockfile",
+			files: map[string]string{
+				"package.json":              `{"name": "app", "devDependencies": {"react-dom": "^18.0.0"}}`,
+				"pnpm-workspace.yaml":       "packages:\n  - 'packages/*'\n",
+				"packages/lib/package.json": workspace,
+				"pnpm-lock.yaml": `lockfileVersion: '9.0'
+importers:
+  .:
+    devDependencies:
+      react-dom:
+        specifier: ^18.0.0
+        version: 18.2.0(react@18.2.0)
+  packages/lib:
+    dependencies:
+      lodash:
+        specifier: ~4.17.0
+        version: 4.17.21
+packages:
+  lodash@4.17.21: {}
+  react-dom@18.2.0: {}
+  react@18.2.0: {}
+`,
+			},
+			transitive: true,
+			want: []string{
+				"react-dom@18.2.0 dev app package.json [^18.0.0]",
+				"lodash@4.17.21 direct @app/lib packages/lib/package.json [~4.17.0]",
+				"react@18.2.0 indirect app pnpm-lock.yaml",
+			},
+		},
+		{
+			name: "pnpm v5 lockfile",
+			files: map[string]string{
+				"package.json": `{"name": "app", "dependencies": {"debug": "^4.0.0"}}`,
+				"pnpm-lock.yaml": `lockfileVersion: 5.4
+dependencies:
+  debug: 4.3.4_supports-color@8.1.1
+packages:
+  /debug/4.3.4_supports-color@8.1.1: {}
+  /ms/2.1.2: {}
+`,
+			},
+			transitive: true,
+			want: []string{
+				"debug@4.3.4 direct app package.json [^4.0.0]",
+				"ms@2.1.2 indirect app pnpm-lock.yaml",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeFiles(t, root, tt.files)
+			if !(npmParser{}).Detect(root) {
+				t.Fatal("Detect() = false")
+			}
+			deps, err := npmParser{}.Parse(root, tt.transitive)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := make([]string, len(tt.want))
+			for i, w := range tt.want {
+				want[i] = replaceRoot(w, filepath.Base(root))
+			}
+			if got := depStrings(root, deps); !reflect.DeepEqual(got, want) {
+				t.Errorf("Parse() =\n%q\nwant\n%q", got, want)
+			}
+		})
+	}
+}
+
+func replaceRoot(s, base string) string {
+	return strings.ReplaceAll(s, "%ROOT%", base)
+}
+
+func TestPNPMPackageKey(t *testing.T) {
+	tests := []struct {
+		key, name, version string
+	}{
+		{"/debug/4.3.4", "debug", "4.3.4"},
+		{"/debug/4.3.4_supports-color@8.1.1", "debug", "4.3.4"},
+		{"/@babel/core/7.23.5", "@babel/core", "7.23.5"},
+		{"/@babel/core/7.0.0-beta.44", "@babel/core", "7.0.0-beta.44"},
+		{"/debug@4.3.4", "debug", "4.3.4"},
+		{"/debug@4.3.4(supports-color@8.1.1)", "debug", "4.3.4"},
+		{"/@scope/3d-lib@1.0.0", "@scope/3d-lib", "1.0.0"},
+		{"/@scope/3d-lib/1.0.0", "@scope/3d-lib", "1.0.0"},
+		{"@scope/pkg@2.1.0", "@scope/pkg", "2.1.0"},
+		{"react-dom@18.2.0(react@18.2.0)", "react-dom", "18.2.0"},
+		{"/left-pad", "", ""},
+	}
+	for _, tt := range tests {
+		if name, version := pnpmPackageKey(tt.key); name != tt.name || version != tt.version {
+			t.Errorf("pnpmPackageKey(%q) = %q, %q; want %q, %q", tt.key, name, version, tt.name, tt.version)
+		}
+	}
+}
+
+func TestNPMSpecName(t *testing.T) {
+	tests := map[string]string{
+		"left-pad@^1.3.0":              "left-pad",
+		"left-pad@npm:^1.3.0":          "left-pad",
+		"@scope/pkg@^2.0.0":            "@scope/pkg",
+		"@scope/pkg@npm:2.0.0":         "@scope/pkg",
+		"foo@npm:bar@^1.0.0":           "foo",
+		"@scope/foo@npm:@other/bar@^1": "@scope/foo",
+		"left-pad":                     "left-pad",
+		"@scope/pkg":                   "@scope/pkg",
+	}
+	for spec, want := range tests {
+		if got := npmSpecName(spec); got != want {
+			t.Errorf("npmSpecName(%q) = %q, want %q", spec, got, want)
+		}
+	}
+}
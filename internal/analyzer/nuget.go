@@ -0,0 +1,286 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+func init() {
+	mustRegisterDependencyParser(nugetParser{})
+}
+
+// nugetParser reads the PackageReference items of .NET project files, with
+// central package versions from Directory.Packages.props, resolved versions
+// from packages.lock.json and legacy packages.config files
+type nugetParser struct{}
+
+// maxProjectDepth bounds the search for project files below a project path
+const maxProjectDepth = 4
+
+var msbuildPropertyPattern = regexp.MustCompile(`\$\(([^)]+)\)`)
+
+// msbuildProject is the subset of a project file used for dependency analysis
+type msbuildProject struct {
+	PropertyGroups []struct {
+		Properties []struct {
+			XMLName xml.Name
+			Value   string `xml:",chardata"`
+		} `xml:",any"`
+	} `xml:"PropertyGroup"`
+	ItemGroups []struct {
+		Condition         string             `xml:"Condition,attr"`
+		PackageReferences []packageReference `xml:"PackageReference"`
+		PackageVersions   []packageReference `xml:"PackageVersion"`
+	} `xml:"ItemGroup"`
+}
+
+type packageReference struct {
+	Include         string `xml:"Include,attr"`
+	Version         string `xml:"Version,attr"`
+	VersionElement  string `xml:"Version"`
+	VersionOverride string `xml:"VersionOverride,attr"`
+	PrivateAssets   string `xml:"PrivateAssets,attr"`
+	PrivateElement  string `xml:"PrivateAssets"`
+	Condition       string `xml:"Condition,attr"`
+}
+
+// nugetLock is packages.lock.json: target framework -> package -> entry
+type nugetLock struct {
+	Dependencies map[string]map[string]struct {
+		Type     string `json:"type"`
+		Resolved string `json:"resolved"`
+	} `json:"dependencies"`
+}
+
+func (nugetParser) Ecosystem() string { return "nuget" }
+
+func (nugetParser) Detect(root string) bool {
+	return len(findDotnetProjects(root)) > 0
+}
+
+func (nugetParser) Parse(root string, includeTransitive bool) ([]Dependency, error) {
+	var deps []Dependency
+
+	for _, projectFile := range findDotnetProjects(root) {
+		module := strings.TrimSuffix(filepath.Base(projectFile), filepath.Ext(projectFile))
+		dir := filepath.Dir(projectFile)
+
+		project, err := readMSBuildProject(projectFile)
+		if err != nil {
+			continue
+		}
+		central := centralPackageVersions(dir, root)
+		resolved, transitive := readNuGetLock(filepath.Join(dir, "packages.lock.json"))
+
+		properties := make(map[string]string)
+		for _, group := range project.PropertyGroups {
+			for _, p := range group.Properties {
+				properties[p.XMLName.Local] = strings.TrimSpace(p.Value)
+			}
+		}
+		resolve := func(value string) string {
+			return msbuildPropertyPattern.ReplaceAllStringFunc(strings.TrimSpace(value), func(ref string) string {
+				if v, ok := properties[ref[2:len(ref)-1]]; ok {
+					return v
+				}
+				return ref
+			})
+		}
+
+		seen := make(map[string]bool)
+		for _, group := range project.ItemGroups {
+			for _, ref := range group.PackageReferences {
+				name := ref.Include
+				if name == "" || seen[strings.ToLower(name)] {
+					continue
+				}
+				seen[strings.ToLower(name)] = true
+
+				constraint := firstNonEmpty(ref.VersionOverride, ref.Version, ref.VersionElement, central[strings.ToLower(name)])
+				dep := Dependency{
+					Name:       name,
+					Type:       "direct",
+					Path:       projectFile,
+					Module:     module,
+					Ecosystem:  "nuget",
+					Constraint: resolve(constraint),
+					Markers:    firstNonEmpty(ref.Condition, group.Condition),
+				}
+				// Analyzers and build tools do not flow to consumers
+				if strings.EqualFold(strings.TrimSpace(firstNonEmpty(ref.PrivateAssets, ref.PrivateElement)), "all") {
+					dep.Type = "dev"
+				}
+
+				dep.Version = firstNonEmpty(resolved[strings.ToLower(name)], dep.Constraint, "*")
+				if dep.Version == dep.Constraint {
+					dep.Constraint = ""
+				}
+				deps = append(deps, dep)
+			}
+		}
+
+		// Legacy projects list packages in packages.config
+		configPath := filepath.Join(dir, "packages.config")
+		for _, pkg := range readPackagesConfig(configPath) {
+			if seen[strings.ToLower(pkg.Name)] {
+				continue
+			}
+			seen[strings.ToLower(pkg.Name)] = true
+			pkg.Path = configPath
+			pkg.Module = module
+			deps = append(deps, pkg)
+		}
+
+		if includeTransitive {
+			for _, name := range sortedKeys(transitive) {
+				deps = append(deps, Dependency{
+					Name:      transitive[name].Path,
+					Version:   transitive[name].Version,
+					Type:      "indirect",
+					Path:      filepath.Join(dir, "packages.lock.json"),
+					Module:    module,
+					Ecosystem: "nuget",
+				})
+			}
+		}
+	}
+
+	return deps, nil
+}
+
+// findDotnetProjects returns the .csproj, .fsproj and .vbproj files below
+// root, skipping build output
+func findDotnetProjects(root string) []string {
+	var projects []string
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			name := d.Name()
+			rel, _ := filepath.Rel(root, path)
+			if path != root && (strings.HasPrefix(name, ".") || name == "bin" || name == "obj" || name == "node_modules") {
+				return filepath.SkipDir
+			}
+			if strings.Count(rel, string(filepath.Separator)) >= maxProjectDepth {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		switch filepath.Ext(path) {
+		case ".csproj", ".fsproj", ".vbproj":
+			projects = append(projects, path)
+		}
+		return nil
+	})
+	sort.Strings(projects)
+	return projects
+}
+
+func readMSBuildProject(path string) (*msbuildProject, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var project msbuildProject
+	if err := xml.Unmarshal(data, &project); err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+// centralPackageVersions reads the nearest Directory.Packages.props between
+// dir and root, keyed by lowercase package id
+func centralPackageVersions(dir, root string) map[string]string {
+	versions := make(map[string]string)
+	for {
+		if project, err := readMSBuildProject(filepath.Join(dir, "Directory.Packages.props")); err == nil {
+			for _, group := range project.ItemGroups {
+				for _, pv := range group.PackageVersions {
+					versions[strings.ToLower(pv.Include)] = firstNonEmpty(pv.Version, pv.VersionElement)
+				}
+			}
+			return versions
+		}
+		if dir == root || filepath.Dir(dir) == dir {
+			return versions
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+// readNuGetLock returns resolved versions of direct packages and the
+// transitive packages of packages.lock.json, both keyed by lowercase id
+func readNuGetLock(path string) (map[string]string, map[string]ModuleVersion) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil
+	}
+	var lock nugetLock
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, nil
+	}
+
+	resolved := make(map[string]string)
+	transitive := make(map[string]ModuleVersion)
+	// The first target framework wins when they disagree
+	for _, framework := range sortedKeys(lock.Dependencies) {
+		for name, entry := range lock.Dependencies[framework] {
+			key := strings.ToLower(name)
+			switch entry.Type {
+			case "Direct", "CentralTransitive":
+				if _, ok := resolved[key]; !ok {
+					resolved[key] = entry.Resolved
+				}
+			case "Transitive":
+				if _, ok := transitive[key]; !ok {
+					transitive[key] = ModuleVersion{Path: name, Version: entry.Resolved}
+				}
+			}
+		}
+	}
+	return resolved, transitive
+}
+
+// readPackagesConfig reads the packages of a legacy packages.config file
+func readPackagesConfig(path string) []Dependency {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var config struct {
+		Packages []struct {
+			ID                    string `xml:"id,attr"`
+			Version               string `xml:"version,attr"`
+			DevelopmentDependency string `xml:"developmentDependency,attr"`
+		} `xml:"package"`
+	}
+	if err := xml.Unmarshal(data, &config); err != nil {
+		return nil
+	}
+
+	var deps []Dependency
+	for _, pkg := range config.Packages {
+		dep := Dependency{Name: pkg.ID, Version: pkg.Version, Type: "direct", Ecosystem: "nuget"}
+		if pkg.DevelopmentDependency == "true" {
+			dep.Type = "dev"
+		}
+		deps = append(deps, dep)
+	}
+	return deps
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
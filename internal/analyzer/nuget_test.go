@@ -0,0 +1,79 @@
+package analyzer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNuGetParser(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"Directory.Packages.props": `<Project>
+  <ItemGroup>
+    <PackageVersion Include="Serilog" Version="3.1.1" />
+    <PackageVersion Include="xunit" Version="2.6.2" />
+  </ItemGroup>
+</Project>`,
+		"src/Api/Api.csproj": `<Project Sdk="Microsoft.NET.Sdk.Web">
+  <PropertyGroup>
+    <EfVersion>8.0.0</EfVersion>
+  </PropertyGroup>
+  <ItemGroup>
+    <PackageReference Include="Serilog" />
+    <PackageReference Include="Microsoft.EntityFrameworkCore" Version="$(EfVersion)" />
+    <PackageReference Include="Newtonsoft.Json">
+      <Version>13.0.3</Version>
+    </PackageReference>
+    <PackageReference Include="StyleCop.Analyzers" Version="1.1.118" PrivateAssets="all" />
+    <PackageReference Include="serilog" Version="9.9.9" />
+  </ItemGroup>
+  <ItemGroup Condition="'$(TargetFramework)' == 'net48'">
+    <PackageReference Include="System.Memory" Version="4.5.5" />
+  </ItemGroup>
+</Project>`,
+		"src/Api/packages.lock.json": `{"version": 2, "dependencies": {"net8.0": {
+  "Serilog": {"type": "Direct", "requested": "[3.1.1, )", "resolved": "3.1.1"},
+  "Microsoft.EntityFrameworkCore": {"type": "Direct", "requested": "[8.0.0, )", "resolved": "8.0.0"},
+  "Newtonsoft.Json": {"type": "Direct", "requested": "[13.0.3, )", "resolved": "13.0.3"},
+  "Microsoft.Extensions.Logging": {"type": "Transitive", "resolved": "8.0.0"}
+}}}`,
+		"tests/Api.Tests/Api.Tests.csproj": `<Project Sdk="Microsoft.NET.Sdk">
+  <ItemGroup>
+    <PackageReference Include="xunit" VersionOverride="2.5.0" />
+  </ItemGroup>
+</Project>`,
+		"legacy/Legacy.vbproj": `<Project ToolsVersion="15.0" />`,
+		"legacy/packages.config": `<?xml version="1.0" encoding="utf-8"?>
+<packages>
+  <package id="EntityFramework" version="6.4.4" targetFramework="net472" />
+  <package id="Microsoft.Net.Compilers" version="2.4.0" developmentDependency="true" />
+</packages>`,
+		"src/Api/bin/Debug/Copy.csproj": `<Project />`,
+	})
+	if !(nugetParser{}).Detect(root) {
+		t.Fatal("Detect() = false")
+	}
+	deps, err := nugetParser{}.Parse(root, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"EntityFramework@6.4.4 direct Legacy legacy/packages.config",
+		"Microsoft.Net.Compilers@2.4.0 dev Legacy legacy/packages.config",
+		"Serilog@3.1.1 direct Api src/Api/Api.csproj",
+		"Microsoft.EntityFrameworkCore@8.0.0 direct Api src/Api/Api.csproj",
+		"Newtonsoft.Json@13.0.3 direct Api src/Api/Api.csproj",
+		"StyleCop.Analyzers@1.1.118 dev Api src/Api/Api.csproj",
+		"System.Memory@4.5.5 direct Api src/Api/Api.csproj ; '$(TargetFramework)' == 'net48'",
+		"Microsoft.Extensions.Logging@8.0.0 indirect Api src/Api/packages.lock.json",
+		"xunit@2.5.0 direct Api.Tests tests/Api.Tests/Api.Tests.csproj",
+	}
+	if got := depStrings(root, deps); !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse() =\n%q\nwant\n%q", got, want)
+	}
+
+	if (nugetParser{}).Detect(t.TempDir()) {
+		t.Error("Detect() = true without project files")
+	}
+}
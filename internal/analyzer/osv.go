@@ -0,0 +1,576 @@
+package analyzer
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Vulnerability is an OSV advisory affecting a dependency version
+type Vulnerability struct {
+	ID           string
+	Aliases      []string // CVE and GHSA identifiers
+	Summary      string
+	Severity     string  // CRITICAL, HIGH, MEDIUM, LOW or UNKNOWN
+	Score        float64 // CVSS v3 base score, 0 if unknown
+	Dependency   Dependency
+	Ranges       []string // affected version ranges, e.g. ">=1.0.0, <1.2.3"
+	Fixed        string   // lowest fixed version above the dependency version
+	Reachability string   // Go only: called, imported or not imported
+	CallSites    []string // file:line of project calls to vulnerable symbols
+}
+
+// VulnerabilityOptions selects the OSV database and analysis depth
+type VulnerabilityOptions struct {
+	Database     string // directory or zip of OSV JSON files; empty uses the configured one
+	Reachability bool   // check whether vulnerable Go packages and symbols are used
+}
+
+// osvAdvisory is an OSV-format advisory (https://ossf.github.io/osv-schema/)
+type osvAdvisory struct {
+	ID               string                 `json:"id"`
+	Aliases          []string               `json:"aliases"`
+	Summary          string                 `json:"summary"`
+	Details          string                 `json:"details"`
+	Withdrawn        string                 `json:"withdrawn"`
+	Severity         []osvSeverity          `json:"severity"`
+	Affected         []osvAffected          `json:"affected"`
+	DatabaseSpecific map[string]interface{} `json:"database_specific"`
+}
+
+type osvSeverity struct {
+	Type  string `json:"type"`
+	Score string `json:"score"`
+}
+
+type osvAffected struct {
+	Package struct {
+		Ecosystem string `json:"ecosystem"`
+		Name      string `json:"name"`
+	} `json:"package"`
+	Ranges []struct {
+		Type   string              `json:"type"`
+		Events []map[string]string `json:"events"`
+	} `json:"ranges"`
+	Versions          []string      `json:"versions"`
+	Severity          []osvSeverity `json:"severity"`
+	EcosystemSpecific struct {
+		Imports []struct {
+			Path    string   `json:"path"`
+			Symbols []string `json:"symbols"`
+		} `json:"imports"`
+	} `json:"ecosystem_specific"`
+	DatabaseSpecific map[string]interface{} `json:"database_specific"`
+}
+
+// osvEcosystems maps dependency ecosystems to OSV ecosystem names
+var osvEcosystems = map[string]string{
+	"go":     "Go",
+	"npm":    "npm",
+	"python": "PyPI",
+	"cargo":  "crates.io",
+	"maven":  "Maven",
+	"gradle": "Maven",
+	"nuget":  "NuGet",
+}
+
+// Vulnerabilities checks every resolved dependency version, including
+// transitive ones, against an offline OSV database
+func (a *ProjectAnalyzer) Vulnerabilities(opts VulnerabilityOptions) ([]Vulnerability, error) {
+	database := opts.Database
+	if database == "" {
+		database = a.deps.OSVDatabase
+	}
+	if database == "" {
+		return nil, fmt.Errorf("no OSV database configured")
+	}
+	database = expandHome(database)
+
+	deps, warnings, err := a.AnalyzeDependencies(true)
+	if err != nil {
+		return nil, err
+	}
+	for _, warning := range warnings {
+		log.Printf("analyzer: %s", warning)
+	}
+
+	// Dependencies by OSV ecosystem and normalized name; ranges and
+	// placeholders cannot be matched
+	byPackage := make(map[string][]Dependency)
+	for _, dep := range deps {
+		ecosystem, ok := osvEcosystems[dep.Ecosystem]
+		if !ok || !isConcreteVersion(dep.Version) {
+			continue
+		}
+		key := ecosystem + "\x00" + osvPackageName(ecosystem, dep.Name)
+		byPackage[key] = append(byPackage[key], dep)
+	}
+
+	var vulns []Vulnerability
+	seen := make(map[string]bool)
+	err = walkOSVDatabase(database, func(adv *osvAdvisory) {
+		if adv.Withdrawn != "" {
+			return
+		}
+		for i := range adv.Affected {
+			affected := &adv.Affected[i]
+			ecosystem := affected.Package.Ecosystem
+			if j := strings.Index(ecosystem, ":"); j >= 0 {
+				ecosystem = ecosystem[:j]
+			}
+			for _, dep := range byPackage[ecosystem+"\x00"+osvPackageName(ecosystem, affected.Package.Name)] {
+				ranges, fixed, hit := affected.matches(dep)
+				if !hit {
+					continue
+				}
+				key := adv.ID + "\x00" + dep.Module + "\x00" + dep.Name + "\x00" + dep.Version
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+
+				severity, score := adv.severity(affected)
+				vulns = append(vulns, Vulnerability{
+					ID:         adv.ID,
+					Aliases:    adv.Aliases,
+					Summary:    adv.summary(),
+					Severity:   severity,
+					Score:      score,
+					Dependency: dep,
+					Ranges:     ranges,
+					Fixed:      fixed,
+				})
+				if opts.Reachability && dep.Ecosystem == "go" {
+					last := &vulns[len(vulns)-1]
+					last.Reachability, last.CallSites = a.goReachability(dep.Name, affected)
+				}
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(vulns, func(i, j int) bool {
+		if vulns[i].Score != vulns[j].Score {
+			return vulns[i].Score > vulns[j].Score
+		}
+		if severityRank(vulns[i].Severity) != severityRank(vulns[j].Severity) {
+			return severityRank(vulns[i].Severity) > severityRank(vulns[j].Severity)
+		}
+		if vulns[i].Dependency.Name != vulns[j].Dependency.Name {
+			return vulns[i].Dependency.Name < vulns[j].Dependency.Name
+		}
+		return vulns[i].ID < vulns[j].ID
+	})
+
+	return vulns, nil
+}
+
+// walkOSVDatabase calls fn for every advisory of a directory tree of JSON
+// files, a zip archive (such as an OSV all.zip export), or zips in a directory
+func walkOSVDatabase(path string, fn func(*osvAdvisory)) error {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("OSV database: %w", err)
+	}
+	if !stat.IsDir() {
+		return walkOSVZip(path, fn)
+	}
+
+	return filepath.WalkDir(path, func(file string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		switch strings.ToLower(filepath.Ext(file)) {
+		case ".json":
+			if data, err := os.ReadFile(file); err == nil {
+				decodeOSVAdvisory(data, fn)
+			}
+		case ".zip":
+			return walkOSVZip(file, fn)
+		}
+		return nil
+	})
+}
+
+func walkOSVZip(path string, fn func(*osvAdvisory)) error {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return fmt.Errorf("OSV database: %w", err)
+	}
+	defer archive.Close()
+
+	for _, file := range archive.File {
+		if !strings.HasSuffix(strings.ToLower(file.Name), ".json") {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			continue
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err == nil {
+			decodeOSVAdvisory(data, fn)
+		}
+	}
+	return nil
+}
+
+// decodeOSVAdvisory skips files that are not advisories, such as indexes
+func decodeOSVAdvisory(data []byte, fn func(*osvAdvisory)) {
+	var adv osvAdvisory
+	if json.Unmarshal(data, &adv) == nil && adv.ID != "" && len(adv.Affected) > 0 {
+		fn(&adv)
+	}
+}
+
+// osvPackageName normalizes a package name for comparison within an ecosystem
+func osvPackageName(ecosystem, name string) string {
+	switch ecosystem {
+	case "PyPI":
+		return normalizePythonName(name)
+	case "NuGet":
+		return strings.ToLower(name)
+	}
+	return name
+}
+
+// matches reports whether the dependency version is affected, with the
+// affected ranges and the lowest fixed version above it
+func (af *osvAffected) matches(dep Dependency) ([]string, string, bool) {
+	version := dep.Version
+	compare := func(v, w string) int {
+		return compareVersions(dep.Ecosystem, v, w)
+	}
+
+	hit := false
+	for _, v := range af.Versions {
+		if compare(v, version) == 0 {
+			hit = true
+		}
+	}
+
+	var ranges []string
+	fixed := ""
+	for _, r := range af.Ranges {
+		// Commit ranges cannot be matched against versions
+		if r.Type == "GIT" {
+			continue
+		}
+
+		events := append([]map[string]string(nil), r.Events...)
+		sort.SliceStable(events, func(i, j int) bool {
+			return compare(osvEventVersion(events[i]), osvEventVersion(events[j])) < 0
+		})
+
+		affected := false
+		var intervals []string
+		lower := ""
+		for _, event := range events {
+			switch {
+			case event["introduced"] != "":
+				lower = event["introduced"]
+				if lower == "0" || compare(version, lower) >= 0 {
+					affected = true
+				}
+			case event["fixed"] != "":
+				intervals = append(intervals, osvInterval(lower, "<"+event["fixed"]))
+				lower = ""
+				if compare(version, event["fixed"]) >= 0 {
+					affected = false
+				} else if affected && (fixed == "" || compare(event["fixed"], fixed) < 0) {
+					fixed = event["fixed"]
+				}
+			case event["last_affected"] != "":
+				intervals = append(intervals, osvInterval(lower, "<="+event["last_affected"]))
+				lower = ""
+				if compare(version, event["last_affected"]) > 0 {
+					affected = false
+				}
+			case event["limit"] != "":
+				if compare(version, event["limit"]) >= 0 {
+					affected = false
+				}
+			}
+		}
+		if lower != "" {
+			intervals = append(intervals, osvInterval(lower, ""))
+		}
+
+		if affected {
+			hit = true
+			ranges = append(ranges, intervals...)
+		}
+	}
+
+	if hit && len(ranges) == 0 && len(af.Versions) > 0 {
+		ranges = []string{"listed versions"}
+	}
+	return ranges, fixed, hit
+}
+
+// osvEventVersion returns the version of a range event; "0" sorts first
+func osvEventVersion(event map[string]string) string {
+	for _, key := range []string{"introduced", "fixed", "last_affected", "limit"} {
+		if v, ok := event[key]; ok {
+			if v == "0" {
+				return "0.0.0-0"
+			}
+			return v
+		}
+	}
+	return ""
+}
+
+func osvInterval(lower, upper string) string {
+	switch {
+	case (lower == "" || lower == "0") && upper == "":
+		return "all versions"
+	case lower == "" || lower == "0":
+		return upper
+	case upper == "":
+		return ">=" + lower
+	}
+	return ">=" + lower + ", " + upper
+}
+
+// summary falls back to the first line of the details
+func (adv *osvAdvisory) summary() string {
+	if adv.Summary != "" {
+		return adv.Summary
+	}
+	details := strings.TrimSpace(adv.Details)
+	if i := strings.Index(details, "\n"); i >= 0 {
+		details = details[:i]
+	}
+	if len(details) > 200 {
+		details = details[:200] + "..."
+	}
+	return details
+}
+
+// severity rates an advisory from its CVSS v3 vector, or from the severity
+// label of the source database (GitHub advisories use MODERATE for MEDIUM)
+func (adv *osvAdvisory) severity(affected *osvAffected) (string, float64) {
+	for _, list := range [][]osvSeverity{affected.Severity, adv.Severity} {
+		for _, s := range list {
+			if s.Type == "CVSS_V3" {
+				if score, ok := cvss3BaseScore(s.Score); ok {
+					return cvssRating(score), score
+				}
+			}
+		}
+	}
+
+	for _, specific := range []map[string]interface{}{affected.DatabaseSpecific, adv.DatabaseSpecific} {
+		if label, ok := specific["severity"].(string); ok && label != "" {
+			label = strings.ToUpper(label)
+			if label == "MODERATE" {
+				label = "MEDIUM"
+			}
+			return label, 0
+		}
+	}
+	return "UNKNOWN", 0
+}
+
+// cvss3BaseScore computes the base score of a CVSS v3.x vector string
+func cvss3BaseScore(vector string) (float64, bool) {
+	weights := map[string]map[string]float64{
+		"AV": {"N": 0.85, "A": 0.62, "L": 0.55, "P": 0.2},
+		"AC": {"L": 0.77, "H": 0.44},
+		"UI": {"N": 0.85, "R": 0.62},
+		"C":  {"H": 0.56, "L": 0.22, "N": 0},
+		"I":  {"H": 0.56, "L": 0.22, "N": 0},
+		"A":  {"H": 0.56, "L": 0.22, "N": 0},
+	}
+
+	metrics := make(map[string]string)
+	for _, part := range strings.Split(vector, "/") {
+		if k, v, ok := strings.Cut(part, ":"); ok {
+			metrics[k] = v
+		}
+	}
+	if !strings.HasPrefix(metrics["CVSS"], "3") {
+		return 0, false
+	}
+
+	values := make(map[string]float64)
+	for metric, table := range weights {
+		w, ok := table[metrics[metric]]
+		if !ok {
+			return 0, false
+		}
+		values[metric] = w
+	}
+
+	changed := metrics["S"] == "C"
+	switch metrics["PR"] {
+	case "N":
+		values["PR"] = 0.85
+	case "L":
+		values["PR"] = 0.62
+		if changed {
+			values["PR"] = 0.68
+		}
+	case "H":
+		values["PR"] = 0.27
+		if changed {
+			values["PR"] = 0.5
+		}
+	default:
+		return 0, false
+	}
+
+	iss := 1 - (1-values["C"])*(1-values["I"])*(1-values["A"])
+	impact := 6.42 * iss
+	if changed {
+		impact = 7.52*(iss-0.029) - 3.25*math.Pow(iss-0.02, 15)
+	}
+	if impact <= 0 {
+		return 0, true
+	}
+
+	exploitability := 8.22 * values["AV"] * values["AC"] * values["PR"] * values["UI"]
+	if changed {
+		return cvssRoundUp(math.Min(1.08*(impact+exploitability), 10)), true
+	}
+	return cvssRoundUp(math.Min(impact+exploitability, 10)), true
+}
+
+// cvssRoundUp rounds up to one decimal as specified by CVSS v3.1
+func cvssRoundUp(value float64) float64 {
+	scaled := int(math.Round(value * 100000))
+	if scaled%10000 == 0 {
+		return float64(scaled) / 100000
+	}
+	return (math.Floor(float64(scaled)/10000) + 1) / 10
+}
+
+func cvssRating(score float64) string {
+	switch {
+	case score >= 9:
+		return "CRITICAL"
+	case score >= 7:
+		return "HIGH"
+	case score >= 4:
+		return "MEDIUM"
+	case score > 0:
+		return "LOW"
+	}
+	return "NONE"
+}
+
+func severityRank(severity string) int {
+	switch severity {
+	case "CRITICAL":
+		return 4
+	case "HIGH":
+		return 3
+	case "MEDIUM":
+		return 2
+	case "LOW":
+		return 1
+	}
+	return 0
+}
+
+// goReachability reports whether project code imports the vulnerable
+// packages of a module and calls the vulnerable symbols directly. Calls made
+// only from inside dependencies are not followed.
+func (a *ProjectAnalyzer) goReachability(module string, affected *osvAffected) (string, []string) {
+	a.ensureIndexed()
+
+	// Without package details any package of the module counts
+	vulnerable := make(map[string][]string)
+	for _, imp := range affected.EcosystemSpecific.Imports {
+		vulnerable[imp.Path] = imp.Symbols
+	}
+	inModule := func(path string) bool {
+		if len(vulnerable) > 0 {
+			_, ok := vulnerable[path]
+			return ok
+		}
+		return path == module || strings.HasPrefix(path, module+"/")
+	}
+
+	imported := false
+	for _, file := range a.goFiles() {
+		for _, imp := range file.Imports {
+			if inModule(imp) {
+				imported = true
+			}
+		}
+	}
+	if !imported {
+		return "not imported", nil
+	}
+
+	graph := a.CallGraph()
+	if graph == nil {
+		return "imported", nil
+	}
+
+	var sites []string
+	for _, node := range graph.Nodes {
+		if !node.External || len(node.In) == 0 {
+			continue
+		}
+		pkgPath := callNodePackagePath(node.Key)
+		if !inModule(pkgPath) {
+			continue
+		}
+		if symbols := vulnerable[pkgPath]; len(symbols) > 0 && !containsSymbol(symbols, node) {
+			continue
+		}
+		for _, site := range node.In {
+			sites = append(sites, fmt.Sprintf("%s:%d → %s", a.displayPath(site.Path), site.Line, node.Name))
+		}
+	}
+	if len(sites) == 0 {
+		return "imported", nil
+	}
+	sort.Strings(sites)
+	return "called", sites
+}
+
+// callNodePackagePath extracts the package path from a types.Func.FullName
+// key: pkg/path.Func, (pkg/path.Type).Method or (*pkg/path.Type).Method
+func callNodePackagePath(key string) string {
+	if strings.HasPrefix(key, "(") {
+		key = strings.TrimPrefix(strings.TrimPrefix(key, "("), "*")
+		if i := strings.Index(key, ")"); i >= 0 {
+			key = key[:i]
+		}
+	}
+	// The last dot after the last slash separates the package from the name
+	slash := strings.LastIndex(key, "/")
+	if i := strings.Index(key[slash+1:], "."); i >= 0 {
+		return key[:slash+1+i]
+	}
+	return key
+}
+
+// containsSymbol matches a call node against OSV symbols (Func or Type.Method)
+func containsSymbol(symbols []string, node *CallNode) bool {
+	name := node.Func
+	if node.Receiver != "" {
+		name = node.Receiver + "." + node.Func
+	}
+	for _, symbol := range symbols {
+		if symbol == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestCVSS3BaseScore(t *testing.T) {
+	tests := []struct {
+		vector string
+		want   float64
+		ok     bool
+	}{
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", 9.8, true},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H", 10.0, true},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:R/S:C/C:L/I:L/A:N", 6.1, true},
+		{"CVSS:3.1/AV:L/AC:L/PR:L/UI:N/S:U/C:H/I:H/A:H", 7.8, true},
+		{"CVSS:3.0/AV:N/AC:H/PR:N/UI:N/S:U/C:H/I:N/A:N", 5.9, true},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:L", 5.3, true},
+		{"CVSS:3.1/AV:P/AC:H/PR:H/UI:R/S:U/C:L/I:N/A:N", 1.6, true},
+		{"CVSS:3.1/AV:N/AC:L/PR:H/UI:N/S:C/C:L/I:L/A:N", 5.5, true},
+		{"CVSS:3.1/AV:N/AC:L/PR:H/UI:R/S:C/C:L/I:L/A:N", 4.8, true},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:N", 0, true},
+		{"AV:N/AC:L/Au:N/C:P/I:P/A:P", 0, false},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H", 0, false},
+		{"CVSS:3.1/AV:X/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := cvss3BaseScore(tt.vector)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("cvss3BaseScore(%q) = %v, %v; want %v, %v", tt.vector, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestOSVAffectedMatches(t *testing.T) {
+	const twoRanges = `{"ranges": [{"type": "SEMVER", "events": [
+		{"introduced": "2.0.0"}, {"fixed": "2.1.3"}, {"introduced": "0"}, {"fixed": "1.2.0"}]}]}`
+	const lastAffected = `{"ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "1.0.0"}, {"last_affected": "1.4.0"}]}]}`
+	const limited = `{"ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"limit": "3.0.0"}]}]}`
+	const listed = `{"versions": ["1.0.0", "1.0.1"], "ranges": [{"type": "GIT", "events": [{"introduced": "0"}]}]}`
+
+	tests := []struct {
+		name     string
+		affected string
+		version  string
+		ranges   []string
+		fixed    string
+		hit      bool
+	}{
+		{"first range", twoRanges, "1.1.0", []string{"<1.2.0", ">=2.0.0, <2.1.3"}, "1.2.0", true},
+		{"between ranges", twoRanges, "1.5.0", nil, "", false},
+		{"second range", twoRanges, "2.0.5", []string{"<1.2.0", ">=2.0.0, <2.1.3"}, "2.1.3", true},
+		{"fixed", twoRanges, "2.1.3", nil, "", false},
+		{"before introduced", lastAffected, "0.9.0", nil, "", false},
+		{"last affected", lastAffected, "1.4.0", []string{">=1.0.0, <=1.4.0"}, "", true},
+		{"after last affected", lastAffected, "1.4.1", nil, "", false},
+		{"below limit", limited, "2.9.9", []string{"all versions"}, "", true},
+		{"at limit", limited, "3.0.0", nil, "", false},
+		{"listed version", listed, "1.0.1", []string{"listed versions"}, "", true},
+		{"unlisted version", listed, "1.0.2", nil, "", false},
+	}
+	for _, tt := range tests {
+		var af osvAffected
+		if err := json.Unmarshal([]byte(tt.affected), &af); err != nil {
+			t.Fatal(err)
+		}
+		ranges, fixed, hit := af.matches(Dependency{Ecosystem: "npm", Version: tt.version})
+		if !reflect.DeepEqual(ranges, tt.ranges) || fixed != tt.fixed || hit != tt.hit {
+			t.Errorf("%s: matches(%s) = %q, %q, %v; want %q, %q, %v", tt.name, tt.version, ranges, fixed, hit, tt.ranges, tt.fixed, tt.hit)
+		}
+	}
+}
@@ -0,0 +1,348 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// Outdated lists the newer releases of a dependency by semver distance
+type Outdated struct {
+	Dependency Dependency
+	Latest     string // version the registry reports as latest
+	Patch      string // newest release with the same major and minor version
+	Minor      string // newest release with the same major version
+	Major      string // newest release of a higher major version
+	MajorPath  string // Go module path of that major version, e.g. example.com/m/v2
+	Kind       string // largest available upgrade: patch, minor or major
+}
+
+// OutdatedOptions selects the registries queried for newer versions
+type OutdatedOptions struct {
+	GoProxy     string // GOPROXY-protocol URL (http, https or file); empty uses the configured one
+	NPMRegistry string // npm registry URL (http, https or file); empty uses the configured one
+}
+
+// registryFetchers bounds concurrent registry requests
+const registryFetchers = 8
+
+// maxMajorProbes bounds the /vN module paths probed for major upgrades
+const maxMajorProbes = 5
+
+var registryClient = &http.Client{Timeout: 15 * time.Second}
+
+// errNotFound reports a module or package unknown to the registry
+var errNotFound = fmt.Errorf("not found")
+
+// Outdated queries a module proxy and an npm registry for releases newer
+// than the resolved version of each direct Go and npm dependency
+func (a *ProjectAnalyzer) Outdated(opts OutdatedOptions) ([]Outdated, error) {
+	goProxy := firstNonEmpty(opts.GoProxy, a.deps.GoProxy)
+	npmRegistry := firstNonEmpty(opts.NPMRegistry, a.deps.NPMRegistry)
+	if goProxy == "" && npmRegistry == "" {
+		return nil, fmt.Errorf("no module proxy or npm registry configured")
+	}
+
+	deps, warnings, err := a.AnalyzeDependencies(false)
+	if err != nil {
+		return nil, err
+	}
+	for _, warning := range warnings {
+		log.Printf("analyzer: %s", warning)
+	}
+
+	type query struct{ ecosystem, name string }
+	var queries []query
+	queued := make(map[query]bool)
+	for _, dep := range deps {
+		if dep.Type == "indirect" || !isConcreteVersion(dep.Version) {
+			continue
+		}
+		q := query{dep.Ecosystem, dep.Name}
+		if queued[q] {
+			continue
+		}
+		switch {
+		case dep.Ecosystem == "go" && goProxy != "":
+		case dep.Ecosystem == "npm" && npmRegistry != "":
+		default:
+			continue
+		}
+		queued[q] = true
+		queries = append(queries, q)
+	}
+
+	// Each package is queried once, however many modules require it
+	type release struct {
+		versions   []string
+		latest     string
+		majorPaths map[string]string
+		err        error
+	}
+	releases := make(map[query]release)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, registryFetchers)
+	for _, q := range queries {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(q query) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			var r release
+			if q.ecosystem == "go" {
+				r.versions, r.latest, r.majorPaths, r.err = goProxyVersions(goProxy, q.name)
+			} else {
+				r.versions, r.latest, r.err = npmVersions(npmRegistry, q.name)
+			}
+			mu.Lock()
+			releases[q] = r
+			mu.Unlock()
+		}(q)
+	}
+	wg.Wait()
+
+	// Unreachable registries fail the report; single missing packages do not
+	var failures []string
+	for _, q := range queries {
+		if err := releases[q].err; err != nil && err != errNotFound {
+			failures = append(failures, fmt.Sprintf("%s: %v", q.name, err))
+		}
+	}
+	if len(queries) > 0 && len(failures) == len(queries) {
+		return nil, fmt.Errorf("registry queries failed: %s", strings.Join(failures[:min(3, len(failures))], "; "))
+	}
+
+	var outdated []Outdated
+	for _, dep := range deps {
+		r, ok := releases[query{dep.Ecosystem, dep.Name}]
+		if !ok || r.err != nil || dep.Type == "indirect" || !isConcreteVersion(dep.Version) {
+			continue
+		}
+		if o, ok := classifyUpgrades(dep, r.versions, r.latest); ok {
+			if o.Major != "" {
+				o.MajorPath = r.majorPaths[semver.Major(canonicalSemver(o.Major))]
+			}
+			outdated = append(outdated, o)
+		}
+	}
+
+	sort.SliceStable(outdated, func(i, j int) bool {
+		if ki, kj := upgradeRank(outdated[i].Kind), upgradeRank(outdated[j].Kind); ki != kj {
+			return ki > kj
+		}
+		return outdated[i].Dependency.Name < outdated[j].Dependency.Name
+	})
+	return outdated, nil
+}
+
+func upgradeRank(kind string) int {
+	switch kind {
+	case "major":
+		return 3
+	case "minor":
+		return 2
+	case "patch":
+		return 1
+	}
+	return 0
+}
+
+// classifyUpgrades finds the newest patch, minor and major releases above
+// the dependency's version. Prereleases only count when the current version
+// is one, and +incompatible Go versions only when it is one too.
+func classifyUpgrades(dep Dependency, versions []string, latest string) (Outdated, bool) {
+	current := canonicalSemver(dep.Version)
+	if !semver.IsValid(current) {
+		return Outdated{}, false
+	}
+	incompatible := strings.HasSuffix(current, "+incompatible")
+
+	o := Outdated{Dependency: dep, Latest: latest}
+	for _, version := range versions {
+		v := canonicalSemver(version)
+		if !semver.IsValid(v) || semver.Compare(v, current) <= 0 {
+			continue
+		}
+		if semver.Prerelease(v) != "" && semver.Prerelease(current) == "" {
+			continue
+		}
+		if strings.HasSuffix(v, "+incompatible") && !incompatible {
+			continue
+		}
+
+		var slot *string
+		switch {
+		case semver.Major(v) != semver.Major(current):
+			slot = &o.Major
+		case semver.MajorMinor(v) != semver.MajorMinor(current):
+			slot = &o.Minor
+		default:
+			slot = &o.Patch
+		}
+		if *slot == "" || semver.Compare(v, canonicalSemver(*slot)) > 0 {
+			*slot = version
+		}
+	}
+
+	switch {
+	case o.Major != "":
+		o.Kind = "major"
+	case o.Minor != "":
+		o.Kind = "minor"
+	case o.Patch != "":
+		o.Kind = "patch"
+	default:
+		return Outdated{}, false
+	}
+	return o, true
+}
+
+// goProxyVersions lists the released versions of a module from a GOPROXY
+// endpoint, including the versions of its higher major version paths
+// (example.com/m/v2, gopkg.in/m.v3), whose paths are returned by major version
+func goProxyVersions(proxy, path string) ([]string, string, map[string]string, error) {
+	versions, latest, err := goProxyModuleVersions(proxy, path)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	majorPaths := make(map[string]string)
+	prefix, pathMajor, ok := module.SplitPathVersion(path)
+	if !ok {
+		return versions, latest, majorPaths, nil
+	}
+	major := 1
+	if pathMajor != "" {
+		major, _ = strconv.Atoi(strings.TrimLeft(pathMajor, "/.v"))
+	}
+	for probe := major + 1; probe <= major+maxMajorProbes; probe++ {
+		next := fmt.Sprintf("%s/v%d", prefix, probe)
+		if strings.HasPrefix(path, "gopkg.in/") {
+			next = fmt.Sprintf("%s.v%d", prefix, probe)
+		}
+		more, _, err := goProxyModuleVersions(proxy, next)
+		if err != nil || len(more) == 0 {
+			break
+		}
+		versions = append(versions, more...)
+		majorPaths[fmt.Sprintf("v%d", probe)] = next
+	}
+	return versions, latest, majorPaths, nil
+}
+
+// goProxyModuleVersions reads $proxy/<module>/@v/list, falling back to
+// @latest for modules with pseudo-versions only
+func goProxyModuleVersions(proxy, path string) ([]string, string, error) {
+	escaped, err := module.EscapePath(path)
+	if err != nil {
+		return nil, "", err
+	}
+	base := strings.TrimSuffix(proxy, "/") + "/" + escaped + "/@v/"
+
+	data, err := fetchRegistry(base+"list", "")
+	if err != nil {
+		return nil, "", err
+	}
+	var versions []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if version := strings.TrimSpace(line); version != "" {
+			versions = append(versions, version)
+		}
+	}
+
+	var info struct {
+		Version string
+	}
+	latestURL := strings.TrimSuffix(base, "@v/") + "@latest"
+	if data, err := fetchRegistry(latestURL, ""); err == nil && json.Unmarshal(data, &info) == nil && info.Version != "" {
+		if len(versions) == 0 {
+			versions = append(versions, info.Version)
+		}
+		return versions, info.Version, nil
+	}
+
+	// Like the go command, prefer the highest release over prereleases
+	semver.Sort(versions)
+	latest := ""
+	for _, version := range versions {
+		if latest == "" || semver.Prerelease(version) == "" || semver.Prerelease(latest) != "" {
+			latest = version
+		}
+	}
+	return versions, latest, nil
+}
+
+// npmVersions reads the versions and latest dist-tag of a package from an
+// npm registry
+func npmVersions(registry, name string) ([]string, string, error) {
+	// Scoped packages keep the @ but escape the slash
+	data, err := fetchRegistry(strings.TrimSuffix(registry, "/")+"/"+strings.Replace(name, "/", "%2f", 1), "application/vnd.npm.install-v1+json")
+	if err != nil {
+		return nil, "", err
+	}
+	var packument struct {
+		DistTags map[string]string          `json:"dist-tags"`
+		Versions map[string]json.RawMessage `json:"versions"`
+	}
+	if err := json.Unmarshal(data, &packument); err != nil {
+		return nil, "", fmt.Errorf("invalid registry response: %w", err)
+	}
+	return sortedKeys(packument.Versions), packument.DistTags["latest"], nil
+}
+
+// fetchRegistry GETs a registry URL. file:// URLs read the local directory
+// layout of the protocol; a path naming a directory reads its index.json,
+// which lets a plain directory stand in for an npm registry.
+func fetchRegistry(rawURL, accept string) ([]byte, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Scheme == "file" {
+		path := filepath.FromSlash(u.Path)
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			path = filepath.Join(path, "index.json")
+		}
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			return nil, errNotFound
+		}
+		return data, err
+	}
+
+	req, err := http.NewRequest("GET", rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	resp, err := registryClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return io.ReadAll(resp.Body)
+	case http.StatusNotFound, http.StatusGone:
+		return nil, errNotFound
+	}
+	return nil, fmt.Errorf("HTTP %d from %s", resp.StatusCode, u.Host)
+}
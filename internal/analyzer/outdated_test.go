@@ -0,0 +1,236 @@
+package analyzer
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/scopweb/mcp-go-context/internal/config"
+)
+
+func TestClassifyUpgrades(t *testing.T) {
+	tests := []struct {
+		name     string
+		current  string
+		versions []string
+		want     Outdated
+		ok       bool
+	}{
+		{
+			name:     "highest of each kind",
+			current:  "v1.2.3",
+			versions: []string{"v1.2.0", "v1.2.4", "v1.2.10", "v1.3.0", "v1.10.1", "v1.9.9", "v2.0.0", "v3.1.0", "v3.0.5"},
+			want:     Outdated{Patch: "v1.2.10", Minor: "v1.10.1", Major: "v3.1.0", Kind: "major"},
+			ok:       true,
+		},
+		{
+			name:     "npm versions without the v prefix",
+			current:  "4.17.20",
+			versions: []string{"4.17.19", "4.17.21", "4.18.0-beta.1"},
+			want:     Outdated{Patch: "4.17.21", Kind: "patch"},
+			ok:       true,
+		},
+		{
+			name:     "prereleases count from a prerelease",
+			current:  "v2.0.0-rc.1",
+			versions: []string{"v2.0.0-rc.2", "v2.0.0", "v2.1.0-beta.1"},
+			want:     Outdated{Patch: "v2.0.0", Minor: "v2.1.0-beta.1", Kind: "minor"},
+			ok:       true,
+		},
+		{
+			name:     "incompatible versions skipped",
+			current:  "v1.5.0",
+			versions: []string{"v2.0.0+incompatible", "v1.5.1"},
+			want:     Outdated{Patch: "v1.5.1", Kind: "patch"},
+			ok:       true,
+		},
+		{
+			name:     "incompatible versions count from an incompatible one",
+			current:  "v2.0.0+incompatible",
+			versions: []string{"v2.1.0+incompatible", "v3.0.0+incompatible"},
+			want:     Outdated{Minor: "v2.1.0+incompatible", Major: "v3.0.0+incompatible", Kind: "major"},
+			ok:       true,
+		},
+		{
+			name:     "up to date",
+			current:  "v1.2.3",
+			versions: []string{"v1.0.0", "v1.2.3", "v1.3.0-alpha", "not-a-version"},
+		},
+		{
+			name:     "unparseable current version",
+			current:  "latest",
+			versions: []string{"v1.0.0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dep := Dependency{Name: "example.com/m", Version: tt.current}
+			got, ok := classifyUpgrades(dep, tt.versions, "latest-tag")
+			if ok != tt.ok {
+				t.Fatalf("classifyUpgrades() ok = %v, want %v", ok, tt.ok)
+			}
+			if !ok {
+				return
+			}
+			tt.want.Dependency = dep
+			tt.want.Latest = "latest-tag"
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("classifyUpgrades() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGoProxyVersions(t *testing.T) {
+	proxy := t.TempDir()
+	writeFiles(t, proxy, map[string]string{
+		"example.com/m/@v/list":    "v1.0.0\nv1.2.0\n",
+		"example.com/m/@latest":    `{"Version":"v1.2.0"}`,
+		"example.com/m/v2/@v/list": "v2.0.0\nv2.1.0\n",
+		"example.com/m/v3/@v/list": "v3.0.0\n",
+		// v4 is missing, so v5 is never probed
+		"example.com/m/v5/@v/list":             "v5.0.0\n",
+		"github.com/!burnt!sushi/toml/@v/list": "v1.3.0\nv1.4.0-rc.1\nv1.3.2\n",
+		"gopkg.in/yaml.v2/@v/list":             "v2.4.0\n",
+		"gopkg.in/yaml.v3/@v/list":             "v3.0.1\n",
+		"example.com/pseudo/@v/list":           "",
+		"example.com/pseudo/@latest":           `{"Version":"v0.0.0-20240101000000-abcdefabcdef"}`,
+	})
+	proxyURL := "file://" + filepath.ToSlash(proxy)
+
+	tests := []struct {
+		path       string
+		versions   []string
+		latest     string
+		majorPaths map[string]string
+	}{
+		{
+			path:       "example.com/m",
+			versions:   []string{"v1.0.0", "v1.2.0", "v2.0.0", "v2.1.0", "v3.0.0"},
+			latest:     "v1.2.0",
+			majorPaths: map[string]string{"v2": "example.com/m/v2", "v3": "example.com/m/v3"},
+		},
+		{
+			path:       "example.com/m/v2",
+			versions:   []string{"v2.0.0", "v2.1.0", "v3.0.0"},
+			latest:     "v2.1.0",
+			majorPaths: map[string]string{"v3": "example.com/m/v3"},
+		},
+		{
+			path:       "github.com/BurntSushi/toml",
+			versions:   []string{"v1.3.0", "v1.3.2", "v1.4.0-rc.1"},
+			latest:     "v1.3.2",
+			majorPaths: map[string]string{},
+		},
+		{
+			path:       "gopkg.in/yaml.v2",
+			versions:   []string{"v2.4.0", "v3.0.1"},
+			latest:     "v2.4.0",
+			majorPaths: map[string]string{"v3": "gopkg.in/yaml.v3"},
+		},
+		{
+			path:       "example.com/pseudo",
+			versions:   []string{"v0.0.0-20240101000000-abcdefabcdef"},
+			latest:     "v0.0.0-20240101000000-abcdefabcdef",
+			majorPaths: map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		versions, latest, majorPaths, err := goProxyVersions(proxyURL, tt.path)
+		if err != nil {
+			t.Errorf("goProxyVersions(%s): %v", tt.path, err)
+			continue
+		}
+		if !reflect.DeepEqual(versions, tt.versions) || latest != tt.latest || !reflect.DeepEqual(majorPaths, tt.majorPaths) {
+			t.Errorf("goProxyVersions(%s) = %q, %q, %v; want %q, %q, %v", tt.path, versions, latest, majorPaths, tt.versions, tt.latest, tt.majorPaths)
+		}
+	}
+
+	if _, _, _, err := goProxyVersions(proxyURL, "example.com/unknown"); err != errNotFound {
+		t.Errorf("unknown module error = %v, want errNotFound", err)
+	}
+}
+
+func TestNPMVersions(t *testing.T) {
+	registry := t.TempDir()
+	writeFiles(t, registry, map[string]string{
+		"left-pad/index.json":   `{"dist-tags":{"latest":"1.3.0"},"versions":{"1.1.0":{},"1.3.0":{},"1.2.0":{}}}`,
+		"@scope/pkg/index.json": `{"dist-tags":{"latest":"2.0.0","next":"3.0.0-rc.1"},"versions":{"2.0.0":{},"3.0.0-rc.1":{}}}`,
+		"broken/index.json":     `not json`,
+	})
+	registryURL := "file://" + filepath.ToSlash(registry)
+
+	tests := []struct {
+		name     string
+		versions []string
+		latest   string
+		err      bool
+	}{
+		{name: "left-pad", versions: []string{"1.1.0", "1.2.0", "1.3.0"}, latest: "1.3.0"},
+		{name: "@scope/pkg", versions: []string{"2.0.0", "3.0.0-rc.1"}, latest: "2.0.0"},
+		{name: "broken", err: true},
+		{name: "missing", err: true},
+	}
+	for _, tt := range tests {
+		versions, latest, err := npmVersions(registryURL, tt.name)
+		if (err != nil) != tt.err {
+			t.Errorf("npmVersions(%s) error = %v, want error %v", tt.name, err, tt.err)
+			continue
+		}
+		if !reflect.DeepEqual(versions, tt.versions) || latest != tt.latest {
+			t.Errorf("npmVersions(%s) = %q, %q; want %q, %q", tt.name, versions, latest, tt.versions, tt.latest)
+		}
+	}
+}
+
+func TestOutdated(t *testing.T) {
+	t.Setenv("GOWORK", "off")
+	root, proxy, registry := t.TempDir(), t.TempDir(), t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.mod":       "module example.com/app\n\ngo 1.21\n\nrequire (\n\texample.com/m v1.0.0\n\texample.com/current v1.1.0\n\texample.com/dep v1.0.0 // indirect\n)\n",
+		"package.json": `{"name": "app", "dependencies": {"left-pad": "1.1.0", "missing": "1.0.0"}}`,
+	})
+	writeFiles(t, proxy, map[string]string{
+		"example.com/m/@v/list":       "v1.0.0\nv1.0.1\n",
+		"example.com/m/v2/@v/list":    "v2.0.0\n",
+		"example.com/current/@v/list": "v1.1.0\n",
+		"example.com/dep/@v/list":     "v1.0.0\nv9.0.0\n",
+	})
+	writeFiles(t, registry, map[string]string{
+		"left-pad/index.json": `{"dist-tags":{"latest":"1.3.0"},"versions":{"1.1.0":{},"1.2.0":{},"1.3.0":{}}}`,
+	})
+
+	a, err := New(config.ContextConfig{ProjectPaths: []string{root}, Tokenizer: "chars"}, config.CacheConfig{}, config.DependencyConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	outdated, err := a.Outdated(OutdatedOptions{
+		GoProxy:     "file://" + filepath.ToSlash(proxy),
+		NPMRegistry: "file://" + filepath.ToSlash(registry),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, o := range outdated {
+		got = append(got, fmt.Sprintf("%s %s->%s/%s/%s %s %s", o.Dependency.Name, o.Dependency.Version, o.Patch, o.Minor, o.Major, o.MajorPath, o.Kind))
+	}
+	want := []string{
+		"example.com/m v1.0.0->v1.0.1//v2.0.0 example.com/m/v2 major",
+		"left-pad 1.1.0->/1.3.0/  minor",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Outdated() =\n%q\nwant\n%q", got, want)
+	}
+
+	// Unknown modules are skipped, an unreachable registry is an error
+	if outdated, err := a.Outdated(OutdatedOptions{GoProxy: "file://" + filepath.ToSlash(t.TempDir())}); err != nil || len(outdated) != 0 {
+		t.Errorf("Outdated() with an empty proxy = %v, %v; want nothing", outdated, err)
+	}
+	if _, err := a.Outdated(OutdatedOptions{GoProxy: "http://127.0.0.1:1"}); err == nil {
+		t.Error("Outdated() with an unreachable proxy succeeded")
+	}
+}
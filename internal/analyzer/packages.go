@@ -0,0 +1,478 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// TypeIndex holds the type-checked packages of a Go module, or of all modules
+// of a go.work workspace, which are checked together
+type TypeIndex struct {
+	Fset       *token.FileSet
+	ModulePath string          // main module; empty for a workspace
+	ModuleDir  string          // module directory, or the directory of go.work
+	Modules    []string        // paths of all modules in the index
+	Workspace  string          // go.work file, if any
+	Packages   []*TypedPackage // project packages, sorted by import path
+	Missing    []string        // imports that could not be resolved offline
+	SyntaxOnly bool            // no module found; only syntax information is available
+}
+
+// TypedPackage is a type-checked package
+type TypedPackage struct {
+	Path     string
+	Dir      string
+	Name     string
+	Files    []*ast.File
+	Types    *types.Package
+	Info     *types.Info
+	Errors   []string
+	External bool
+}
+
+// Partial reports whether type checking completed with errors
+func (p *TypedPackage) Partial() bool {
+	return len(p.Errors) > 0
+}
+
+// Status describes how complete the type information is
+func (ti *TypeIndex) Status() string {
+	if ti.SyntaxOnly {
+		return "syntax-only (no go.mod found)"
+	}
+	if len(ti.Missing) > 0 {
+		return fmt.Sprintf("partial (%d unresolved imports)", len(ti.Missing))
+	}
+	for _, pkg := range ti.Packages {
+		if pkg.Partial() {
+			return "partial (type errors)"
+		}
+	}
+	return "complete"
+}
+
+// Name identifies the index in reports
+func (ti *TypeIndex) Name() string {
+	if ti.Workspace != "" {
+		return fmt.Sprintf("go.work (%s)", strings.Join(ti.Modules, ", "))
+	}
+	return ti.ModulePath
+}
+
+// TypeIndex loads (or returns the cached) type information for the module or
+// go.work workspace containing root
+func (a *ProjectAnalyzer) TypeIndex(root string) (*TypeIndex, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path: %w", err)
+	}
+
+	a.typesMu.Lock()
+	defer a.typesMu.Unlock()
+
+	indexDir, moduleDirs := "", []string{}
+	workFile := findWorkFile(absRoot)
+	if workFile != "" {
+		indexDir = filepath.Dir(workFile)
+		moduleDirs = readWorkspaceModules(workFile)
+	} else if moduleDir := findModuleRoot(absRoot); moduleDir != "" {
+		indexDir = moduleDir
+		moduleDirs = []string{moduleDir}
+	}
+
+	if indexDir == "" {
+		return &TypeIndex{Fset: a.typesFset, SyntaxOnly: true}, nil
+	}
+
+	if index, exists := a.types[indexDir]; exists {
+		return index, nil
+	}
+
+	index, err := a.loadTypeIndex(indexDir, moduleDirs, workFile)
+	if err != nil {
+		return nil, err
+	}
+
+	a.types[indexDir] = index
+	return index, nil
+}
+
+// typeIndexes returns the type information of every configured project path
+func (a *ProjectAnalyzer) typeIndexes() []*TypeIndex {
+	var indexes []*TypeIndex
+	seen := make(map[string]bool)
+
+	for _, root := range a.config.ProjectPaths {
+		index, err := a.TypeIndex(root)
+		if err != nil || index.SyntaxOnly || seen[index.ModuleDir] {
+			continue
+		}
+		seen[index.ModuleDir] = true
+		indexes = append(indexes, index)
+	}
+
+	return indexes
+}
+
+func (a *ProjectAnalyzer) loadTypeIndex(indexDir string, moduleDirs []string, workFile string) (*TypeIndex, error) {
+	modules := make(map[string]string) // module path -> dir
+	sources := make(map[string]string) // required module path -> source dir
+	modCache := moduleCacheDir()
+	var modulePaths []string
+
+	for _, moduleDir := range moduleDirs {
+		mf, err := readGoModFile(filepath.Join(moduleDir, "go.mod"))
+		if err != nil || mf.Module == "" {
+			continue
+		}
+		modules[mf.Module] = moduleDir
+		modulePaths = append(modulePaths, mf.Module)
+
+		for _, dep := range mf.Requires {
+			if _, exists := sources[dep.Name]; !exists {
+				sources[dep.Name] = moduleSourceDir(mf, dep, modCache)
+			}
+		}
+	}
+	if len(modules) == 0 {
+		return nil, fmt.Errorf("no module found in %s", indexDir)
+	}
+
+	// All indexes share one file set so positions resolve across modules
+	fset := a.typesFset
+	loader := &packageLoader{
+		fset:     fset,
+		modules:  modules,
+		sources:  sources,
+		ctxt:     build.Default,
+		packages: make(map[string]*TypedPackage),
+		missing:  make(map[string]bool),
+	}
+	if workFile == "" {
+		// Workspace mode ignores vendor directories, like the go command
+		loader.vendorDir = filepath.Join(moduleDirs[0], "vendor")
+	}
+	loader.std = importer.ForCompiler(fset, "source", nil).(types.ImporterFrom)
+
+	index := &TypeIndex{
+		Fset:      fset,
+		ModuleDir: indexDir,
+		Modules:   modulePaths,
+		Workspace: workFile,
+	}
+	if workFile == "" {
+		index.ModulePath = modulePaths[0]
+	}
+
+	for _, modulePath := range modulePaths {
+		a.loadModulePackages(loader, index, modulePath, modules[modulePath])
+	}
+
+	sort.Slice(index.Packages, func(i, j int) bool {
+		return index.Packages[i].Path < index.Packages[j].Path
+	})
+
+	for path := range loader.missing {
+		index.Missing = append(index.Missing, path)
+	}
+	sort.Strings(index.Missing)
+
+	return index, nil
+}
+
+// loadModulePackages type-checks every package of one module into index
+func (a *ProjectAnalyzer) loadModulePackages(loader *packageLoader, index *TypeIndex, modulePath, moduleDir string) {
+	for _, dir := range a.packageDirs(moduleDir) {
+		rel, _ := filepath.Rel(moduleDir, dir)
+		importPath := modulePath
+		if rel != "." {
+			importPath = modulePath + "/" + filepath.ToSlash(rel)
+		}
+
+		pkg, err := loader.load(importPath, dir, false)
+		if err != nil || pkg == nil {
+			continue
+		}
+		index.Packages = append(index.Packages, pkg)
+	}
+}
+
+// packageDirs lists directories of moduleDir containing Go files, excluding nested modules
+func (a *ProjectAnalyzer) packageDirs(moduleDir string) []string {
+	var dirs []string
+	ignore := a.newIgnoreMatcher(moduleDir)
+
+	filepath.WalkDir(moduleDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+
+		if path != moduleDir {
+			name := d.Name()
+			if name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+				return filepath.SkipDir
+			}
+			if ignore.Match(path, true) {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil {
+				return filepath.SkipDir
+			}
+		}
+
+		if hasGoFiles(path) {
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+
+	return dirs
+}
+
+// packageLoader type-checks packages from source, resolving imports offline
+type packageLoader struct {
+	fset      *token.FileSet
+	modules   map[string]string // project module path -> dir
+	vendorDir string            // empty in workspace mode
+	sources   map[string]string // required module path -> source dir
+	ctxt      build.Context
+	std       types.ImporterFrom
+	packages  map[string]*TypedPackage
+	missing   map[string]bool
+}
+
+// Import implements types.Importer
+func (l *packageLoader) Import(path string) (*types.Package, error) {
+	return l.ImportFrom(path, "", 0)
+}
+
+// ImportFrom implements types.ImporterFrom
+func (l *packageLoader) ImportFrom(path, srcDir string, mode types.ImportMode) (*types.Package, error) {
+	if path == "unsafe" {
+		return types.Unsafe, nil
+	}
+
+	if dir, external, ok := l.resolve(path); ok {
+		pkg, err := l.load(path, dir, external)
+		if err != nil {
+			return nil, err
+		}
+		if pkg.Types == nil {
+			return nil, fmt.Errorf("import cycle through %s", path)
+		}
+		return pkg.Types, nil
+	}
+
+	if isStdPackage(l.ctxt.GOROOT, path) {
+		return l.std.ImportFrom(path, srcDir, mode)
+	}
+
+	l.missing[path] = true
+	return nil, fmt.Errorf("package %s not available offline", path)
+}
+
+// resolve maps an import path to a source directory in the project modules,
+// vendor tree or module cache
+func (l *packageLoader) resolve(path string) (string, bool, bool) {
+	if module := longestModule(l.modules, path); module != "" {
+		dir := l.modules[module]
+		if rest := strings.TrimPrefix(path, module); rest != "" {
+			dir = filepath.Join(dir, filepath.FromSlash(strings.TrimPrefix(rest, "/")))
+		}
+		return dir, false, true
+	}
+
+	if l.vendorDir != "" {
+		vendorDir := filepath.Join(l.vendorDir, filepath.FromSlash(path))
+		if hasGoFiles(vendorDir) {
+			return vendorDir, true, true
+		}
+	}
+
+	best := longestModule(l.sources, path)
+	if best == "" || l.sources[best] == "" {
+		return "", false, false
+	}
+
+	dir := l.sources[best]
+	if rest := strings.TrimPrefix(path, best); rest != "" {
+		dir = filepath.Join(dir, filepath.FromSlash(strings.TrimPrefix(rest, "/")))
+	}
+	if !hasGoFiles(dir) {
+		return "", false, false
+	}
+
+	return dir, true, true
+}
+
+// load parses and type-checks the package in dir. Type errors are recorded,
+// not returned, so callers always get whatever information could be derived.
+func (l *packageLoader) load(importPath, dir string, external bool) (*TypedPackage, error) {
+	if pkg, exists := l.packages[importPath]; exists {
+		return pkg, nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	pkg := &TypedPackage{
+		Path:     importPath,
+		Dir:      dir,
+		External: external,
+	}
+	l.packages[importPath] = pkg
+
+	mode := parser.ParseComments
+	if external {
+		mode = parser.SkipObjectResolution
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		if ok, err := l.ctxt.MatchFile(dir, name); err != nil || !ok {
+			continue
+		}
+
+		file, err := parser.ParseFile(l.fset, filepath.Join(dir, name), nil, mode)
+		if file == nil {
+			continue
+		}
+		if err != nil {
+			pkg.Errors = append(pkg.Errors, err.Error())
+		}
+		if pkg.Name == "" {
+			pkg.Name = file.Name.Name
+		}
+		if file.Name.Name == pkg.Name {
+			pkg.Files = append(pkg.Files, file)
+		}
+	}
+
+	if len(pkg.Files) == 0 {
+		delete(l.packages, importPath)
+		return nil, fmt.Errorf("no Go files in %s", dir)
+	}
+
+	conf := types.Config{
+		Importer:    l,
+		FakeImportC: true,
+		Error: func(err error) {
+			pkg.Errors = append(pkg.Errors, err.Error())
+		},
+	}
+
+	var info *types.Info
+	if !external {
+		info = &types.Info{
+			Types:      make(map[ast.Expr]types.TypeAndValue),
+			Defs:       make(map[*ast.Ident]types.Object),
+			Uses:       make(map[*ast.Ident]types.Object),
+			Implicits:  make(map[ast.Node]types.Object),
+			Selections: make(map[*ast.SelectorExpr]*types.Selection),
+		}
+	}
+
+	typesPkg, _ := conf.Check(importPath, l.fset, pkg.Files, info)
+	pkg.Types = typesPkg
+	pkg.Info = info
+
+	// Syntax trees of dependencies are only needed while checking
+	if external {
+		pkg.Files = nil
+	}
+
+	return pkg, nil
+}
+
+// longestModule returns the longest module path in modules containing the package path
+func longestModule(modules map[string]string, path string) string {
+	best := ""
+	for module := range modules {
+		if (path == module || strings.HasPrefix(path, module+"/")) && len(module) > len(best) {
+			best = module
+		}
+	}
+	return best
+}
+
+// findModuleRoot walks up from dir to the nearest directory containing go.mod
+func findModuleRoot(dir string) string {
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// moduleCacheDir returns GOMODCACHE without invoking the go command
+func moduleCacheDir() string {
+	if dir := os.Getenv("GOMODCACHE"); dir != "" {
+		return dir
+	}
+	gopath := os.Getenv("GOPATH")
+	if gopath == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		gopath = filepath.Join(home, "go")
+	}
+	return filepath.Join(filepath.SplitList(gopath)[0], "pkg", "mod")
+}
+
+// escapeModulePath applies the module cache case encoding (Upper -> !upper)
+func escapeModulePath(path string) string {
+	var b strings.Builder
+	for _, r := range path {
+		if r >= 'A' && r <= 'Z' {
+			b.WriteByte('!')
+			b.WriteRune(r + ('a' - 'A'))
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func isStdPackage(goroot, path string) bool {
+	first := strings.SplitN(path, "/", 2)[0]
+	if strings.Contains(first, ".") {
+		return false
+	}
+	info, err := os.Stat(filepath.Join(goroot, "src", filepath.FromSlash(path)))
+	return err == nil && info.IsDir()
+}
+
+func hasGoFiles(dir string) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() && strings.HasSuffix(name, ".go") && !strings.HasSuffix(name, "_test.go") {
+			return true
+		}
+	}
+	return false
+}
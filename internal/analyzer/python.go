@@ -0,0 +1,475 @@
+package analyzer
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// pythonRequirementPattern matches a PEP 508 requirement: name, extras and
+// the rest (version specifiers or URL, then markers)
+var pythonRequirementPattern = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)\s*(?:\[([^\]]*)\])?\s*(.*)$`)
+
+var pythonNameSeparators = regexp.MustCompile(`[-_.]+`)
+
+// pyprojectTOML is the subset of pyproject.toml used for dependency analysis
+type pyprojectTOML struct {
+	Project struct {
+		Name                 string              `toml:"name"`
+		Dependencies         []string            `toml:"dependencies"`
+		OptionalDependencies map[string][]string `toml:"optional-dependencies"`
+	} `toml:"project"`
+	DependencyGroups map[string][]interface{} `toml:"dependency-groups"`
+	Tool             struct {
+		Poetry struct {
+			Name            string                 `toml:"name"`
+			Dependencies    map[string]interface{} `toml:"dependencies"`
+			DevDependencies map[string]interface{} `toml:"dev-dependencies"`
+			Group           map[string]struct {
+				Dependencies map[string]interface{} `toml:"dependencies"`
+			} `toml:"group"`
+		} `toml:"poetry"`
+	} `toml:"tool"`
+}
+
+// pipfileTOML is a Pipfile
+type pipfileTOML struct {
+	Packages    map[string]interface{} `toml:"packages"`
+	DevPackages map[string]interface{} `toml:"dev-packages"`
+}
+
+func init() {
+	mustRegisterDependencyParser(pythonParser{})
+}
+
+// pythonParser reads the Python manifests of a project: pyproject.toml
+// (PEP 621, PEP 735 groups and Poetry), Pipfile and requirements files.
+// Versions are pinned from poetry.lock or Pipfile.lock when present.
+type pythonParser struct{}
+
+func (pythonParser) Ecosystem() string { return "python" }
+
+func (pythonParser) Detect(root string) bool {
+	for _, name := range []string{"pyproject.toml", "Pipfile"} {
+		if _, err := os.Stat(filepath.Join(root, name)); err == nil {
+			return true
+		}
+	}
+	return len(requirementsFiles(root)) > 0
+}
+
+func (pythonParser) Parse(root string, includeTransitive bool) ([]Dependency, error) {
+	var declared []Dependency
+	module := filepath.Base(root)
+
+	pyproject := filepath.Join(root, "pyproject.toml")
+	if _, err := os.Stat(pyproject); err == nil {
+		var project pyprojectTOML
+		if _, err := toml.DecodeFile(pyproject, &project); err != nil {
+			return nil, err
+		}
+		if project.Project.Name != "" {
+			module = project.Project.Name
+		} else if project.Tool.Poetry.Name != "" {
+			module = project.Tool.Poetry.Name
+		}
+		declared = append(declared, pyprojectDependencies(pyproject, &project)...)
+	}
+
+	pipfile := filepath.Join(root, "Pipfile")
+	if _, err := os.Stat(pipfile); err == nil {
+		var pf pipfileTOML
+		if _, err := toml.DecodeFile(pipfile, &pf); err != nil {
+			return nil, err
+		}
+		declared = append(declared, poetryStyleDependencies(pipfile, pf.Packages, "direct")...)
+		declared = append(declared, poetryStyleDependencies(pipfile, pf.DevPackages, "dev")...)
+	}
+
+	// Production requirements come first so packages that development files
+	// include with -r keep their type
+	var devFiles []string
+	for _, file := range requirementsFiles(root) {
+		// requirements-dev.txt, requirements/test.txt, ...
+		rel, _ := filepath.Rel(root, file)
+		if name := strings.ToLower(rel); strings.Contains(name, "dev") || strings.Contains(name, "test") {
+			devFiles = append(devFiles, file)
+			continue
+		}
+		declared = append(declared, readRequirementsFile(file, "direct", make(map[string]bool))...)
+	}
+	for _, file := range devFiles {
+		declared = append(declared, readRequirementsFile(file, "dev", make(map[string]bool))...)
+	}
+
+	if len(declared) == 0 {
+		return nil, nil
+	}
+
+	lockFile, pins := readPythonLock(root)
+
+	// The first manifest declaring a package wins
+	var deps []Dependency
+	seen := make(map[string]bool)
+	for _, dep := range declared {
+		key := normalizePythonName(dep.Name)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		dep.Module = module
+		dep.Ecosystem = "python"
+		if version, ok := pins[key]; ok {
+			dep.Version = version
+		}
+		if dep.Version == "" {
+			dep.Version = dep.Constraint
+		}
+		if dep.Version == "" {
+			dep.Version = "*"
+		}
+		if dep.Constraint == dep.Version || dep.Constraint == "=="+dep.Version {
+			dep.Constraint = ""
+		}
+		deps = append(deps, dep)
+	}
+
+	// Remaining locked packages are transitive
+	if includeTransitive && lockFile != "" {
+		names := make([]string, 0, len(pins))
+		for name := range pins {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if seen[name] {
+				continue
+			}
+			deps = append(deps, Dependency{
+				Name:      name,
+				Version:   pins[name],
+				Type:      "indirect",
+				Path:      lockFile,
+				Module:    module,
+				Ecosystem: "python",
+			})
+		}
+	}
+
+	return deps, nil
+}
+
+// pyprojectDependencies lists PEP 621 dependencies and extras, PEP 735
+// dependency groups and Poetry dependencies and groups
+func pyprojectDependencies(path string, project *pyprojectTOML) []Dependency {
+	var deps []Dependency
+
+	for _, req := range project.Project.Dependencies {
+		if dep, ok := parsePythonRequirement(req); ok {
+			dep.Type = "direct"
+			dep.Path = path
+			deps = append(deps, dep)
+		}
+	}
+
+	// Extras of the project itself install optional dependencies
+	for _, extra := range sortedKeys(project.Project.OptionalDependencies) {
+		for _, req := range project.Project.OptionalDependencies[extra] {
+			if dep, ok := parsePythonRequirement(req); ok {
+				dep.Type = "optional"
+				dep.Path = path
+				dep.Markers = joinMarkers(`extra == "`+extra+`"`, dep.Markers)
+				deps = append(deps, dep)
+			}
+		}
+	}
+
+	for _, group := range sortedKeys(project.DependencyGroups) {
+		for _, entry := range project.DependencyGroups[group] {
+			// Tables are {include-group = "..."} references
+			req, ok := entry.(string)
+			if !ok {
+				continue
+			}
+			if dep, ok := parsePythonRequirement(req); ok {
+				dep.Type = "dev"
+				dep.Path = path
+				deps = append(deps, dep)
+			}
+		}
+	}
+
+	poetry := project.Tool.Poetry
+	deps = append(deps, poetryStyleDependencies(path, poetry.Dependencies, "direct")...)
+	deps = append(deps, poetryStyleDependencies(path, poetry.DevDependencies, "dev")...)
+	for _, group := range sortedKeys(poetry.Group) {
+		depType := "dev"
+		if group == "main" {
+			depType = "direct"
+		}
+		deps = append(deps, poetryStyleDependencies(path, poetry.Group[group].Dependencies, depType)...)
+	}
+
+	return deps
+}
+
+// poetryStyleDependencies reads a name -> specification table as used by
+// Poetry and Pipfile. A specification is a version string, a table with
+// version, extras, markers and optional keys, or a list of such tables.
+func poetryStyleDependencies(path string, table map[string]interface{}, depType string) []Dependency {
+	var deps []Dependency
+
+	for _, name := range sortedKeys(table) {
+		if strings.EqualFold(name, "python") {
+			continue
+		}
+		dep := Dependency{Name: name, Type: depType, Path: path}
+
+		// Multiple-constraint dependencies list one table per environment
+		spec := table[name]
+		switch list := spec.(type) {
+		case []map[string]interface{}:
+			if len(list) > 0 {
+				spec = list[0]
+			}
+		case []interface{}:
+			if len(list) > 0 {
+				spec = list[0]
+			}
+		}
+		switch v := spec.(type) {
+		case string:
+			dep.Constraint = v
+		case map[string]interface{}:
+			if version, ok := v["version"].(string); ok {
+				dep.Constraint = version
+			} else if git, ok := v["git"].(string); ok {
+				dep.Constraint = git
+			} else if dir, ok := v["path"].(string); ok {
+				dep.Constraint = dir
+			}
+			if extras, ok := v["extras"].([]interface{}); ok {
+				for _, extra := range extras {
+					if s, ok := extra.(string); ok {
+						dep.Extras = append(dep.Extras, s)
+					}
+				}
+			}
+			if markers, ok := v["markers"].(string); ok {
+				dep.Markers = markers
+			}
+			if optional, ok := v["optional"].(bool); ok && optional && depType == "direct" {
+				dep.Type = "optional"
+			}
+		}
+		if dep.Constraint == "*" {
+			dep.Constraint = ""
+		}
+		dep.Version = pinnedPythonVersion(dep.Constraint)
+		deps = append(deps, dep)
+	}
+
+	return deps
+}
+
+// requirementsFiles returns requirements*.txt files in root and the
+// requirements/ directory
+func requirementsFiles(root string) []string {
+	var files []string
+	for _, pattern := range []string{"requirements*.txt", "*-requirements.txt", filepath.Join("requirements", "*.txt")} {
+		matches, _ := filepath.Glob(filepath.Join(root, pattern))
+		files = append(files, matches...)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// readRequirementsFile parses a pip requirements file, following -r includes
+func readRequirementsFile(path, depType string, visited map[string]bool) []Dependency {
+	if visited[path] {
+		return nil
+	}
+	visited[path] = true
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var deps []Dependency
+	var pending string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasSuffix(line, "\\") {
+			pending += strings.TrimSuffix(line, "\\") + " "
+			continue
+		}
+		line = pending + line
+		pending = ""
+
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "-r ") || strings.HasPrefix(line, "--requirement") {
+			include := strings.TrimSpace(strings.TrimLeft(strings.TrimPrefix(strings.TrimPrefix(line, "-r"), "--requirement"), " ="))
+			if !filepath.IsAbs(include) {
+				include = filepath.Join(filepath.Dir(path), include)
+			}
+			deps = append(deps, readRequirementsFile(include, depType, visited)...)
+			continue
+		}
+		// Other options (-c, -e, --index-url, --hash, ...) do not name packages
+		if strings.HasPrefix(line, "-") {
+			continue
+		}
+
+		if dep, ok := parsePythonRequirement(line); ok {
+			dep.Type = depType
+			dep.Path = path
+			deps = append(deps, dep)
+		}
+	}
+
+	return deps
+}
+
+// parsePythonRequirement parses a PEP 508 requirement string such as
+// `requests[security]>=2.8,<3; python_version >= "3.8"`
+func parsePythonRequirement(req string) (Dependency, bool) {
+	req = strings.TrimSpace(req)
+
+	// Per-requirement pip options such as --hash follow the requirement
+	if i := strings.Index(req, " --"); i >= 0 {
+		req = req[:i]
+	}
+
+	match := pythonRequirementPattern.FindStringSubmatch(req)
+	if match == nil {
+		return Dependency{}, false
+	}
+
+	dep := Dependency{Name: match[1]}
+	if match[2] != "" {
+		for _, extra := range strings.Split(match[2], ",") {
+			if extra = strings.TrimSpace(extra); extra != "" {
+				dep.Extras = append(dep.Extras, extra)
+			}
+		}
+	}
+
+	rest := match[3]
+	if strings.HasPrefix(rest, "@") {
+		// Direct references: name @ url ; markers (the URL may contain ';')
+		rest = strings.TrimSpace(rest[1:])
+		if i := strings.Index(rest, " ;"); i >= 0 {
+			dep.Markers = strings.TrimSpace(rest[i+2:])
+			rest = rest[:i]
+		}
+		dep.Constraint = strings.TrimSpace(rest)
+		return dep, true
+	}
+
+	if i := strings.Index(rest, ";"); i >= 0 {
+		dep.Markers = strings.TrimSpace(rest[i+1:])
+		rest = rest[:i]
+	}
+	constraint := strings.TrimSpace(rest)
+	constraint = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(constraint, "("), ")"))
+	dep.Constraint = strings.ReplaceAll(constraint, " ", "")
+	dep.Version = pinnedPythonVersion(dep.Constraint)
+
+	return dep, true
+}
+
+// pinnedPythonVersion returns the version of an exact `==` or `===` pin
+func pinnedPythonVersion(constraint string) string {
+	if strings.Contains(constraint, ",") || strings.Contains(constraint, "*") {
+		return ""
+	}
+	for _, op := range []string{"===", "=="} {
+		if strings.HasPrefix(constraint, op) {
+			return strings.TrimSpace(constraint[len(op):])
+		}
+	}
+	return ""
+}
+
+// readPythonLock returns the pinned versions of poetry.lock or Pipfile.lock,
+// keyed by normalized package name
+func readPythonLock(root string) (string, map[string]string) {
+	poetryLock := filepath.Join(root, "poetry.lock")
+	if _, err := os.Stat(poetryLock); err == nil {
+		var lock struct {
+			Package []struct {
+				Name    string `toml:"name"`
+				Version string `toml:"version"`
+			} `toml:"package"`
+		}
+		if _, err := toml.DecodeFile(poetryLock, &lock); err == nil {
+			pins := make(map[string]string)
+			for _, pkg := range lock.Package {
+				pins[normalizePythonName(pkg.Name)] = pkg.Version
+			}
+			return poetryLock, pins
+		}
+	}
+
+	pipfileLock := filepath.Join(root, "Pipfile.lock")
+	if data, err := os.ReadFile(pipfileLock); err == nil {
+		var lock map[string]json.RawMessage
+		if err := json.Unmarshal(data, &lock); err == nil {
+			pins := make(map[string]string)
+			for _, section := range []string{"default", "develop"} {
+				var packages map[string]struct {
+					Version string `json:"version"`
+				}
+				if json.Unmarshal(lock[section], &packages) != nil {
+					continue
+				}
+				for name, pkg := range packages {
+					if version := strings.TrimPrefix(pkg.Version, "=="); version != "" {
+						pins[normalizePythonName(name)] = version
+					}
+				}
+			}
+			return pipfileLock, pins
+		}
+	}
+
+	return "", nil
+}
+
+// normalizePythonName normalizes a package name as in PEP 503
+func normalizePythonName(name string) string {
+	return pythonNameSeparators.ReplaceAllString(strings.ToLower(name), "-")
+}
+
+func joinMarkers(a, b string) string {
+	if b == "" {
+		return a
+	}
+	return a + " and (" + b + ")"
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,172 @@
+package analyzer
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParsePythonRequirement(t *testing.T) {
+	tests := []struct {
+		req  string
+		want Dependency
+		ok   bool
+	}{
+		{"requests", Dependency{Name: "requests"}, true},
+		{"Django==4.2.7", Dependency{Name: "Django", Version: "4.2.7", Constraint: "==4.2.7"}, true},
+		{"numpy >= 1.24, < 2", Dependency{Name: "numpy", Constraint: ">=1.24,<2"}, true},
+		{"pkg===1.0", Dependency{Name: "pkg", Version: "1.0", Constraint: "===1.0"}, true},
+		{"pkg==1.*", Dependency{Name: "pkg", Constraint: "==1.*"}, true},
+		{"pkg (>=1.0)", Dependency{Name: "pkg", Constraint: ">=1.0"}, true},
+		{
+			`requests[security, socks]>=2.8; python_version >= "3.8"`,
+			Dependency{Name: "requests", Constraint: ">=2.8", Extras: []string{"security", "socks"}, Markers: `python_version >= "3.8"`},
+			true,
+		},
+		{
+			"pip @ https://example.com/pip.zip;v=1 ; sys_platform == 'linux'",
+			Dependency{Name: "pip", Constraint: "https://example.com/pip.zip;v=1", Markers: "sys_platform == 'linux'"},
+			true,
+		},
+		{"flask==3.0.0 --hash=sha256:abc", Dependency{Name: "flask", Version: "3.0.0", Constraint: "==3.0.0"}, true},
+		{"", Dependency{}, false},
+		{"./local/path", Dependency{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := parsePythonRequirement(tt.req)
+		if ok != tt.ok || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parsePythonRequirement(%q) = %+v, %v; want %+v, %v", tt.req, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestPythonParser(t *testing.T) {
+	tests := []struct {
+		name       string
+		files      map[string]string
+		transitive bool
+		want       []string
+	}{
+		{
+			name: "requirements files with includes",
+			files: map[string]string{
+				"requirements.txt":      "# pinned\nDjango==4.2.7\n-r requirements/base.txt\n--index-url https://pypi.org/simple\nrequests>=2.0 \\\n    ; python_version >= \"3.8\"\n",
+				"requirements/base.txt": "celery\n",
+				"requirements-dev.txt":  "-r requirements.txt\npytest~=7.4  # tests\n",
+			},
+			want: []string{
+				"Django@4.2.7 direct %ROOT% requirements.txt",
+				"celery@* direct %ROOT% requirements/base.txt",
+				`requests@>=2.0 direct %ROOT% requirements.txt ; python_version >= "3.8"`,
+				"pytest@~=7.4 dev %ROOT% requirements-dev.txt",
+			},
+		},
+		{
+			name: "pyproject PEP 621 with groups and poetry.lock",
+			files: map[string]string{
+				"pyproject.toml": `[project]
+name = "svc"
+dependencies = ["httpx>=0.25", "pydantic[email]==2.5.0"]
+
+[project.optional-dependencies]
+cli = ["rich; os_name != 'nt'"]
+
+[dependency-groups]
+test = ["pytest", {include-group = "lint"}]
+lint = ["ruff"]
+`,
+				"poetry.lock": `[[package]]
+name = "httpx"
+version = "0.25.2"
+
+[[package]]
+name = "Pydantic"
+version = "2.5.0"
+
+[[package]]
+name = "anyio"
+version = "4.1.0"
+`,
+			},
+			transitive: true,
+			want: []string{
+				"httpx@0.25.2 direct svc pyproject.toml [>=0.25]",
+				"pydantic@2.5.0 direct svc pyproject.toml extras=[email]",
+				`rich@* optional svc pyproject.toml ; extra == "cli" and (os_name != 'nt')`,
+				"ruff@* dev svc pyproject.toml",
+				"pytest@* dev svc pyproject.toml",
+				"anyio@4.1.0 indirect svc poetry.lock",
+			},
+		},
+		{
+			name: "poetry tables",
+			files: map[string]string{
+				"pyproject.toml": `[tool.poetry]
+name = "legacy"
+
+[tool.poetry.dependencies]
+python = "^3.10"
+fastapi = "^0.104"
+uvicorn = {version = "0.24.0", extras = ["standard"]}
+boto3 = {version = "*", optional = true}
+numpy = [
+  {version = "1.24", markers = "python_version < '3.9'"},
+  {version = "^1.26", markers = "python_version >= '3.9'"},
+]
+
+[tool.poetry.group.dev.dependencies]
+black = "23.11.0"
+`,
+			},
+			want: []string{
+				"boto3@* optional legacy pyproject.toml",
+				"fastapi@^0.104 direct legacy pyproject.toml",
+				"numpy@1.24 direct legacy pyproject.toml ; python_version < '3.9'",
+				"uvicorn@0.24.0 direct legacy pyproject.toml extras=[standard]",
+				"black@23.11.0 dev legacy pyproject.toml",
+			},
+		},
+		{
+			name: "Pipfile with Pipfile.lock",
+			files: map[string]string{
+				"Pipfile": `[packages]
+requests = "*"
+flask = {version = ">=2.0"}
+
+[dev-packages]
+pytest = "*"
+`,
+				"Pipfile.lock": `{"default": {"requests": {"version": "==2.31.0"}, "flask": {"version": "==3.0.0"}, "idna": {"version": "==3.6"}},
+ "develop": {"pytest": {"version": "==7.4.3"}}}`,
+			},
+			transitive: true,
+			want: []string{
+				"flask@3.0.0 direct %ROOT% Pipfile [>=2.0]",
+				"requests@2.31.0 direct %ROOT% Pipfile",
+				"pytest@7.4.3 dev %ROOT% Pipfile",
+				"idna@3.6 indirect %ROOT% Pipfile.lock",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeFiles(t, root, tt.files)
+			if !(pythonParser{}).Detect(root) {
+				t.Fatal("Detect() = false")
+			}
+			deps, err := pythonParser{}.Parse(root, tt.transitive)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := make([]string, len(tt.want))
+			for i, w := range tt.want {
+				want[i] = replaceRoot(w, filepath.Base(root))
+			}
+			if got := depStrings(root, deps); !reflect.DeepEqual(got, want) {
+				t.Errorf("Parse() =\n%q\nwant\n%q", got, want)
+			}
+		})
+	}
+}
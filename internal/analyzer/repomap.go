@@ -0,0 +1,293 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// mappedSymbol is an exported declaration listed in the repository map
+type mappedSymbol struct {
+	Symbol
+	line string // one-line signature
+	refs int
+	cost int // tokens of its line
+}
+
+// RepoMap renders an outline of the project's Go packages: the exported
+// types, functions and methods of each file by signature, without bodies.
+// When the outline exceeds maxTokens, the most referenced symbols are kept.
+func (a *ProjectAnalyzer) RepoMap(maxTokens int) (string, error) {
+	a.ensureIndexed()
+
+	var symbols []*mappedSymbol
+	exportedTypes := make(map[string]bool) // dir + "\x00" + name
+	for _, file := range a.goFiles() {
+		for _, sym := range file.Symbols {
+			if sym.Exported && (sym.Kind == "struct" || sym.Kind == "interface" || sym.Kind == "type") {
+				exportedTypes[filepath.Dir(sym.Path)+"\x00"+sym.Name] = true
+			}
+		}
+	}
+	for _, file := range a.goFiles() {
+		if strings.HasSuffix(file.Path, "_test.go") {
+			continue
+		}
+		for _, sym := range file.Symbols {
+			if !sym.Exported {
+				continue
+			}
+			switch {
+			case sym.Kind == "func", sym.Kind == "struct", sym.Kind == "interface", sym.Kind == "type":
+			case sym.Kind == "method" && exportedTypes[filepath.Dir(sym.Path)+"\x00"+sym.Receiver]:
+			default:
+				continue
+			}
+			symbols = append(symbols, &mappedSymbol{Symbol: sym, line: outlineSignature(sym)})
+		}
+	}
+	if len(symbols) == 0 {
+		return "", fmt.Errorf("no exported Go declarations found in the project")
+	}
+
+	counts := a.referenceCounts(symbols)
+	for _, sym := range symbols {
+		sym.refs = counts[sym]
+		sym.cost = a.tokenizer.Count(repoMapLine(sym)) + 1
+	}
+
+	// Most referenced first; types before their methods on ties
+	ranked := append([]*mappedSymbol(nil), symbols...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].refs != ranked[j].refs {
+			return ranked[i].refs > ranked[j].refs
+		}
+		if ri, rj := ranked[i].Kind == "method", ranked[j].Kind == "method"; ri != rj {
+			return rj
+		}
+		if ranked[i].Path != ranked[j].Path {
+			return ranked[i].Path < ranked[j].Path
+		}
+		return ranked[i].StartLine < ranked[j].StartLine
+	})
+
+	// Take symbols by rank while they fit, counting the package and file
+	// headings they bring in, then drop the last ones if the estimate was low
+	headerCost := a.tokenizer.Count(repoMapHeader(len(symbols), len(symbols))) + 8
+	used := headerCost
+	shownDirs := make(map[string]bool)
+	shownFiles := make(map[string]bool)
+	var kept []*mappedSymbol
+	for _, sym := range ranked {
+		cost := sym.cost
+		dir := filepath.Dir(sym.Path)
+		if !shownDirs[dir] {
+			cost += a.tokenizer.Count(a.packageHeading(sym.Symbol)) + 1
+		}
+		if !shownFiles[sym.Path] {
+			cost += a.tokenizer.Count(filepath.Base(sym.Path)) + 2
+		}
+		if used+cost > maxTokens {
+			continue
+		}
+		used += cost
+		shownDirs[dir], shownFiles[sym.Path] = true, true
+		kept = append(kept, sym)
+	}
+	for {
+		result := a.renderRepoMap(kept, len(symbols))
+		if a.tokenizer.Count(result) <= maxTokens || len(kept) == 0 {
+			return result, nil
+		}
+		kept = kept[:len(kept)-1]
+	}
+}
+
+// renderRepoMap writes symbols grouped by package directory and file, in
+// source order
+func (a *ProjectAnalyzer) renderRepoMap(symbols []*mappedSymbol, total int) string {
+	sorted := append([]*mappedSymbol(nil), symbols...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		di, dj := filepath.Dir(sorted[i].Path), filepath.Dir(sorted[j].Path)
+		if di != dj {
+			return di < dj
+		}
+		if sorted[i].Path != sorted[j].Path {
+			return sorted[i].Path < sorted[j].Path
+		}
+		return sorted[i].StartLine < sorted[j].StartLine
+	})
+
+	var result strings.Builder
+	result.WriteString(repoMapHeader(len(symbols), total))
+	result.WriteString("```text\n")
+	dir, path := "", ""
+	for _, sym := range sorted {
+		if d := filepath.Dir(sym.Path); d != dir {
+			dir = d
+			result.WriteString(a.packageHeading(sym.Symbol) + "\n")
+		}
+		if sym.Path != path {
+			path = sym.Path
+			result.WriteString("  " + filepath.Base(path) + "\n")
+		}
+		result.WriteString(repoMapLine(sym) + "\n")
+	}
+	result.WriteString("```\n")
+	return result.String()
+}
+
+func repoMapHeader(shown, total int) string {
+	return fmt.Sprintf("# Repository Map\n\n%d of %d exported Go declarations, most referenced first when cut to the token budget.\n\n", shown, total)
+}
+
+// packageHeading names a package directory relative to its project root
+func (a *ProjectAnalyzer) packageHeading(sym Symbol) string {
+	dir := a.displayPath(filepath.Dir(sym.Path))
+	if dir == "." {
+		dir = "./"
+	}
+	return fmt.Sprintf("%s (package %s)", dir, sym.Package)
+}
+
+func repoMapLine(sym *mappedSymbol) string {
+	line := "    " + sym.line
+	if sym.refs > 0 {
+		line += fmt.Sprintf("  // %d refs", sym.refs)
+	}
+	return line
+}
+
+// outlineSignature renders a declaration on one line: functions with their
+// parameters and results, types without their fields or methods
+func outlineSignature(sym Symbol) string {
+	switch sym.Kind {
+	case "struct":
+		return "type " + sym.Name + " struct"
+	case "interface":
+		return "type " + sym.Name + " interface"
+	}
+	sig := strings.Join(strings.Fields(sym.Signature), " ")
+	if sym.Kind == "type" {
+		if brace := strings.Index(sig, "{"); brace >= 0 {
+			sig = strings.TrimSpace(sig[:brace]) + " {...}"
+		}
+	}
+	return sig
+}
+
+// referenceCounts counts the uses of each symbol: resolved through type
+// information in packages that type-check, by matching identifiers elsewhere
+func (a *ProjectAnalyzer) referenceCounts(symbols []*mappedSymbol) map[*mappedSymbol]int {
+	counts := make(map[*mappedSymbol]int)
+	typedDirs := make(map[string]bool)
+
+	// Typed: map each declared object to its symbol, then count its uses
+	byName := make(map[string][]*mappedSymbol) // path + "\x00" + name
+	for _, sym := range symbols {
+		key := sym.Path + "\x00" + sym.Name
+		byName[key] = append(byName[key], sym)
+	}
+	indexes := a.typeIndexes()
+	objects := make(map[types.Object]*mappedSymbol)
+	for _, index := range indexes {
+		for _, pkg := range index.Packages {
+			if pkg.Info == nil || pkg.External {
+				continue
+			}
+			for ident, obj := range pkg.Info.Defs {
+				if obj == nil || !declaresTopLevel(obj) {
+					continue
+				}
+				p := index.Fset.Position(ident.Pos())
+				for _, sym := range byName[p.Filename+"\x00"+ident.Name] {
+					if p.Line >= sym.StartLine && p.Line <= sym.EndLine {
+						objects[obj] = sym
+						typedDirs[filepath.Dir(sym.Path)] = true
+					}
+				}
+			}
+		}
+	}
+	for _, index := range indexes {
+		for _, pkg := range index.Packages {
+			if pkg.Info == nil {
+				continue
+			}
+			for _, obj := range pkg.Info.Uses {
+				if sym, ok := objects[obj]; ok {
+					counts[sym]++
+				}
+			}
+		}
+	}
+
+	// Syntactic: bare names within a package, pkg.Name across packages and
+	// any selector for methods
+	var untyped []*mappedSymbol
+	for _, sym := range symbols {
+		if !typedDirs[filepath.Dir(sym.Path)] {
+			untyped = append(untyped, sym)
+		}
+	}
+	if len(untyped) == 0 {
+		return counts
+	}
+
+	bare := make(map[string]int)      // dir + "\x00" + name
+	qualified := make(map[string]int) // package name + "." + name
+	selectors := make(map[string]int) // name
+	for _, file := range a.goFiles() {
+		content, err := os.ReadFile(file.Path)
+		if err != nil {
+			continue
+		}
+		node, _ := parser.ParseFile(token.NewFileSet(), file.Path, content, 0)
+		if node == nil {
+			continue
+		}
+		dir := filepath.Dir(file.Path)
+		selected := make(map[*ast.Ident]bool)
+		ast.Inspect(node, func(n ast.Node) bool {
+			switch x := n.(type) {
+			case *ast.SelectorExpr:
+				selected[x.Sel] = true
+				selectors[x.Sel.Name]++
+				if pkg, ok := x.X.(*ast.Ident); ok {
+					qualified[pkg.Name+"."+x.Sel.Name]++
+				}
+			case *ast.Ident:
+				if !selected[x] {
+					bare[dir+"\x00"+x.Name]++
+				}
+			}
+			return true
+		})
+	}
+	for _, sym := range untyped {
+		if sym.Kind == "method" {
+			counts[sym] = selectors[sym.Name]
+			continue
+		}
+		// The declaring identifier is not a use
+		counts[sym] = max(0, bare[filepath.Dir(sym.Path)+"\x00"+sym.Name]-1) + qualified[sym.Package+"."+sym.Name]
+	}
+	return counts
+}
+
+// declaresTopLevel reports whether obj is a package-level declaration or a
+// method
+func declaresTopLevel(obj types.Object) bool {
+	if fn, ok := obj.(*types.Func); ok {
+		if sig, ok := fn.Type().(*types.Signature); ok && sig.Recv() != nil {
+			return true
+		}
+	}
+	return obj.Pkg() != nil && obj.Parent() == obj.Pkg().Scope()
+}
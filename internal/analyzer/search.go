@@ -0,0 +1,362 @@
+package analyzer
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// BM25 parameters: term frequency saturation and length normalization
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// nameBoost is the term frequency added for terms of a file's path and
+// declared symbols, which say more about a file than its body
+const nameBoost = 3
+
+// snippetContext is the number of lines shown around a matching line
+const snippetContext = 4
+
+// maxSnippetWindows bounds the separate regions shown per file
+const maxSnippetWindows = 3
+
+// searchIndex is an inverted index over the contents of the cached files,
+// ranked with BM25. It is brought up to date with the file cache lazily,
+// before each query.
+type searchIndex struct {
+	mu          sync.Mutex
+	docs        map[string]*indexedDoc
+	postings    map[string]map[string]int // term -> path -> frequency
+	totalLength int
+}
+
+// indexedDoc records what a file contributed to the index
+type indexedDoc struct {
+	modified int64
+	size     int64
+	length   int // number of terms
+	terms    map[string]int
+}
+
+// searchHit is a file matching a query with its best matching lines
+type searchHit struct {
+	Path      string
+	Score     float64
+	StartLine int // first line of the snippet, 1-based
+	Snippet   string
+	chunk     *fileChunk // most similar chunk, when vector search found the file
+}
+
+func newSearchIndex() *searchIndex {
+	return &searchIndex{
+		docs:     make(map[string]*indexedDoc),
+		postings: make(map[string]map[string]int),
+	}
+}
+
+// sync indexes new and changed files and drops files no longer cached
+func (idx *searchIndex) sync(files []*FileInfo) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	live := make(map[string]bool, len(files))
+	for _, file := range files {
+		live[file.Path] = true
+		if doc, ok := idx.docs[file.Path]; ok && doc.modified == file.LastModified && doc.size == file.Size {
+			continue
+		}
+		idx.remove(file.Path)
+		idx.add(file)
+	}
+	for path := range idx.docs {
+		if !live[path] {
+			idx.remove(path)
+		}
+	}
+}
+
+func (idx *searchIndex) add(file *FileInfo) {
+	doc := &indexedDoc{modified: file.LastModified, size: file.Size, terms: make(map[string]int)}
+
+	if content, err := os.ReadFile(file.Path); err == nil && bytes.IndexByte(content, 0) < 0 {
+		for _, term := range searchTerms(string(content)) {
+			doc.terms[term]++
+		}
+	}
+	names := []string{strings.TrimSuffix(filepath.Base(file.Path), filepath.Ext(file.Path)), filepath.Base(filepath.Dir(file.Path))}
+	for _, sym := range file.Symbols {
+		names = append(names, sym.QualifiedName())
+	}
+	for _, term := range searchTerms(strings.Join(names, " ")) {
+		doc.terms[term] += nameBoost
+	}
+
+	for term, freq := range doc.terms {
+		doc.length += freq
+		if idx.postings[term] == nil {
+			idx.postings[term] = make(map[string]int)
+		}
+		idx.postings[term][file.Path] = freq
+	}
+	idx.docs[file.Path] = doc
+	idx.totalLength += doc.length
+}
+
+func (idx *searchIndex) remove(path string) {
+	doc, ok := idx.docs[path]
+	if !ok {
+		return
+	}
+	for term := range doc.terms {
+		delete(idx.postings[term], path)
+		if len(idx.postings[term]) == 0 {
+			delete(idx.postings, term)
+		}
+	}
+	idx.totalLength -= doc.length
+	delete(idx.docs, path)
+}
+
+// Search ranks the indexed files for query by BM25 and returns up to limit
+// hits, without snippets; limit <= 0 returns all matches
+func (idx *searchIndex) Search(query string, limit int) []searchHit {
+	terms := uniqueStrings(searchTerms(query))
+
+	idx.mu.Lock()
+	scores := make(map[string]float64)
+	if n := len(idx.docs); n > 0 {
+		avgLength := float64(idx.totalLength) / float64(n)
+		for _, term := range terms {
+			postings := idx.postings[term]
+			if len(postings) == 0 {
+				continue
+			}
+			df := float64(len(postings))
+			idf := math.Log(1 + (float64(n)-df+0.5)/(df+0.5))
+			for path, freq := range postings {
+				tf := float64(freq)
+				norm := 1 - bm25B + bm25B*float64(idx.docs[path].length)/avgLength
+				scores[path] += idf * tf * (bm25K1 + 1) / (tf + bm25K1*norm)
+			}
+		}
+	}
+	idx.mu.Unlock()
+
+	hits := make([]searchHit, 0, len(scores))
+	for path, score := range scores {
+		hits = append(hits, searchHit{Path: path, Score: score})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].Path < hits[j].Path
+	})
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits
+}
+
+// addSnippets fills in the snippets of hits: the regions densest in query
+// terms or, when no line contains one, the most similar chunk
+func addSnippets(hits []searchHit, query string) {
+	terms := uniqueStrings(searchTerms(query))
+	for i := range hits {
+		content, err := os.ReadFile(hits[i].Path)
+		if err != nil {
+			continue
+		}
+		start, snippet, matched := extractSnippet(string(content), terms)
+		if !matched && hits[i].chunk != nil {
+			lines := strings.Split(string(content), "\n")
+			if c := hits[i].chunk; c.EndLine <= len(lines) {
+				start, snippet = c.StartLine, strings.Join(lines[c.StartLine-1:c.EndLine], "\n")
+			}
+		}
+		hits[i].StartLine, hits[i].Snippet = start, snippet
+	}
+}
+
+// extractSnippet returns the regions of content densest in query terms,
+// each with a few lines of context, joined by "... (line N)" markers. It
+// falls back to the start of the file when no line matches.
+func extractSnippet(content string, terms []string) (int, string, bool) {
+	lines := strings.Split(content, "\n")
+	wanted := make(map[string]bool, len(terms))
+	for _, term := range terms {
+		wanted[term] = true
+	}
+
+	// Score each line by the distinct query terms it contains
+	type scored struct{ line, score int }
+	var matches []scored
+	for i, line := range lines {
+		seen := make(map[string]bool)
+		for _, term := range searchTerms(line) {
+			if wanted[term] {
+				seen[term] = true
+			}
+		}
+		if len(seen) > 0 {
+			matches = append(matches, scored{i, len(seen)})
+		}
+	}
+	if len(matches) == 0 {
+		end := min(len(lines), 2*snippetContext+1)
+		return 1, strings.Join(lines[:end], "\n"), false
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+
+	// Take the best lines whose windows do not overlap a chosen one
+	var windows [][2]int
+	for _, m := range matches {
+		start, end := max(0, m.line-snippetContext), min(len(lines), m.line+snippetContext+1)
+		overlaps := false
+		for _, w := range windows {
+			if start < w[1] && end > w[0] {
+				overlaps = true
+				break
+			}
+		}
+		if !overlaps {
+			windows = append(windows, [2]int{start, end})
+			if len(windows) == maxSnippetWindows {
+				break
+			}
+		}
+	}
+	sort.Slice(windows, func(i, j int) bool { return windows[i][0] < windows[j][0] })
+
+	var snippet strings.Builder
+	for i, w := range windows {
+		if i > 0 {
+			snippet.WriteString(fmt.Sprintf("\n... (line %d)\n", w[0]+1))
+		}
+		snippet.WriteString(strings.Join(lines[w[0]:w[1]], "\n"))
+	}
+	return windows[0][0] + 1, snippet.String(), true
+}
+
+// searchTerms tokenizes text into lowercase terms. Identifiers also yield
+// their camelCase and snake_case parts, so "parseGoMod" matches "parse",
+// "go", "mod" and "parsegomod".
+func searchTerms(text string) []string {
+	var terms []string
+	word := []rune{}
+	flush := func() {
+		if len(word) == 0 {
+			return
+		}
+		parts := identifierParts(word)
+		if len(parts) > 1 {
+			if whole := strings.ToLower(strings.ReplaceAll(string(word), "_", "")); len(whole) > 1 {
+				terms = append(terms, whole)
+			}
+		}
+		for _, part := range parts {
+			if len(part) > 1 {
+				terms = append(terms, part)
+			}
+		}
+		word = word[:0]
+	}
+	for _, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			word = append(word, r)
+		} else {
+			flush()
+		}
+	}
+	flush()
+	return terms
+}
+
+// identifierParts splits an identifier at underscores and case changes,
+// keeping acronyms together: HTTPServer -> http, server
+func identifierParts(word []rune) []string {
+	var parts []string
+	start := 0
+	emit := func(end int) {
+		if end > start {
+			parts = append(parts, strings.ToLower(string(word[start:end])))
+		}
+		start = end
+	}
+	for i := 0; i < len(word); i++ {
+		r := word[i]
+		switch {
+		case r == '_':
+			emit(i)
+			start = i + 1
+		case i > start && unicode.IsUpper(r):
+			prev := word[i-1]
+			nextLower := i+1 < len(word) && unicode.IsLower(word[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				emit(i)
+			}
+		}
+	}
+	emit(len(word))
+	return parts
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	var unique []string
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
+
+// blendHits merges BM25 and vector hits into one ranking. BM25 scores are
+// scaled to the best one so both lie in [0, 1]; weight is the share of the
+// vector similarity.
+func blendHits(lexical []searchHit, semantic []vectorHit, weight float64) []searchHit {
+	weight = math.Min(1, math.Max(0, weight))
+	best := 0.0
+	for _, hit := range lexical {
+		best = math.Max(best, hit.Score)
+	}
+
+	blended := make(map[string]*searchHit)
+	var order []string
+	for _, hit := range lexical {
+		h := hit
+		if best > 0 {
+			h.Score = (1 - weight) * hit.Score / best
+		}
+		blended[hit.Path] = &h
+		order = append(order, hit.Path)
+	}
+	for _, hit := range semantic {
+		h, ok := blended[hit.Path]
+		if !ok {
+			h = &searchHit{Path: hit.Path}
+			blended[hit.Path] = h
+			order = append(order, hit.Path)
+		}
+		h.Score += weight * hit.Score
+		chunk := hit.Chunk
+		h.chunk = &chunk
+	}
+
+	hits := make([]searchHit, 0, len(order))
+	for _, path := range order {
+		hits = append(hits, *blended[path])
+	}
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].Score > hits[j].Score })
+	return hits
+}
@@ -0,0 +1,47 @@
+package analyzer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIdentifierParts(t *testing.T) {
+	tests := []struct {
+		word string
+		want []string
+	}{
+		{"parse", []string{"parse"}},
+		{"parseGoMod", []string{"parse", "go", "mod"}},
+		{"HTTPServer", []string{"http", "server"}},
+		{"newHTTPConn", []string{"new", "http", "conn"}},
+		{"snake_case_name", []string{"snake", "case", "name"}},
+		{"_leading__double_", []string{"leading", "double"}},
+		{"utf8Decode", []string{"utf8", "decode"}},
+		{"ID", []string{"id"}},
+		{"ÜberName", []string{"über", "name"}},
+	}
+	for _, tt := range tests {
+		if got := identifierParts([]rune(tt.word)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("identifierParts(%q) = %q, want %q", tt.word, got, tt.want)
+		}
+	}
+}
+
+func TestSearchTerms(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"", nil},
+		{"a b", nil},
+		{"func parseGoMod(path string)", []string{"func", "parsegomod", "parse", "go", "mod", "path", "string"}},
+		{"max_file_size = 10", []string{"maxfilesize", "max", "file", "size", "10"}},
+		{"HTTPServer.Listen()", []string{"httpserver", "http", "server", "listen"}},
+		{"go.mod", []string{"go", "mod"}},
+	}
+	for _, tt := range tests {
+		if got := searchTerms(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("searchTerms(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package analyzer
+
+import (
+	"bytes"
+	"go/ast"
+	"go/printer"
+	"go/token"
+	"strings"
+)
+
+// Symbol describes a declaration found in a Go file
+type Symbol struct {
+	Name      string
+	Kind      string // func, method, struct, interface, type, const, var
+	Receiver  string // receiver type name for methods, without pointer
+	Package   string
+	Signature string
+	Doc       string
+	Exported  bool
+	Path      string
+	StartLine int
+	EndLine   int
+}
+
+// QualifiedName returns Receiver.Name for methods and Name otherwise
+func (s Symbol) QualifiedName() string {
+	if s.Receiver != "" {
+		return s.Receiver + "." + s.Name
+	}
+	return s.Name
+}
+
+// extractSymbols collects top-level declarations of a parsed Go file
+func extractSymbols(fset *token.FileSet, node *ast.File, path string) []Symbol {
+	var symbols []Symbol
+	pkg := node.Name.Name
+
+	for _, decl := range node.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			sym := Symbol{
+				Name:      d.Name.Name,
+				Kind:      "func",
+				Package:   pkg,
+				Signature: funcSignature(fset, d),
+				Doc:       docText(d.Doc),
+				Exported:  d.Name.IsExported(),
+				Path:      path,
+				StartLine: fset.Position(d.Pos()).Line,
+				EndLine:   fset.Position(d.End()).Line,
+			}
+			if d.Recv != nil && len(d.Recv.List) > 0 {
+				sym.Kind = "method"
+				sym.Receiver = receiverName(d.Recv.List[0].Type)
+			}
+			symbols = append(symbols, sym)
+
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					doc := s.Doc
+					if doc == nil && len(d.Specs) == 1 {
+						doc = d.Doc
+					}
+					start := s.Pos()
+					if len(d.Specs) == 1 {
+						start = d.Pos()
+					}
+					symbols = append(symbols, Symbol{
+						Name:      s.Name.Name,
+						Kind:      typeKind(s),
+						Package:   pkg,
+						Signature: typeSignature(fset, s),
+						Doc:       docText(doc),
+						Exported:  s.Name.IsExported(),
+						Path:      path,
+						StartLine: fset.Position(start).Line,
+						EndLine:   fset.Position(s.End()).Line,
+					})
+
+				case *ast.ValueSpec:
+					kind := "var"
+					if d.Tok == token.CONST {
+						kind = "const"
+					}
+					doc := s.Doc
+					if doc == nil && len(d.Specs) == 1 {
+						doc = d.Doc
+					}
+					for _, name := range s.Names {
+						if name.Name == "_" {
+							continue
+						}
+						symbols = append(symbols, Symbol{
+							Name:      name.Name,
+							Kind:      kind,
+							Package:   pkg,
+							Signature: valueSignature(fset, kind, name.Name, s),
+							Doc:       docText(doc),
+							Exported:  name.IsExported(),
+							Path:      path,
+							StartLine: fset.Position(s.Pos()).Line,
+							EndLine:   fset.Position(s.End()).Line,
+						})
+					}
+				}
+			}
+		}
+	}
+
+	return symbols
+}
+
+// hasMainFunc reports whether a package main file declares func main
+func hasMainFunc(node *ast.File) bool {
+	if node.Name.Name != "main" {
+		return false
+	}
+	for _, decl := range node.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Name.Name == "main" {
+			return true
+		}
+	}
+	return false
+}
+
+func receiverName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return receiverName(t.X)
+	case *ast.Ident:
+		return t.Name
+	case *ast.IndexExpr:
+		return receiverName(t.X)
+	case *ast.IndexListExpr:
+		return receiverName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	}
+	return ""
+}
+
+func typeKind(spec *ast.TypeSpec) string {
+	switch spec.Type.(type) {
+	case *ast.StructType:
+		return "struct"
+	case *ast.InterfaceType:
+		return "interface"
+	}
+	return "type"
+}
+
+func funcSignature(fset *token.FileSet, fn *ast.FuncDecl) string {
+	decl := *fn
+	decl.Doc = nil
+	decl.Body = nil
+	return nodeString(fset, &decl)
+}
+
+// typeSignature renders a type declaration; struct and interface bodies are kept
+// since their fields and methods are the useful part of the outline
+func typeSignature(fset *token.FileSet, spec *ast.TypeSpec) string {
+	s := *spec
+	s.Doc = nil
+	s.Comment = nil
+	return "type " + nodeString(fset, &s)
+}
+
+func valueSignature(fset *token.FileSet, kind, name string, spec *ast.ValueSpec) string {
+	sig := kind + " " + name
+	if spec.Type != nil {
+		sig += " " + nodeString(fset, spec.Type)
+	}
+	return sig
+}
+
+func nodeString(fset *token.FileSet, node interface{}) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, node); err != nil {
+		return ""
+	}
+	return buf.String()
+}
+
+func docText(group *ast.CommentGroup) string {
+	if group == nil {
+		return ""
+	}
+	return strings.TrimSpace(group.Text())
+}
@@ -0,0 +1,392 @@
+package analyzer
+
+import (
+	"bufio"
+	"container/heap"
+	_ "embed"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/scopweb/mcp-go-context/internal/config"
+)
+
+// Tokenizer counts text the way a model tokenizes it, so that context can be
+// fitted to a token budget
+type Tokenizer interface {
+	// Name identifies the tokenizer in reports
+	Name() string
+	// Count returns the number of tokens in text
+	Count(text string) int
+	// Truncate returns the longest prefix of text of at most maxTokens tokens
+	Truncate(text string, maxTokens int) string
+}
+
+// bundledRanks is a byte-level BPE vocabulary in the tiktoken rank file
+// format, trained with the cl100k pre-tokenizer on the sources of the Go
+// distribution. TOKENIZER.md describes the corpus, its license and how to
+// regenerate it.
+//
+//go:embed tokenizer.tiktoken
+var bundledRanks string
+
+// maxCachedPieces bounds the memoized token counts of pre-tokenized pieces
+const maxCachedPieces = 1 << 16
+
+// newTokenizer creates the configured tokenizer: "bpe" (the default) with the
+// bundled vocabulary or a tiktoken rank file such as cl100k_base.tiktoken,
+// or "chars" for the four characters per token estimate
+func newTokenizer(cfg config.ContextConfig) (Tokenizer, error) {
+	switch cfg.Tokenizer {
+	case "chars":
+		return charTokenizer{}, nil
+	case "", "bpe":
+	default:
+		return nil, fmt.Errorf("unknown tokenizer %q", cfg.Tokenizer)
+	}
+
+	if cfg.TokenizerRanks == "" {
+		return bundledTokenizer()
+	}
+	f, err := os.Open(expandHome(cfg.TokenizerRanks))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	name := strings.TrimSuffix(filepath.Base(cfg.TokenizerRanks), filepath.Ext(cfg.TokenizerRanks))
+	return newBPETokenizer("bpe:"+name, f)
+}
+
+var (
+	bundledOnce sync.Once
+	bundled     *bpeTokenizer
+	bundledErr  error
+)
+
+// bundledTokenizer parses the embedded vocabulary once
+func bundledTokenizer() (Tokenizer, error) {
+	bundledOnce.Do(func() {
+		bundled, bundledErr = newBPETokenizer("bpe", strings.NewReader(bundledRanks))
+	})
+	return bundled, bundledErr
+}
+
+// bpeTokenizer implements byte-level BPE as tiktoken does: text is split
+// into pieces by the cl100k pattern, and the bytes of each piece are merged
+// pairwise, lowest rank first, while the merged bytes are in the vocabulary
+type bpeTokenizer struct {
+	name  string
+	ranks map[string]int
+
+	mu     sync.Mutex
+	counts map[string]int // tokens per piece
+}
+
+// newBPETokenizer reads a rank file: one base64 token and its rank per line
+func newBPETokenizer(name string, r io.Reader) (*bpeTokenizer, error) {
+	t := &bpeTokenizer{name: name, ranks: make(map[string]int), counts: make(map[string]int)}
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid rank file line %d", line)
+		}
+		token, err := base64.StdEncoding.DecodeString(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid rank file line %d: %w", line, err)
+		}
+		rank, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid rank file line %d: %w", line, err)
+		}
+		t.ranks[string(token)] = rank
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	// Every byte must be a token for any text to be encodable
+	for b := 0; b < 256; b++ {
+		if _, ok := t.ranks[string([]byte{byte(b)})]; !ok {
+			return nil, fmt.Errorf("rank file lacks byte %#x", b)
+		}
+	}
+	return t, nil
+}
+
+func (t *bpeTokenizer) Name() string {
+	return t.name
+}
+
+func (t *bpeTokenizer) Count(text string) int {
+	count := 0
+	for _, piece := range splitPieces(text) {
+		count += t.pieceCount(piece)
+	}
+	return count
+}
+
+func (t *bpeTokenizer) Truncate(text string, maxTokens int) string {
+	count, offset := 0, 0
+	for _, piece := range splitPieces(text) {
+		n := t.pieceCount(piece)
+		if count+n <= maxTokens {
+			count += n
+			offset += len(piece)
+			continue
+		}
+		// Keep the tokens of the piece that still fit, at a rune boundary
+		end := 0
+		for _, length := range t.encode(piece)[:max(0, maxTokens-count)] {
+			end += length
+		}
+		for end > 0 && end < len(piece) && !utf8.RuneStart(piece[end]) {
+			end--
+		}
+		return text[:offset+end]
+	}
+	return text
+}
+
+func (t *bpeTokenizer) pieceCount(piece string) int {
+	if _, ok := t.ranks[piece]; ok {
+		return 1
+	}
+
+	t.mu.Lock()
+	n, ok := t.counts[piece]
+	t.mu.Unlock()
+	if ok {
+		return n
+	}
+
+	n = len(t.encode(piece))
+	t.mu.Lock()
+	if len(t.counts) >= maxCachedPieces {
+		t.counts = make(map[string]int)
+	}
+	t.counts[piece] = n
+	t.mu.Unlock()
+	return n
+}
+
+// encode returns the byte lengths of the tokens of a piece. Tokens are kept
+// in a linked list and candidate merges in a heap, so that each merge costs
+// O(log n) rather than a rescan of the piece.
+func (t *bpeTokenizer) encode(piece string) []int {
+	if _, ok := t.ranks[piece]; ok {
+		return []int{len(piece)}
+	}
+
+	// One token per byte to start with
+	parts := make([]bpePart, len(piece))
+	for i := range parts {
+		parts[i] = bpePart{start: i, end: i + 1, prev: i - 1, next: i + 1}
+	}
+	parts[len(parts)-1].next = -1
+
+	merges := &bpeMerges{}
+	push := func(left int) {
+		if left < 0 || parts[left].next < 0 {
+			return
+		}
+		right := parts[left].next
+		if rank, ok := t.ranks[piece[parts[left].start:parts[right].end]]; ok {
+			heap.Push(merges, bpeMerge{rank: rank, left: left, right: right, end: parts[right].end})
+		}
+	}
+	for i := range parts {
+		push(i)
+	}
+
+	for merges.Len() > 0 {
+		m := heap.Pop(merges).(bpeMerge)
+		left, right := &parts[m.left], &parts[m.right]
+		// Skip merges made stale by an earlier one
+		if left.merged || right.merged || left.next != m.right || right.end != m.end {
+			continue
+		}
+		left.end, left.next = right.end, right.next
+		right.merged = true
+		if right.next >= 0 {
+			parts[right.next].prev = m.left
+		}
+		push(left.prev)
+		push(m.left)
+	}
+
+	var lengths []int
+	for i := 0; i >= 0; i = parts[i].next {
+		lengths = append(lengths, parts[i].end-parts[i].start)
+	}
+	return lengths
+}
+
+// bpePart is a token of a piece being encoded, linked to its neighbours
+type bpePart struct {
+	start, end int
+	prev, next int // -1 at either end
+	merged     bool
+}
+
+// bpeMerge is a candidate merge of two adjacent parts
+type bpeMerge struct {
+	rank        int
+	left, right int
+	end         int // end of the right part when queued
+}
+
+// bpeMerges orders candidate merges lowest rank first, then leftmost, as
+// tiktoken merges them
+type bpeMerges []bpeMerge
+
+func (h bpeMerges) Len() int { return len(h) }
+func (h bpeMerges) Less(i, j int) bool {
+	if h[i].rank != h[j].rank {
+		return h[i].rank < h[j].rank
+	}
+	return h[i].left < h[j].left
+}
+func (h bpeMerges) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *bpeMerges) Push(x interface{}) { *h = append(*h, x.(bpeMerge)) }
+func (h *bpeMerges) Pop() interface{} {
+	old := *h
+	m := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return m
+}
+
+// charTokenizer estimates four characters per token
+type charTokenizer struct{}
+
+func (charTokenizer) Name() string {
+	return "chars"
+}
+
+func (charTokenizer) Count(text string) int {
+	return (len(text) + 3) / 4
+}
+
+func (charTokenizer) Truncate(text string, maxTokens int) string {
+	end := max(0, maxTokens*4)
+	if end >= len(text) {
+		return text
+	}
+	for end > 0 && !utf8.RuneStart(text[end]) {
+		end--
+	}
+	return text[:end]
+}
+
+// splitPieces splits text like the cl100k pattern
+//
+//	(?i:'s|'t|'re|'ve|'m|'ll|'d)|[^\r\n\p{L}\p{N}]?\p{L}+|\p{N}{1,3}|
+//	 ?[^\s\p{L}\p{N}]+[\r\n]*|\s*[\r\n]+|\s+(?!\S)|\s+
+//
+// whose lookahead Go's regexp does not support
+func splitPieces(text string) []string {
+	var pieces []string
+	for i := 0; i < len(text); {
+		n := pieceLength(text[i:])
+		pieces = append(pieces, text[i:i+n])
+		i += n
+	}
+	return pieces
+}
+
+// pieceLength returns the length of the piece text starts with, trying the
+// alternatives of the pattern in order
+func pieceLength(s string) int {
+	r, size := utf8.DecodeRuneInString(s)
+
+	// Contractions
+	if r == '\'' && len(s) > 1 {
+		suffix := strings.ToLower(s[1:min(len(s), 3)])
+		if strings.HasPrefix(suffix, "re") || strings.HasPrefix(suffix, "ve") || strings.HasPrefix(suffix, "ll") {
+			return 3
+		}
+		if c := suffix[0]; c == 's' || c == 't' || c == 'm' || c == 'd' {
+			return 2
+		}
+	}
+
+	// Letters, optionally after one character that is not a digit or newline
+	if unicode.IsLetter(r) {
+		return size + runLength(s[size:], unicode.IsLetter)
+	}
+	if !unicode.IsNumber(r) && !isNewline(r) && size < len(s) {
+		if next, _ := utf8.DecodeRuneInString(s[size:]); unicode.IsLetter(next) {
+			return size + runLength(s[size:], unicode.IsLetter)
+		}
+	}
+
+	// Up to three digits
+	if unicode.IsNumber(r) {
+		n := size
+		for digits := 1; digits < 3 && n < len(s); digits++ {
+			next, nextSize := utf8.DecodeRuneInString(s[n:])
+			if !unicode.IsNumber(next) {
+				break
+			}
+			n += nextSize
+		}
+		return n
+	}
+
+	// Punctuation, optionally after a space, with the newlines that follow
+	start := 0
+	if r == ' ' && size < len(s) {
+		if next, _ := utf8.DecodeRuneInString(s[size:]); isPunctuation(next) {
+			start = size
+		}
+	}
+	if first, _ := utf8.DecodeRuneInString(s[start:]); isPunctuation(first) {
+		n := start + runLength(s[start:], isPunctuation)
+		return n + runLength(s[n:], isNewline)
+	}
+
+	// Whitespace up to its last newline, or leaving the last space for the
+	// word that follows
+	spaces := runLength(s, unicode.IsSpace)
+	if newline := strings.LastIndexAny(s[:spaces], "\r\n"); newline >= 0 {
+		return newline + 1
+	}
+	if spaces < len(s) {
+		if _, lastSize := utf8.DecodeLastRuneInString(s[:spaces]); spaces > lastSize {
+			return spaces - lastSize
+		}
+	}
+	return max(spaces, size)
+}
+
+func isNewline(r rune) bool {
+	return r == '\r' || r == '\n'
+}
+
+func isPunctuation(r rune) bool {
+	return !unicode.IsSpace(r) && !unicode.IsLetter(r) && !unicode.IsNumber(r)
+}
+
+// runLength returns the length of the prefix of s whose runes satisfy in
+func runLength(s string, in func(rune) bool) int {
+	n := 0
+	for n < len(s) {
+		r, size := utf8.DecodeRuneInString(s[n:])
+		if !in(r) {
+			break
+		}
+		n += size
+	}
+	return n
+}
@@ -0,0 +1,238 @@
+package analyzer
+
+import (
+	"bufio"
+	"container/heap"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+)
+
+var generateRanks = flag.Bool("generate-ranks", false, "retrain tokenizer.tiktoken from the sources of the Go distribution")
+
+// bundledVocabSize is the number of tokens in tokenizer.tiktoken
+const bundledVocabSize = 32768
+
+// TestGenerateRanks retrains the bundled vocabulary, as described in
+// TOKENIZER.md:
+//
+//	go test ./internal/analyzer -run TestGenerateRanks -generate-ranks
+func TestGenerateRanks(t *testing.T) {
+	if !*generateRanks {
+		t.Skip("run with -generate-ranks to retrain tokenizer.tiktoken")
+	}
+
+	counts := make(map[string]int)
+	files, size := 0, 0
+	for _, dir := range []string{"src", "doc"} {
+		root := filepath.Join(runtime.GOROOT(), dir)
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if name := d.Name(); name == "testdata" || name == "vendor" {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !isRanksCorpusFile(path) {
+				return nil
+			}
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			for _, piece := range splitPieces(string(content)) {
+				counts[piece]++
+			}
+			files++
+			size += len(content)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Logf("corpus: %d files, %d bytes, %d distinct pieces from %s", files, size, len(counts), runtime.Version())
+
+	ranks := trainBPE(counts, bundledVocabSize)
+
+	out, err := os.Create("tokenizer.tiktoken")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := bufio.NewWriter(out)
+	for rank, token := range ranks {
+		fmt.Fprintf(w, "%s %d\n", base64.StdEncoding.EncodeToString([]byte(token)), rank)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if err := out.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// isRanksCorpusFile reports whether a file of the Go distribution is part of
+// the training corpus: non-test Go sources and the documentation
+func isRanksCorpusFile(path string) bool {
+	switch filepath.Ext(path) {
+	case ".go":
+		return !strings.HasSuffix(path, "_test.go")
+	case ".md", ".html", ".txt", ".s", ".c", ".h", ".json", ".yaml", ".sh":
+		return true
+	}
+	return false
+}
+
+// trainBPE learns byte-level merges from piece counts: starting from the 256
+// bytes, the most frequent adjacent pair of tokens is merged until vocabSize
+// tokens are known. Ties go to the pair of lowest token ids, so the result
+// depends only on the counts.
+func trainBPE(counts map[string]int, vocabSize int) []string {
+	pieces := make([]string, 0, len(counts))
+	for piece := range counts {
+		pieces = append(pieces, piece)
+	}
+	sort.Strings(pieces)
+
+	type word struct {
+		tokens []int
+		count  int
+	}
+	words := make([]word, len(pieces))
+	for i, piece := range pieces {
+		tokens := make([]int, len(piece))
+		for j := 0; j < len(piece); j++ {
+			tokens[j] = int(piece[j])
+		}
+		words[i] = word{tokens: tokens, count: counts[piece]}
+	}
+
+	// vocab holds every merge result by id; ranks the distinct ones
+	vocab := make([]string, 256)
+	known := make(map[string]bool)
+	for b := range vocab {
+		vocab[b] = string([]byte{byte(b)})
+		known[vocab[b]] = true
+	}
+	ranks := append([]string(nil), vocab...)
+
+	pairCounts := make(map[trainPair]int)
+	occurrences := make(map[trainPair]map[int]bool) // words containing the pair
+	for i, w := range words {
+		for j := 0; j+1 < len(w.tokens); j++ {
+			p := trainPair{w.tokens[j], w.tokens[j+1]}
+			pairCounts[p] += w.count
+			if occurrences[p] == nil {
+				occurrences[p] = make(map[int]bool)
+			}
+			occurrences[p][i] = true
+		}
+	}
+	queue := &trainQueue{}
+	for p, n := range pairCounts {
+		*queue = append(*queue, trainCount{p, n})
+	}
+	heap.Init(queue)
+
+	for len(ranks) < vocabSize && queue.Len() > 0 {
+		top := heap.Pop(queue).(trainCount)
+		if pairCounts[top.pair] != top.count || top.count < 2 {
+			continue // stale, or not worth a token
+		}
+		best := top.pair
+		id := len(vocab)
+		merged := vocab[best[0]] + vocab[best[1]]
+		vocab = append(vocab, merged)
+		if !known[merged] {
+			known[merged] = true
+			ranks = append(ranks, merged)
+		}
+
+		// Replace the pair in every word that has it, updating the counts of
+		// the pairs around it
+		changed := make(map[trainPair]bool)
+		for i := range occurrences[best] {
+			w := &words[i]
+			for j := 0; j+1 < len(w.tokens); j++ {
+				p := trainPair{w.tokens[j], w.tokens[j+1]}
+				pairCounts[p] -= w.count
+				changed[p] = true
+			}
+			tokens := w.tokens[:0:0]
+			for j := 0; j < len(w.tokens); j++ {
+				if j+1 < len(w.tokens) && w.tokens[j] == best[0] && w.tokens[j+1] == best[1] {
+					tokens = append(tokens, id)
+					j++
+				} else {
+					tokens = append(tokens, w.tokens[j])
+				}
+			}
+			w.tokens = tokens
+			for j := 0; j+1 < len(w.tokens); j++ {
+				p := trainPair{w.tokens[j], w.tokens[j+1]}
+				pairCounts[p] += w.count
+				changed[p] = true
+				if occurrences[p] == nil {
+					occurrences[p] = make(map[int]bool)
+				}
+				occurrences[p][i] = true
+			}
+		}
+		delete(occurrences, best)
+		for p := range changed {
+			if n := pairCounts[p]; n > 0 {
+				heap.Push(queue, trainCount{p, n})
+			} else {
+				delete(pairCounts, p)
+			}
+		}
+	}
+	return ranks
+}
+
+// trainPair is a pair of adjacent token ids
+type trainPair [2]int
+
+type trainCount struct {
+	pair  trainPair
+	count int
+}
+
+// trainQueue orders pairs most frequent first, then by token ids
+type trainQueue []trainCount
+
+func (q trainQueue) Len() int { return len(q) }
+func (q trainQueue) Less(i, j int) bool {
+	if q[i].count != q[j].count {
+		return q[i].count > q[j].count
+	}
+	if q[i].pair[0] != q[j].pair[0] {
+		return q[i].pair[0] < q[j].pair[0]
+	}
+	return q[i].pair[1] < q[j].pair[1]
+}
+func (q trainQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *trainQueue) Push(x interface{}) { *q = append(*q, x.(trainCount)) }
+func (q *trainQueue) Pop() interface{} {
+	old := *q
+	top := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return top
+}
+
+func TestTrainBPE(t *testing.T) {
+	ranks := trainBPE(map[string]int{"abab": 3, "ab": 2, "b": 1}, 258)
+	if len(ranks) != 258 || ranks[256] != "ab" || ranks[257] != "abab" {
+		t.Errorf("trainBPE() learned %q, want [ab abab]", ranks[256:])
+	}
+}
@@ -0,0 +1,160 @@
+package analyzer
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testTokenizer builds a tokenizer from the 256 bytes followed by merged
+// tokens in rank order
+func testTokenizer(t testing.TB, merged ...string) *bpeTokenizer {
+	t.Helper()
+	var ranks strings.Builder
+	for b := 0; b < 256; b++ {
+		fmt.Fprintf(&ranks, "%s %d\n", base64.StdEncoding.EncodeToString([]byte{byte(b)}), b)
+	}
+	for i, token := range merged {
+		fmt.Fprintf(&ranks, "%s %d\n", base64.StdEncoding.EncodeToString([]byte(token)), 256+i)
+	}
+	tok, err := newBPETokenizer("test", strings.NewReader(ranks.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tok
+}
+
+func TestBPEEncode(t *testing.T) {
+	tok := testTokenizer(t, "ab", "bc", "abc", "aa", "aaaa", "==", "====")
+
+	tests := []struct {
+		piece string
+		want  []string
+	}{
+		{"a", []string{"a"}},
+		{"abc", []string{"abc"}},
+		// "ab" outranks "bc", so "bc" never forms
+		{"abcd", []string{"abc", "d"}},
+		{"bcab", []string{"bc", "ab"}},
+		// Equal ranks merge leftmost first
+		{"aaa", []string{"aa", "a"}},
+		{"aaaaa", []string{"aaaa", "a"}},
+		{"=======", []string{"====", "==", "="}},
+		{"xyz", []string{"x", "y", "z"}},
+		{"é", []string{"\xc3", "\xa9"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		offset := 0
+		for _, n := range tok.encode(tt.piece) {
+			got = append(got, tt.piece[offset:offset+n])
+			offset += n
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("encode(%q) = %q, want %q", tt.piece, got, tt.want)
+		}
+	}
+}
+
+// naiveEncode merges by rescanning the piece for the lowest ranked pair
+func naiveEncode(t *bpeTokenizer, piece string) []int {
+	bounds := make([]int, len(piece)+1)
+	for i := range bounds {
+		bounds[i] = i
+	}
+	for len(bounds) > 2 {
+		best, bestRank := -1, 0
+		for i := 0; i+2 < len(bounds); i++ {
+			if rank, ok := t.ranks[piece[bounds[i]:bounds[i+2]]]; ok && (best < 0 || rank < bestRank) {
+				best, bestRank = i, rank
+			}
+		}
+		if best < 0 {
+			break
+		}
+		bounds = append(bounds[:best+1], bounds[best+2:]...)
+	}
+	lengths := make([]int, len(bounds)-1)
+	for i := range lengths {
+		lengths[i] = bounds[i+1] - bounds[i]
+	}
+	return lengths
+}
+
+func TestBPEEncodeBundled(t *testing.T) {
+	tok, err := bundledTokenizer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bpe := tok.(*bpeTokenizer)
+
+	rng := rand.New(rand.NewSource(1))
+	alphabet := []string{"a", "e", "t", "in", "re", "_", "=", " ", "(", ")", "{", "\n", "é", "0"}
+	for i := 0; i < 500; i++ {
+		var piece strings.Builder
+		for n := rng.Intn(40) + 1; n > 0; n-- {
+			piece.WriteString(alphabet[rng.Intn(len(alphabet))])
+		}
+		if got, want := bpe.encode(piece.String()), naiveEncode(bpe, piece.String()); !reflect.DeepEqual(got, want) {
+			t.Fatalf("encode(%q) = %v, want %v", piece.String(), got, want)
+		}
+	}
+}
+
+func TestBPETruncate(t *testing.T) {
+	tok := testTokenizer(t, "ab", "abab")
+
+	tests := []struct {
+		text      string
+		maxTokens int
+		want      string
+	}{
+		{"abab", 1, "abab"},
+		{"abab", 0, ""},
+		{"ababab", 1, "abab"},
+		{"ababab", 5, "ababab"},
+	}
+	for _, tt := range tests {
+		if got := tok.Truncate(tt.text, tt.maxTokens); got != tt.want {
+			t.Errorf("Truncate(%q, %d) = %q, want %q", tt.text, tt.maxTokens, got, tt.want)
+		}
+	}
+}
+
+func TestSplitPieces(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"hello world", []string{"hello", " world"}},
+		{"it's 12345", []string{"it", "'s", " ", "123", "45"}},
+		{"x := y\n\n\tz", []string{"x", " :=", " y", "\n\n", "\tz"}},
+		{"foo()  bar", []string{"foo", "()", " ", " bar"}},
+		{"a  \n", []string{"a", "  \n"}},
+	}
+	for _, tt := range tests {
+		if got := splitPieces(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitPieces(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func BenchmarkBPECount(b *testing.B) {
+	tok, err := bundledTokenizer()
+	if err != nil {
+		b.Fatal(err)
+	}
+	for _, size := range []int{5 << 10, 50 << 10} {
+		text := strings.Repeat("=", size)
+		b.Run(fmt.Sprintf("repeated-%dKB", size>>10), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				// A fresh tokenizer each time, so the piece is not cached
+				bpe := &bpeTokenizer{ranks: tok.(*bpeTokenizer).ranks, counts: make(map[string]int)}
+				bpe.Count(text)
+			}
+		})
+	}
+}
@@ -0,0 +1,340 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// TypeInfo answers type-level questions about a Go symbol and renders markdown.
+// Supported queries: "methods" (full method set including promoted methods),
+// "implements" (implementers of an interface, or interfaces a type satisfies)
+// and "resolve" (definition of the identifier at file:line:col).
+func (a *ProjectAnalyzer) TypeInfo(query, symbol string) (string, error) {
+	symbol = strings.TrimSpace(symbol)
+	if symbol == "" {
+		return "", fmt.Errorf("symbol required")
+	}
+
+	indexes := a.typeIndexes()
+	if len(indexes) == 0 {
+		return "", fmt.Errorf("type information unavailable: no Go module found in project paths (syntax-only mode, use find-symbol)")
+	}
+
+	var result strings.Builder
+
+	switch query {
+	case "methods":
+		obj, index := lookupTypeName(indexes, symbol)
+		if obj == nil {
+			return fmt.Sprintf("# Type: %s\n\nNo type found.\n", symbol), nil
+		}
+		writeTypeHeader(&result, a, index, obj)
+		writeMethodSet(&result, a, index, obj)
+
+	case "implements":
+		obj, index := lookupTypeName(indexes, symbol)
+		if obj == nil {
+			return fmt.Sprintf("# Type: %s\n\nNo type found.\n", symbol), nil
+		}
+		writeTypeHeader(&result, a, index, obj)
+		if types.IsInterface(obj.Type()) {
+			impls := implementersOf(indexes, obj)
+			result.WriteString(fmt.Sprintf("## Implementations (%d)\n\n", len(impls)))
+			for _, impl := range impls {
+				result.WriteString(fmt.Sprintf("- `%s` - `%s`\n", impl.Name, a.objectPosition(index, impl.Object)))
+			}
+		} else {
+			ifaces := interfacesOf(indexes, obj)
+			result.WriteString(fmt.Sprintf("## Satisfied Interfaces (%d)\n\n", len(ifaces)))
+			for _, iface := range ifaces {
+				result.WriteString(fmt.Sprintf("- `%s` - `%s`\n", iface.Name, a.objectPosition(index, iface.Object)))
+			}
+		}
+
+	case "resolve":
+		obj, index, err := resolvePosition(indexes, symbol)
+		if err != nil {
+			return "", err
+		}
+		result.WriteString(fmt.Sprintf("# Identifier at %s\n\n", symbol))
+		result.WriteString(fmt.Sprintf("- **Object**: `%s`\n", types.ObjectString(obj, nil)))
+		if obj.Pkg() != nil {
+			result.WriteString(fmt.Sprintf("- **Package**: `%s`\n", obj.Pkg().Path()))
+		}
+		if obj.Pos().IsValid() {
+			result.WriteString(fmt.Sprintf("- **Defined at**: `%s`\n", a.objectPosition(index, obj)))
+		}
+
+	default:
+		return "", fmt.Errorf("unknown query %q (use methods, implements or resolve)", query)
+	}
+
+	result.WriteString(fmt.Sprintf("\n_Type information: %s_\n", typeStatus(indexes)))
+	return result.String(), nil
+}
+
+// namedObject pairs a type-checker object with its display name
+type namedObject struct {
+	Name   string
+	Object types.Object
+}
+
+// lookupTypeName finds a type by Name or pkg.Name in the project packages
+func lookupTypeName(indexes []*TypeIndex, symbol string) (*types.TypeName, *TypeIndex) {
+	pkgName, name := "", symbol
+	if i := strings.LastIndex(symbol, "."); i >= 0 {
+		pkgName, name = symbol[:i], symbol[i+1:]
+	}
+
+	for _, index := range indexes {
+		for _, pkg := range index.Packages {
+			if pkg.Types == nil {
+				continue
+			}
+			if pkgName != "" && pkg.Name != pkgName && pkg.Path != pkgName && !strings.HasSuffix(pkg.Path, "/"+pkgName) {
+				continue
+			}
+			if tn, ok := pkg.Types.Scope().Lookup(name).(*types.TypeName); ok {
+				return tn, index
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// projectTypeNames returns all package-level named types declared in the project
+func projectTypeNames(indexes []*TypeIndex) []*types.TypeName {
+	var names []*types.TypeName
+	for _, index := range indexes {
+		for _, pkg := range index.Packages {
+			if pkg.Types == nil {
+				continue
+			}
+			scope := pkg.Types.Scope()
+			for _, name := range scope.Names() {
+				if tn, ok := scope.Lookup(name).(*types.TypeName); ok && !tn.IsAlias() {
+					names = append(names, tn)
+				}
+			}
+		}
+	}
+	return names
+}
+
+// implementersOf lists concrete project types whose value or pointer satisfies iface
+func implementersOf(indexes []*TypeIndex, iface *types.TypeName) []namedObject {
+	it, ok := iface.Type().Underlying().(*types.Interface)
+	if !ok || it.NumMethods() == 0 {
+		return nil
+	}
+
+	var result []namedObject
+	for _, tn := range projectTypeNames(indexes) {
+		if types.IsInterface(tn.Type()) || isGeneric(tn) {
+			continue
+		}
+		if types.Implements(tn.Type(), it) {
+			result = append(result, namedObject{Name: qualifiedTypeName(tn), Object: tn})
+		} else if types.Implements(types.NewPointer(tn.Type()), it) {
+			result = append(result, namedObject{Name: "*" + qualifiedTypeName(tn), Object: tn})
+		}
+	}
+	return result
+}
+
+// interfacesOf lists project interfaces (and the builtin error) satisfied by a concrete type
+func interfacesOf(indexes []*TypeIndex, concrete *types.TypeName) []namedObject {
+	if isGeneric(concrete) {
+		return nil
+	}
+
+	var result []namedObject
+	check := func(name string, obj types.Object, it *types.Interface) {
+		if it.NumMethods() == 0 {
+			return
+		}
+		if types.Implements(concrete.Type(), it) {
+			result = append(result, namedObject{Name: name, Object: obj})
+		} else if types.Implements(types.NewPointer(concrete.Type()), it) {
+			result = append(result, namedObject{Name: name + " (pointer receiver)", Object: obj})
+		}
+	}
+
+	for _, tn := range projectTypeNames(indexes) {
+		if isGeneric(tn) {
+			continue
+		}
+		if it, ok := tn.Type().Underlying().(*types.Interface); ok {
+			check(qualifiedTypeName(tn), tn, it)
+		}
+	}
+
+	errType := types.Universe.Lookup("error")
+	check("error", errType, errType.Type().Underlying().(*types.Interface))
+
+	return result
+}
+
+// resolvePosition resolves "file:line:col" (or "file:line") to the object referenced there
+func resolvePosition(indexes []*TypeIndex, position string) (types.Object, *TypeIndex, error) {
+	parts := strings.Split(position, ":")
+	if len(parts) < 2 {
+		return nil, nil, fmt.Errorf("position must be file:line[:col]")
+	}
+
+	col := 0
+	if len(parts) >= 3 {
+		if c, err := strconv.Atoi(parts[len(parts)-1]); err == nil {
+			col = c
+			parts = parts[:len(parts)-1]
+		}
+	}
+	line, err := strconv.Atoi(parts[len(parts)-1])
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid line in %s", position)
+	}
+	file := strings.Join(parts[:len(parts)-1], ":")
+
+	for _, index := range indexes {
+		target := file
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(index.ModuleDir, target)
+		}
+
+		for _, pkg := range index.Packages {
+			if pkg.Info == nil {
+				continue
+			}
+
+			var best *ast.Ident
+			for ident := range identsAt(index.Fset, pkg, target, line) {
+				pos := index.Fset.Position(ident.Pos())
+				if col == 0 || (col >= pos.Column && col < pos.Column+len(ident.Name)) {
+					if best == nil || pos.Column < index.Fset.Position(best.Pos()).Column {
+						best = ident
+					}
+				}
+			}
+
+			if best != nil {
+				if obj := pkg.Info.Uses[best]; obj != nil {
+					return obj, index, nil
+				}
+				if obj := pkg.Info.Defs[best]; obj != nil {
+					return obj, index, nil
+				}
+			}
+		}
+	}
+
+	return nil, nil, fmt.Errorf("no identifier found at %s", position)
+}
+
+// identsAt returns identifiers of pkg located on the given line of file
+func identsAt(fset *token.FileSet, pkg *TypedPackage, file string, line int) map[*ast.Ident]bool {
+	idents := make(map[*ast.Ident]bool)
+	for ident := range pkg.Info.Uses {
+		p := fset.Position(ident.Pos())
+		if p.Line == line && filepath.Clean(p.Filename) == filepath.Clean(file) {
+			idents[ident] = true
+		}
+	}
+	for ident, obj := range pkg.Info.Defs {
+		if obj == nil {
+			continue
+		}
+		p := fset.Position(ident.Pos())
+		if p.Line == line && filepath.Clean(p.Filename) == filepath.Clean(file) {
+			idents[ident] = true
+		}
+	}
+	return idents
+}
+
+func writeTypeHeader(result *strings.Builder, a *ProjectAnalyzer, index *TypeIndex, obj *types.TypeName) {
+	result.WriteString(fmt.Sprintf("# Type: %s\n\n", qualifiedTypeName(obj)))
+	result.WriteString(fmt.Sprintf("- **Defined at**: `%s`\n", a.objectPosition(index, obj)))
+	result.WriteString(fmt.Sprintf("- **Underlying**: `%s`\n\n", types.TypeString(obj.Type().Underlying(), shortQualifier)))
+}
+
+func writeMethodSet(result *strings.Builder, a *ProjectAnalyzer, index *TypeIndex, obj *types.TypeName) {
+	typ := obj.Type()
+	pointer := !types.IsInterface(typ)
+
+	var mset *types.MethodSet
+	if pointer {
+		mset = types.NewMethodSet(types.NewPointer(typ))
+	} else {
+		mset = types.NewMethodSet(typ)
+	}
+	valueSet := types.NewMethodSet(typ)
+
+	result.WriteString(fmt.Sprintf("## Methods (%d)\n\n", mset.Len()))
+	for i := 0; i < mset.Len(); i++ {
+		sel := mset.At(i)
+		fn := sel.Obj().(*types.Func)
+		sig := fn.Type().(*types.Signature)
+
+		notes := []string{}
+		if pointer && valueSet.Lookup(fn.Pkg(), fn.Name()) == nil {
+			notes = append(notes, "pointer receiver")
+		}
+		if len(sel.Index()) > 1 {
+			from := "embedded"
+			if recv := sig.Recv(); recv != nil {
+				from = types.TypeString(recv.Type(), shortQualifier)
+			}
+			notes = append(notes, "promoted from "+from)
+		}
+
+		line := fmt.Sprintf("- `%s%s`", fn.Name(), strings.TrimPrefix(types.TypeString(sig, shortQualifier), "func"))
+		if len(notes) > 0 {
+			line += " _(" + strings.Join(notes, ", ") + ")_"
+		}
+		if fn.Pos().IsValid() {
+			line += fmt.Sprintf(" - `%s`", a.objectPosition(index, fn))
+		}
+		result.WriteString(line + "\n")
+	}
+}
+
+// objectPosition formats an object's position relative to the project roots
+func (a *ProjectAnalyzer) objectPosition(index *TypeIndex, obj types.Object) string {
+	if !obj.Pos().IsValid() {
+		return "builtin"
+	}
+	pos := index.Fset.Position(obj.Pos())
+	return fmt.Sprintf("%s:%d", a.displayPath(pos.Filename), pos.Line)
+}
+
+func typeStatus(indexes []*TypeIndex) string {
+	var statuses []string
+	for _, index := range indexes {
+		statuses = append(statuses, fmt.Sprintf("%s %s", index.Name(), index.Status()))
+	}
+	sort.Strings(statuses)
+	return strings.Join(statuses, "; ")
+}
+
+func qualifiedTypeName(tn *types.TypeName) string {
+	if tn.Pkg() == nil {
+		return tn.Name()
+	}
+	return tn.Pkg().Name() + "." + tn.Name()
+}
+
+func isGeneric(tn *types.TypeName) bool {
+	named, ok := tn.Type().(*types.Named)
+	return ok && named.TypeParams().Len() > 0
+}
+
+func shortQualifier(pkg *types.Package) string {
+	return pkg.Name()
+}
@@ -0,0 +1,112 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTypeInfo(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, shopFiles)
+	a := newTestAnalyzer(t, root)
+
+	tests := []struct {
+		query, symbol string
+		want          []string
+	}{
+		{"methods", "store.Product", []string{
+			"# Type: store.Product\n",
+			"- **Defined at**: `store/store.go:16`\n",
+			"## Methods (3)\n",
+			"- `ID() string` _(promoted from store.Base)_ - `store/store.go:11`\n",
+			"- `Price() int` - `store/store.go:21`\n",
+			"- `Touch()` _(pointer receiver, promoted from *store.Base)_ - `store/store.go:13`\n",
+		}},
+		{"methods", "Pricer", []string{"## Methods (1)\n", "- `Price() int` - `store/store.go:5`\n"}},
+		{"implements", "store.Pricer", []string{"## Implementations (1)\n", "- `store.Product` - `store/store.go:16`\n"}},
+		{"implements", "Product", []string{"## Satisfied Interfaces (1)\n", "- `store.Pricer` - `store/store.go:4`\n"}},
+		{"implements", "Item", []string{"## Satisfied Interfaces (0)\n"}},
+		{"resolve", "app/app.go:11:17", []string{"- **Object**: `func example.com/shop/store.Total(items []example.com/shop/store.Pricer) int`\n", "- **Defined at**: `store/store.go:29`\n"}},
+		{"resolve", "app/app.go:6", []string{"- **Object**: `func example.com/shop/app.subtotal() int`\n", "- **Defined at**: `app/app.go:9`\n"}},
+		// An embedded field resolves to the type it embeds
+		{"resolve", "store/store.go:17", []string{"- **Object**: `type example.com/shop/store.Base struct{}`\n", "- **Defined at**: `store/store.go:9`\n"}},
+		{"methods", "store.Nothing", []string{"No type found.\n"}},
+	}
+	for _, tt := range tests {
+		got, err := a.TypeInfo(tt.query, tt.symbol)
+		if err != nil {
+			t.Errorf("TypeInfo(%q, %q): %v", tt.query, tt.symbol, err)
+			continue
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(got, want) {
+				t.Errorf("TypeInfo(%q, %q) missing %q:\n%s", tt.query, tt.symbol, want, got)
+			}
+		}
+	}
+
+	for _, tt := range []struct{ query, symbol string }{
+		{"methods", ""},
+		{"fields", "Product"},
+		{"resolve", "app/app.go"},
+		{"resolve", "app/app.go:3:1"},
+	} {
+		if _, err := a.TypeInfo(tt.query, tt.symbol); err == nil {
+			t.Errorf("TypeInfo(%q, %q): expected an error", tt.query, tt.symbol)
+		}
+	}
+}
+
+func TestTypeInfoMissingDependency(t *testing.T) {
+	t.Setenv("GOMODCACHE", t.TempDir())
+
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.mod": "module example.com/shop\n\ngo 1.21\n\nrequire example.com/gone v1.0.0\n",
+		"store/store.go": `package store
+
+import "example.com/gone"
+
+type Pricer interface {
+	Price() int
+}
+
+type Product struct{}
+
+func (Product) Price() int { return gone.Price() }
+
+func Wrap(w gone.Writer) gone.Writer { return w }
+`,
+	})
+	a := newTestAnalyzer(t, root)
+
+	// Types not depending on the missing module are still fully described
+	got, err := a.TypeInfo("implements", "Pricer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"- `store.Product` - `store/store.go:9`\n",
+		"_Type information: example.com/shop partial (1 unresolved imports)_\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("TypeInfo missing %q:\n%s", want, got)
+		}
+	}
+
+	index, err := a.TypeIndex(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(index.Missing) != 1 || index.Missing[0] != "example.com/gone" {
+		t.Errorf("Missing = %q", index.Missing)
+	}
+	if len(index.Packages) != 1 || !index.Packages[0].Partial() {
+		t.Errorf("store should load with type errors: %+v", index.Packages)
+	}
+
+	// Without a module there is no type information at all
+	if _, err := newTestAnalyzer(t, t.TempDir()).TypeInfo("methods", "Product"); err == nil {
+		t.Error("expected an error without a Go module")
+	}
+}
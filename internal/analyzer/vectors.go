@@ -0,0 +1,326 @@
+package analyzer
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Chunk sizes in lines: larger declarations are split, runs of smaller ones
+// are merged
+const (
+	maxChunkLines = 80
+	minChunkLines = 8
+)
+
+// maxChunkChars bounds the text of a chunk sent to the embedder
+const maxChunkChars = 2000
+
+// embedBatchChunks is the number of chunks passed to one Embed call
+const embedBatchChunks = 64
+
+// declarationPattern matches the first line of a top-level declaration in
+// languages without symbol extraction
+var declarationPattern = regexp.MustCompile(`^(export\s+)?(default\s+)?(pub(\(crate\))?\s+)?(async\s+)?(def|class|function|fn|impl|struct|enum|trait|interface|type|func|module|public|private|protected|internal)\b`)
+
+// commentPattern matches lines that belong to the declaration below them
+var commentPattern = regexp.MustCompile(`^\s*(//|#|/\*|\*|--|@)`)
+
+// fileChunk is a region of a file embedded as one vector
+type fileChunk struct {
+	StartLine int // 1-based, inclusive
+	EndLine   int
+	Symbol    string
+	Vector    []float32
+}
+
+// vectorFile holds the chunks of a file and the data used to validate them
+type vectorFile struct {
+	Modified int64
+	Size     int64
+	Chunks   []fileChunk
+}
+
+// vectorStore is the on-disk form of a vectorIndex
+type vectorStore struct {
+	Embedder string
+	Files    map[string]*vectorFile
+}
+
+// vectorIndex embeds file chunks and finds those closest to a query. Vectors
+// are persisted per embedder next to the file cache and, like the search
+// index, brought up to date with the file cache before each query.
+type vectorIndex struct {
+	syncMu   sync.Mutex // serializes sync, so each change is embedded once
+	mu       sync.Mutex // guards files and dirty; not held while embedding
+	embedder Embedder
+	path     string // store location; empty keeps vectors in memory only
+	files    map[string]*vectorFile
+	dirty    bool
+}
+
+// vectorHit is the best matching chunk of a file
+type vectorHit struct {
+	Path  string
+	Score float64 // cosine similarity
+	Chunk fileChunk
+}
+
+func newVectorIndex(embedder Embedder, cacheDir string) *vectorIndex {
+	idx := &vectorIndex{embedder: embedder, files: make(map[string]*vectorFile)}
+	if cacheDir != "" {
+		name := regexp.MustCompile(`[^A-Za-z0-9._-]+`).ReplaceAllString(embedder.Name(), "_")
+		idx.path = filepath.Join(expandHome(cacheDir), "vectors-"+name+".gob")
+		idx.load()
+	}
+	return idx
+}
+
+// sync embeds the chunks of new and changed files and drops files no longer
+// cached, persisting the result. Embedding runs without holding idx.mu, so
+// searches proceed on the previous vectors meanwhile.
+func (idx *vectorIndex) sync(files []*FileInfo) error {
+	idx.syncMu.Lock()
+	defer idx.syncMu.Unlock()
+
+	idx.mu.Lock()
+	live := make(map[string]bool, len(files))
+	var pending []*FileInfo
+	for _, file := range files {
+		live[file.Path] = true
+		if vf, ok := idx.files[file.Path]; !ok || vf.Modified != file.LastModified || vf.Size != file.Size {
+			pending = append(pending, file)
+		}
+	}
+	for path := range idx.files {
+		if !live[path] {
+			delete(idx.files, path)
+			idx.dirty = true
+		}
+	}
+	idx.mu.Unlock()
+
+	// Embed in batches so one request covers many small files
+	type owner struct {
+		file  *vectorFile
+		chunk int
+	}
+	embedded := make(map[string]*vectorFile, len(pending))
+	var texts []string
+	var owners []owner
+	var err error
+	flush := func() {
+		if len(texts) == 0 || err != nil {
+			return
+		}
+		var vectors [][]float32
+		if vectors, err = idx.embedder.Embed(texts); err != nil {
+			return
+		}
+		for i, o := range owners {
+			o.file.Chunks[o.chunk].Vector = vectors[i]
+		}
+		texts, owners = texts[:0], owners[:0]
+	}
+	for _, file := range pending {
+		content, readErr := os.ReadFile(file.Path)
+		if readErr != nil || bytes.IndexByte(content, 0) >= 0 {
+			embedded[file.Path] = &vectorFile{Modified: file.LastModified, Size: file.Size}
+			continue
+		}
+		lines := strings.Split(string(content), "\n")
+		vf := &vectorFile{Modified: file.LastModified, Size: file.Size, Chunks: chunkFile(file, lines)}
+		for i, chunk := range vf.Chunks {
+			texts = append(texts, chunkText(file.Path, chunk, lines))
+			owners = append(owners, owner{vf, i})
+			if len(texts) >= embedBatchChunks {
+				flush()
+			}
+		}
+		embedded[file.Path] = vf
+	}
+	flush()
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	for path, vf := range embedded {
+		// Leave files with missing vectors to be embedded again next time
+		complete := true
+		for _, chunk := range vf.Chunks {
+			if chunk.Vector == nil {
+				complete = false
+				break
+			}
+		}
+		if complete {
+			idx.files[path] = vf
+			idx.dirty = true
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	return idx.save()
+}
+
+// search returns the files whose best chunk is most similar to query
+func (idx *vectorIndex) search(query string, limit int) ([]vectorHit, error) {
+	vectors, err := idx.embedder.Embed([]string{query})
+	if err != nil {
+		return nil, err
+	}
+	if len(vectors) == 0 {
+		return nil, fmt.Errorf("embedder returned no vector")
+	}
+	queryVector := vectors[0]
+
+	idx.mu.Lock()
+	var hits []vectorHit
+	for path, vf := range idx.files {
+		best := vectorHit{Path: path, Score: -1}
+		for _, chunk := range vf.Chunks {
+			if score := dotProduct(queryVector, chunk.Vector); score > best.Score {
+				best.Score, best.Chunk = score, chunk
+			}
+		}
+		if best.Score > 0 {
+			hits = append(hits, best)
+		}
+	}
+	idx.mu.Unlock()
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].Path < hits[j].Path
+	})
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits, nil
+}
+
+// chunkFile splits a file along its declarations: Go symbols, or lines that
+// look like declarations in other languages. Leading comments stay with the
+// declaration they document.
+func chunkFile(file *FileInfo, lines []string) []fileChunk {
+	type boundary struct {
+		line   int // 0-based
+		symbol string
+	}
+	var boundaries []boundary
+	if len(file.Symbols) > 0 {
+		for _, sym := range file.Symbols {
+			if sym.StartLine > 0 && sym.StartLine <= len(lines) {
+				boundaries = append(boundaries, boundary{sym.StartLine - 1, sym.QualifiedName()})
+			}
+		}
+	} else {
+		for i, line := range lines {
+			if declarationPattern.MatchString(line) {
+				boundaries = append(boundaries, boundary{i, strings.TrimSpace(line)})
+			}
+		}
+	}
+	sort.SliceStable(boundaries, func(i, j int) bool { return boundaries[i].line < boundaries[j].line })
+
+	// Move each boundary above the comments preceding it
+	for i := range boundaries {
+		floor := 0
+		if i > 0 {
+			floor = boundaries[i-1].line + 1
+		}
+		for boundaries[i].line > floor && commentPattern.MatchString(lines[boundaries[i].line-1]) {
+			boundaries[i].line--
+		}
+	}
+
+	var chunks []fileChunk
+	add := func(start, end int, symbol string) {
+		for start < end {
+			stop := min(end, start+maxChunkLines)
+			chunks = append(chunks, fileChunk{StartLine: start + 1, EndLine: stop, Symbol: symbol})
+			start = stop
+		}
+	}
+	start, symbol := 0, ""
+	for _, b := range boundaries {
+		if b.line < start {
+			continue
+		}
+		// Small declarations share a chunk with the following ones
+		if b.line-start >= minChunkLines {
+			add(start, b.line, symbol)
+			start, symbol = b.line, ""
+		}
+		if symbol == "" {
+			symbol = b.symbol
+		}
+	}
+	add(start, len(lines), symbol)
+	return chunks
+}
+
+// chunkText is the text embedded for a chunk: its location and content
+func chunkText(path string, chunk fileChunk, lines []string) string {
+	text := filepath.Base(path) + " " + chunk.Symbol + "\n" + strings.Join(lines[chunk.StartLine-1:chunk.EndLine], "\n")
+	if len(text) > maxChunkChars {
+		end := maxChunkChars
+		for end > 0 && !utf8.RuneStart(text[end]) {
+			end--
+		}
+		text = text[:end]
+	}
+	return text
+}
+
+func (idx *vectorIndex) load() {
+	f, err := os.Open(idx.path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	var store vectorStore
+	if err := gob.NewDecoder(f).Decode(&store); err != nil || store.Embedder != idx.embedder.Name() {
+		return
+	}
+	if store.Files != nil {
+		idx.files = store.Files
+	}
+}
+
+// save writes the store atomically when it changed; callers hold idx.mu
+func (idx *vectorIndex) save() error {
+	if idx.path == "" || !idx.dirty {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(vectorStore{Embedder: idx.embedder.Name(), Files: idx.files}); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(idx.path), 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	tmp := idx.path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, idx.path); err != nil {
+		return err
+	}
+
+	idx.dirty = false
+	return nil
+}
@@ -0,0 +1,84 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestChunkTextRuneBoundary(t *testing.T) {
+	// Multibyte runes straddle maxChunkChars at every offset
+	for pad := 0; pad < 3; pad++ {
+		lines := []string{strings.Repeat("x", pad) + strings.Repeat("é€", maxChunkChars)}
+		text := chunkText("a.txt", fileChunk{StartLine: 1, EndLine: 1}, lines)
+		if len(text) > maxChunkChars {
+			t.Errorf("pad %d: %d bytes, want at most %d", pad, len(text), maxChunkChars)
+		}
+		if !utf8.ValidString(text) {
+			t.Errorf("pad %d: chunk text is not valid UTF-8", pad)
+		}
+	}
+}
+
+// blockingEmbedder embeds every text as the same vector once released
+type blockingEmbedder struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (e *blockingEmbedder) Name() string { return "blocking" }
+
+func (e *blockingEmbedder) Embed(texts []string) ([][]float32, error) {
+	if len(texts) == 1 && texts[0] == "query" {
+		return [][]float32{{1, 0}}, nil
+	}
+	close(e.started)
+	<-e.release
+	vectors := make([][]float32, len(texts))
+	for i := range vectors {
+		vectors[i] = []float32{1, 0}
+	}
+	return vectors, nil
+}
+
+func TestVectorSyncDoesNotBlockSearch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.txt")
+	if err := os.WriteFile(path, []byte("hello\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	stat, _ := os.Stat(path)
+
+	embedder := &blockingEmbedder{started: make(chan struct{}), release: make(chan struct{})}
+	idx := newVectorIndex(embedder, "")
+
+	synced := make(chan error)
+	go func() {
+		synced <- idx.sync([]*FileInfo{{Path: path, Size: stat.Size(), LastModified: stat.ModTime().Unix()}})
+	}()
+	<-embedder.started
+
+	searched := make(chan int)
+	go func() {
+		hits, _ := idx.search("query", 10)
+		searched <- len(hits)
+	}()
+	select {
+	case n := <-searched:
+		if n != 0 {
+			t.Errorf("search during sync found %d hits, want none yet", n)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("search blocked while sync was embedding")
+	}
+
+	close(embedder.release)
+	if err := <-synced; err != nil {
+		t.Fatal(err)
+	}
+	if hits, _ := idx.search("query", 10); len(hits) != 1 {
+		t.Errorf("search after sync found %d hits, want 1", len(hits))
+	}
+}
@@ -0,0 +1,124 @@
+package analyzer
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+// concreteVersionPattern matches a single version, as opposed to a range,
+// wildcard or placeholder
+var concreteVersionPattern = regexp.MustCompile(`^v?\d+(\.\d+)*([-+._]?[0-9A-Za-z]+)*([-+.][0-9A-Za-z.-]+)?$`)
+
+// isConcreteVersion reports whether version names one release
+func isConcreteVersion(version string) bool {
+	return concreteVersionPattern.MatchString(version) && !strings.Contains(version, ".x") && !strings.Contains(version, ".X")
+}
+
+// compareVersions compares two versions of a package, returning -1, 0 or +1.
+// Go modules, npm packages and crates use semantic versioning; other
+// ecosystems fall back to a segment-wise comparison.
+func compareVersions(ecosystem, v, w string) int {
+	switch ecosystem {
+	case "go", "npm", "cargo":
+		sv, sw := canonicalSemver(v), canonicalSemver(w)
+		if semver.IsValid(sv) && semver.IsValid(sw) {
+			return semver.Compare(sv, sw)
+		}
+	}
+	return compareSegments(v, w)
+}
+
+// canonicalSemver adds the v prefix expected by golang.org/x/mod/semver
+func canonicalSemver(version string) string {
+	if strings.HasPrefix(version, "v") {
+		return version
+	}
+	return "v" + version
+}
+
+// compareSegments compares versions segment by segment: numbers
+// numerically, words lexically and below numbers. A version ending where
+// the other continues with a word (1.0 vs 1.0rc1 or 1.0-SNAPSHOT) is the
+// greater one, except for post-releases and service packs.
+func compareSegments(v, w string) int {
+	a, b := versionSegments(v), versionSegments(w)
+	for i := 0; i < len(a) || i < len(b); i++ {
+		switch {
+		case i >= len(a):
+			return -trailingOrder(b[i])
+		case i >= len(b):
+			return trailingOrder(a[i])
+		}
+
+		an, aErr := strconv.Atoi(a[i])
+		bn, bErr := strconv.Atoi(b[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if an != bn {
+				if an < bn {
+					return -1
+				}
+				return 1
+			}
+		case aErr == nil:
+			return 1
+		case bErr == nil:
+			return -1
+		default:
+			if c := strings.Compare(a[i], b[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return 0
+}
+
+// trailingOrder orders a version with an extra segment against one without
+func trailingOrder(segment string) int {
+	if _, err := strconv.Atoi(segment); err == nil {
+		return 1
+	}
+	switch segment {
+	case "post", "sp", "pl", "patch":
+		return 1
+	case "final", "ga", "release":
+		return 0
+	}
+	return -1
+}
+
+// versionSegments splits a version into lowercase numeric and word segments
+func versionSegments(version string) []string {
+	version = strings.ToLower(strings.TrimPrefix(version, "v"))
+	if i := strings.Index(version, "+"); i >= 0 {
+		version = version[:i] // build metadata does not order
+	}
+
+	var segments []string
+	var current strings.Builder
+	digit := false
+	flush := func() {
+		if current.Len() > 0 {
+			segments = append(segments, current.String())
+			current.Reset()
+		}
+	}
+	for _, r := range version {
+		isDigit := r >= '0' && r <= '9'
+		isLetter := r >= 'a' && r <= 'z'
+		if !isDigit && !isLetter {
+			flush()
+			continue
+		}
+		if current.Len() > 0 && isDigit != digit {
+			flush()
+		}
+		digit = isDigit
+		current.WriteRune(r)
+	}
+	flush()
+	return segments
+}
@@ -0,0 +1,307 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+const (
+	defaultWatchDebounce = 300 * time.Millisecond
+	defaultPollInterval  = 10 * time.Second
+)
+
+// Watch keeps the index of the configured project paths current until ctx is
+// cancelled. Filesystem notifications are debounced and applied incrementally;
+// when they are unavailable (or WatchMode is "poll") the tree is polled instead.
+func (a *ProjectAnalyzer) Watch(ctx context.Context) error {
+	if a.config.WatchMode == "off" {
+		return nil
+	}
+
+	var roots []string
+	for _, root := range a.config.ProjectPaths {
+		if absRoot, err := filepath.Abs(root); err == nil {
+			roots = append(roots, absRoot)
+		}
+	}
+	if len(roots) == 0 {
+		return fmt.Errorf("no project paths to watch")
+	}
+
+	goChanged := false
+	for _, root := range roots {
+		_, rootChanged := a.rescan(root, a.newIgnoreMatcher(root))
+		goChanged = goChanged || rootChanged
+	}
+	if goChanged {
+		a.invalidateTypes()
+	}
+	a.saveCache()
+
+	if a.config.WatchMode != "poll" {
+		watcher, err := a.newTreeWatcher(roots)
+		if err == nil {
+			defer watcher.Close()
+			return a.watchEvents(ctx, watcher, roots)
+		}
+		log.Printf("analyzer: file notifications unavailable, polling instead: %v", err)
+	}
+
+	return a.poll(ctx, roots)
+}
+
+// newTreeWatcher watches every non-ignored directory below roots
+func (a *ProjectAnalyzer) newTreeWatcher(roots []string) (*fsnotify.Watcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, root := range roots {
+		if err := addWatchTree(watcher, root, a.newIgnoreMatcher(root)); err != nil {
+			watcher.Close()
+			return nil, err
+		}
+	}
+
+	return watcher, nil
+}
+
+// addWatchTree adds dir and its subdirectories to the watcher (notifications
+// are not recursive)
+func addWatchTree(watcher *fsnotify.Watcher, dir string, ignore *ignoreMatcher) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		if path != dir && ignore.Match(path, true) {
+			return filepath.SkipDir
+		}
+		return watcher.Add(path)
+	})
+}
+
+func (a *ProjectAnalyzer) watchEvents(ctx context.Context, watcher *fsnotify.Watcher, roots []string) error {
+	debounce := time.Duration(a.config.WatchDebounceMs) * time.Millisecond
+	if debounce <= 0 {
+		debounce = defaultWatchDebounce
+	}
+
+	timer := time.NewTimer(debounce)
+	timer.Stop()
+	pending := make(map[string]bool)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			pending[event.Name] = true
+			timer.Reset(debounce)
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			if errors.Is(err, fsnotify.ErrEventOverflow) {
+				// Events were lost: fall back to a full rescan
+				for _, root := range roots {
+					pending[root] = true
+				}
+				timer.Reset(debounce)
+				continue
+			}
+			log.Printf("analyzer: watch error: %v", err)
+
+		case <-timer.C:
+			a.applyChanges(watcher, roots, pending)
+			pending = make(map[string]bool)
+		}
+	}
+}
+
+// applyChanges updates the index for a debounced batch of changed paths
+func (a *ProjectAnalyzer) applyChanges(watcher *fsnotify.Watcher, roots []string, paths map[string]bool) {
+	matchers := make(map[string]*ignoreMatcher)
+	goChanges := a.cache.GoChanges()
+	changed := false
+
+	for path := range paths {
+		root := containingRoot(roots, path)
+		if root == "" {
+			continue
+		}
+
+		// Ignore rules changed: re-evaluate the whole root
+		if name := filepath.Base(path); name == ".gitignore" || name == ".mcpignore" {
+			path = root
+		}
+
+		ignore, ok := matchers[root]
+		if !ok {
+			ignore = a.newIgnoreMatcher(root)
+			matchers[root] = ignore
+		}
+
+		stat, err := os.Stat(path)
+		switch {
+		case err != nil:
+			// Removed or renamed away; watches on removed directories are dropped automatically
+			if a.cache.DeleteTree(path) {
+				changed = true
+			}
+
+		case stat.IsDir():
+			if path != root && ignore.Match(path, true) {
+				continue
+			}
+			if err := addWatchTree(watcher, path, ignore); err != nil {
+				log.Printf("analyzer: failed to watch %s: %v", path, err)
+			}
+			if dirChanged, _ := a.rescan(path, ignore); dirChanged {
+				changed = true
+			}
+
+		default:
+			if ignore.Match(path, false) {
+				continue
+			}
+			if a.refreshFile(path, stat) {
+				changed = true
+			}
+		}
+	}
+
+	if a.cache.GoChanges() != goChanges {
+		a.invalidateTypes()
+	}
+	if changed {
+		a.saveCache()
+	}
+}
+
+// poll rescans roots periodically
+func (a *ProjectAnalyzer) poll(ctx context.Context, roots []string) error {
+	interval := time.Duration(a.config.WatchPollSeconds) * time.Second
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			changed, goChanged := false, false
+			for _, root := range roots {
+				rootChanged, rootGoChanged := a.rescan(root, a.newIgnoreMatcher(root))
+				changed = changed || rootChanged
+				goChanged = goChanged || rootGoChanged
+			}
+			if goChanged {
+				a.invalidateTypes()
+			}
+			if changed {
+				a.saveCache()
+			}
+		}
+	}
+}
+
+// rescan walks dir like AnalyzeProject, with the configured file limits,
+// re-analyzing changed files on the worker pool and dropping those the walk
+// no longer covers. It reports whether the index changed, and whether a Go
+// source did, which makes type information stale.
+func (a *ProjectAnalyzer) rescan(dir string, ignore *ignoreMatcher) (changed, goChanged bool) {
+	goChanges := a.cache.GoChanges()
+	walk, err := a.walkProject(dir, AnalyzeOptions{}, ignore)
+	if err != nil {
+		log.Printf("analyzer: failed to rescan %s: %v", dir, err)
+		return false, false
+	}
+
+	seen := make(map[string]bool, len(walk.files))
+	var stale []string
+	for _, path := range walk.files {
+		seen[path] = true
+		if stat, err := os.Stat(path); err != nil {
+			stale = append(stale, path)
+		} else if _, ok := a.cache.Get(path, stat); !ok {
+			stale = append(stale, path)
+		}
+	}
+	for _, info := range a.analyzeFiles(stale) {
+		if info != nil {
+			changed = true
+		}
+	}
+
+	prefix := dir + string(filepath.Separator)
+	for _, file := range a.cache.Snapshot() {
+		if strings.HasPrefix(file.Path, prefix) && !seen[file.Path] {
+			if a.cache.Delete(file.Path) {
+				changed = true
+			}
+		}
+	}
+
+	return changed, a.cache.GoChanges() != goChanges
+}
+
+// refreshFile brings the index entry for path up to date, reporting whether
+// it changed. Files over the size limit leave the index, and new files are
+// only added while the index holds fewer than MaxFiles files.
+func (a *ProjectAnalyzer) refreshFile(path string, stat os.FileInfo) bool {
+	if limit := int64(a.config.MaxFileSizeKB) * 1024; limit > 0 && stat.Size() > limit {
+		return a.cache.Delete(path)
+	}
+	if _, ok := a.cache.Get(path, stat); ok {
+		return false
+	}
+	if limit := a.config.MaxFiles; limit > 0 && !a.cache.Live(path) && a.cache.Len() >= limit {
+		return false
+	}
+	_, err := a.analyzeFile(path)
+	return err == nil
+}
+
+func (a *ProjectAnalyzer) saveCache() {
+	if err := a.cache.Save(); err != nil {
+		log.Printf("analyzer: failed to persist cache: %v", err)
+	}
+}
+
+// containingRoot returns the root path contains, if any
+func containingRoot(roots []string, path string) string {
+	for _, root := range roots {
+		if path == root || strings.HasPrefix(path, root+string(filepath.Separator)) {
+			return root
+		}
+	}
+	return ""
+}
+
+// isGoSource reports whether a change to path affects type information
+func isGoSource(path string) bool {
+	switch filepath.Base(path) {
+	case "go.mod", "go.sum", "go.work":
+		return true
+	}
+	return filepath.Ext(path) == ".go"
+}
@@ -0,0 +1,94 @@
+package analyzer
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/scopweb/mcp-go-context/internal/config"
+)
+
+func TestRescanHonoursLimits(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"a.go":          "package a\n",
+		"b.go":          "package a\n",
+		"c.go":          "package a\n",
+		"big.txt":       strings.Repeat("x", 2048),
+		"build/out.txt": "generated\n",
+	})
+	a, err := New(config.ContextConfig{
+		ProjectPaths:   []string{root},
+		IgnorePatterns: []string{"build"},
+		MaxFiles:       2,
+		MaxFileSizeKB:  1,
+		Tokenizer:      "chars",
+	}, config.CacheConfig{}, config.DependencyConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changed, goChanged := a.rescan(root, a.newIgnoreMatcher(root))
+	if !changed || !goChanged {
+		t.Errorf("first rescan = %v, %v; want both changed", changed, goChanged)
+	}
+	var indexed []string
+	for _, file := range a.cache.Snapshot() {
+		rel, _ := filepath.Rel(root, file.Path)
+		indexed = append(indexed, filepath.ToSlash(rel))
+	}
+	if strings.Join(indexed, ",") != "a.go,b.go" {
+		t.Errorf("indexed %q, want the first two files within the limits", indexed)
+	}
+
+	if changed, goChanged := a.rescan(root, a.newIgnoreMatcher(root)); changed || goChanged {
+		t.Errorf("second rescan = %v, %v; want no change", changed, goChanged)
+	}
+}
+
+func TestTypesSurviveWalksWithDifferentLimits(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.mod":           "module example.com/m\n\ngo 1.21\n",
+		"m.go":             "package m\n",
+		"deep/nested/n.go": "package nested\n",
+	})
+	a := newTestAnalyzer(t, root)
+
+	a.rescan(root, a.newIgnoreMatcher(root))
+	index, err := a.TypeIndex(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	same := func() bool {
+		current, err := a.TypeIndex(root)
+		return err == nil && current == index
+	}
+
+	// A shallow analysis and a full rescan cover different files
+	for i := 0; i < 2; i++ {
+		if _, err := a.AnalyzeProject(root, 1); err != nil {
+			t.Fatal(err)
+		}
+		if _, goChanged := a.rescan(root, a.newIgnoreMatcher(root)); goChanged {
+			t.Fatal("rescan reported a Go change without one")
+		}
+		a.rescan(filepath.Join(root, "deep"), a.newIgnoreMatcher(root))
+		if !same() {
+			t.Fatalf("walk %d dropped type information without a Go change", i)
+		}
+	}
+
+	writeFiles(t, root, map[string]string{"deep/nested/n.go": "package nested\n\nfunc N() {}\n"})
+	if _, err := a.AnalyzeProject(root, 0); err != nil {
+		t.Fatal(err)
+	}
+	if same() {
+		t.Error("type information kept after a Go change")
+	}
+
+	writeFiles(t, root, map[string]string{"m.go": "package m\n\nfunc M() {}\n"})
+	if _, goChanged := a.rescan(root, a.newIgnoreMatcher(root)); !goChanged {
+		t.Error("rescan missed the changed Go file")
+	}
+}
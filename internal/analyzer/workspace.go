@@ -0,0 +1,81 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// GoModule is a Go module belonging to the project
+type GoModule struct {
+	Path      string // module path declared in go.mod
+	Dir       string
+	Workspace string // go.work file listing the module, if any
+}
+
+// GoModules returns the modules of every configured project path. A go.work
+// file at or above a project path contributes all of its used modules.
+func (a *ProjectAnalyzer) GoModules() []GoModule {
+	var modules []GoModule
+	seen := make(map[string]bool)
+
+	for _, root := range a.config.ProjectPaths {
+		absRoot, err := filepath.Abs(root)
+		if err != nil {
+			continue
+		}
+		for _, module := range goModulesIn(absRoot) {
+			if !seen[module.Dir] {
+				seen[module.Dir] = true
+				modules = append(modules, module)
+			}
+		}
+	}
+
+	return modules
+}
+
+// goModulesIn returns the modules governing root: those of its go.work file,
+// or the module containing it
+func goModulesIn(root string) []GoModule {
+	var modules []GoModule
+
+	add := func(dir, workspace string) {
+		path := readModulePath(filepath.Join(dir, "go.mod"))
+		if path != "" {
+			modules = append(modules, GoModule{Path: path, Dir: dir, Workspace: workspace})
+		}
+	}
+
+	if workFile := findWorkFile(root); workFile != "" {
+		for _, dir := range readWorkspaceModules(workFile) {
+			add(dir, workFile)
+		}
+	} else if dir := findModuleRoot(root); dir != "" {
+		add(dir, "")
+	}
+
+	return modules
+}
+
+// findWorkFile returns the go.work file governing dir, honoring GOWORK
+func findWorkFile(dir string) string {
+	switch gowork := os.Getenv("GOWORK"); gowork {
+	case "off":
+		return ""
+	case "":
+	default:
+		return gowork
+	}
+
+	for {
+		path := filepath.Join(dir, "go.work")
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
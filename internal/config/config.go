@@ -8,10 +8,12 @@ import (
 
 // Config represents the server configuration
 type Config struct {
-	Transport TransportConfig `json:"transport"`
-	Context   ContextConfig   `json:"context"`
-	Cache     CacheConfig     `json:"cache"`
-	Memory    MemoryConfig    `json:"memory"`
+	Transport    TransportConfig  `json:"transport"`
+	Context      ContextConfig    `json:"context"`
+	Cache        CacheConfig      `json:"cache"`
+	Memory       MemoryConfig     `json:"memory"`
+	Proxy        ProxyConfig      `json:"proxy"`
+	Dependencies DependencyConfig `json:"dependencies"`
 }
 
 // TransportConfig defines transport settings
@@ -22,12 +24,33 @@ type TransportConfig struct {
 
 // ContextConfig defines context analysis settings
 type ContextConfig struct {
-	MaxTokens         int      `json:"maxTokens"`
-	DefaultLibraries  []string `json:"defaultLibraries"`
-	ProjectPaths      []string `json:"projectPaths"`
-	IgnorePatterns    []string `json:"ignorePatterns"`
-	AutoDetectDeps    bool     `json:"autoDetectDeps"`
-	ContextWindowSize int      `json:"contextWindowSize"`
+	MaxTokens         int             `json:"maxTokens"`
+	DefaultLibraries  []string        `json:"defaultLibraries"`
+	ProjectPaths      []string        `json:"projectPaths"`
+	IgnorePatterns    []string        `json:"ignorePatterns"`
+	AutoDetectDeps    bool            `json:"autoDetectDeps"`
+	ContextWindowSize int             `json:"contextWindowSize"`
+	MaxFiles          int             `json:"maxFiles"`
+	MaxFileSizeKB     int             `json:"maxFileSizeKB"`
+	Concurrency       int             `json:"concurrency"` // parallel file analysis workers, 0 = number of CPUs
+	WatchMode         string          `json:"watchMode"`   // auto (default: notifications, polling fallback), poll, off
+	WatchDebounceMs   int             `json:"watchDebounceMs"`
+	WatchPollSeconds  int             `json:"watchPollSeconds"`
+	Embeddings        EmbeddingConfig `json:"embeddings"`
+	Tokenizer         string          `json:"tokenizer"`      // bpe (default), chars (4 characters per token)
+	TokenizerRanks    string          `json:"tokenizerRanks"` // tiktoken rank file for bpe, e.g. cl100k_base.tiktoken; empty uses the bundled one
+}
+
+// EmbeddingConfig defines the embedding provider used for semantic search
+type EmbeddingConfig struct {
+	Provider       string  `json:"provider"`   // hash (built-in), openai (any OpenAI-compatible server), off
+	URL            string  `json:"url"`        // API base URL, e.g. http://localhost:11434/v1
+	Model          string  `json:"model"`      // model name sent to the provider
+	APIKeyEnv      string  `json:"apiKeyEnv"`  // environment variable holding the API key
+	Dimensions     int     `json:"dimensions"` // vector size of the hash provider
+	BatchSize      int     `json:"batchSize"`  // texts per embedding request
+	Weight         float64 `json:"weight"`     // share of vector similarity in the blended ranking, 0-1
+	TimeoutSeconds int     `json:"timeoutSeconds"`
 }
 
 // CacheConfig defines caching settings
@@ -38,6 +61,15 @@ type CacheConfig struct {
 	TTLMinutes int    `json:"ttlMinutes"`
 }
 
+// DependencyConfig defines dependency analysis settings
+type DependencyConfig struct {
+	OSVDatabase  string   `json:"osvDatabase"`  // directory or zip of OSV advisories, empty disables scanning
+	LicenseAllow []string `json:"licenseAllow"` // SPDX licenses permitted; when set, any other license is a violation
+	LicenseDeny  []string `json:"licenseDeny"`  // SPDX licenses never permitted; "GPL-*" matches a family
+	GoProxy      string   `json:"goProxy"`      // GOPROXY-protocol URL (http, https or file) queried for newer module versions
+	NPMRegistry  string   `json:"npmRegistry"`  // npm registry URL (http, https or file) queried for newer package versions
+}
+
 // MemoryConfig defines conversation memory settings
 type MemoryConfig struct {
 	Enabled        bool   `json:"enabled"`
@@ -49,6 +81,27 @@ type MemoryConfig struct {
 	MaxSessions    int    `json:"maxSessions"`
 }
 
+// ProxyConfig defines upstream MCP servers mounted by this server
+type ProxyConfig struct {
+	Enabled   bool             `json:"enabled"`
+	Separator string           `json:"separator"` // between upstream name and tool/prompt name
+	Upstreams []UpstreamConfig `json:"upstreams"`
+}
+
+// UpstreamConfig describes a single upstream MCP server
+type UpstreamConfig struct {
+	Name           string            `json:"name"`
+	Transport      string            `json:"transport"` // stdio, http
+	Command        string            `json:"command"`
+	Args           []string          `json:"args"`
+	Env            map[string]string `json:"env"`
+	Framing        string            `json:"framing"` // newline, content-length (stdio only)
+	URL            string            `json:"url"`
+	Headers        map[string]string `json:"headers"`
+	TimeoutSeconds int               `json:"timeoutSeconds"`
+	Disabled       bool              `json:"disabled"`
+}
+
 // DefaultConfig returns default configuration
 func DefaultConfig() *Config {
 	homeDir, _ := os.UserHomeDir()
@@ -65,6 +118,19 @@ func DefaultConfig() *Config {
 			IgnorePatterns:    []string{"*.log", "*.tmp", "node_modules", ".git", "vendor"},
 			AutoDetectDeps:    true,
 			ContextWindowSize: 5000,
+			MaxFiles:          20000,
+			MaxFileSizeKB:     1024,
+			WatchMode:         "auto",
+			WatchDebounceMs:   300,
+			WatchPollSeconds:  10,
+			Tokenizer:         "bpe",
+			Embeddings: EmbeddingConfig{
+				Provider:       "hash",
+				Dimensions:     256,
+				BatchSize:      32,
+				Weight:         0.3,
+				TimeoutSeconds: 30,
+			},
 		},
 		Cache: CacheConfig{
 			Enabled:    true,
@@ -81,6 +147,11 @@ func DefaultConfig() *Config {
 			SessionTTLDays: 30,
 			MaxSessions:    100,
 		},
+		Proxy: ProxyConfig{
+			Enabled:   false,
+			Separator: "__",
+			Upstreams: []UpstreamConfig{},
+		},
 	}
 }
 
@@ -9,7 +9,7 @@ import (
 	"sync"
 	"time"
 
-	"github.com/scopweb/mcp-context-server/internal/config"
+	"github.com/scopweb/mcp-go-context/internal/config"
 )
 
 // Manager handles conversation memory persistence
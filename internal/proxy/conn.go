@@ -0,0 +1,465 @@
+package proxy
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Conn is a JSON-RPC connection to an upstream MCP server
+type Conn interface {
+	Call(ctx context.Context, method string, params interface{}) (json.RawMessage, error)
+	Notify(ctx context.Context, method string, params interface{}) error
+	Close() error
+}
+
+// rpcMessage covers requests, notifications and responses
+type rpcMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("upstream error %d: %s", e.Code, e.Message)
+}
+
+// requestHandler answers a request an upstream sends to the proxy
+type requestHandler func(method string, params json.RawMessage) (interface{}, error)
+
+// reply runs handle for a server-initiated request and builds the response
+func reply(handle requestHandler, msg *rpcMessage) map[string]interface{} {
+	response := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      msg.ID,
+	}
+	result, err := handle(msg.Method, msg.Params)
+	if err != nil {
+		rpcErr, ok := err.(*rpcError)
+		if !ok {
+			rpcErr = &rpcError{Code: -32603, Message: err.Error()}
+		}
+		response["error"] = rpcErr
+	} else {
+		response["result"] = result
+	}
+	return response
+}
+
+// stdioConn talks to an upstream process over its stdin/stdout
+type stdioConn struct {
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	reader  *bufio.Reader
+	framing string
+	handle  requestHandler
+
+	nextID  int64
+	writeMu sync.Mutex
+	mu      sync.Mutex
+	pending map[int64]chan *rpcMessage
+	closed  chan struct{}
+	err     error
+}
+
+// newStdioConn spawns command in dir and starts reading its responses;
+// requests from the process are answered by handle
+func newStdioConn(command string, args []string, env map[string]string, dir, framing string, handle requestHandler) (*stdioConn, error) {
+	cmd := exec.Command(command, args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "MCP_PROJECT_ROOT="+dir)
+	for k, v := range env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+	cmd.Stderr = os.Stderr
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start %s: %w", command, err)
+	}
+
+	if framing == "" {
+		framing = "newline"
+	}
+
+	c := &stdioConn{
+		cmd:     cmd,
+		stdin:   stdin,
+		reader:  bufio.NewReader(stdout),
+		framing: framing,
+		handle:  handle,
+		pending: make(map[int64]chan *rpcMessage),
+		closed:  make(chan struct{}),
+	}
+
+	go c.readLoop()
+
+	return c, nil
+}
+
+// Call sends a request and waits for the matching response
+func (c *stdioConn) Call(ctx context.Context, method string, params interface{}) (json.RawMessage, error) {
+	id := atomic.AddInt64(&c.nextID, 1)
+	ch := make(chan *rpcMessage, 1)
+
+	c.mu.Lock()
+	c.pending[id] = ch
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	if err := c.send(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"method":  method,
+		"params":  params,
+	}); err != nil {
+		return nil, err
+	}
+
+	select {
+	case msg := <-ch:
+		if msg.Error != nil {
+			return nil, msg.Error
+		}
+		return msg.Result, nil
+	case <-c.closed:
+		return nil, fmt.Errorf("upstream closed: %v", c.err)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Notify sends a notification without waiting for a reply
+func (c *stdioConn) Notify(ctx context.Context, method string, params interface{}) error {
+	msg := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  method,
+	}
+	if params != nil {
+		msg["params"] = params
+	}
+	return c.send(msg)
+}
+
+// Close terminates the upstream process
+func (c *stdioConn) Close() error {
+	c.stdin.Close()
+	if c.cmd.Process != nil {
+		c.cmd.Process.Kill()
+	}
+	return c.cmd.Wait()
+}
+
+func (c *stdioConn) send(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	if c.framing == "content-length" {
+		if _, err := fmt.Fprintf(c.stdin, "Content-Length: %d\r\n\r\n", len(data)); err != nil {
+			return err
+		}
+		_, err = c.stdin.Write(data)
+		return err
+	}
+
+	_, err = c.stdin.Write(append(data, '\n'))
+	return err
+}
+
+func (c *stdioConn) readLoop() {
+	defer close(c.closed)
+
+	for {
+		data, err := c.readMessage()
+		if err != nil {
+			c.err = err
+			return
+		}
+
+		var msg rpcMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			log.Printf("proxy: skipping invalid upstream message: %v", err)
+			continue
+		}
+
+		// Server-initiated requests; notifications are ignored
+		if msg.Method != "" {
+			if len(msg.ID) > 0 {
+				c.send(reply(c.handle, &msg))
+			}
+			continue
+		}
+
+		var id int64
+		if err := json.Unmarshal(msg.ID, &id); err != nil {
+			continue
+		}
+
+		c.mu.Lock()
+		ch, ok := c.pending[id]
+		c.mu.Unlock()
+		if ok {
+			ch <- &msg
+		}
+	}
+}
+
+// readMessage accepts both newline-delimited and Content-Length framed messages
+func (c *stdioConn) readMessage() ([]byte, error) {
+	for {
+		line, err := c.reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		if !strings.HasPrefix(strings.ToLower(line), "content-length:") {
+			return []byte(line), nil
+		}
+
+		var length int
+		if _, err := fmt.Sscanf(strings.TrimSpace(line[len("content-length:"):]), "%d", &length); err != nil {
+			return nil, fmt.Errorf("invalid Content-Length: %w", err)
+		}
+
+		// Skip remaining headers
+		for {
+			header, err := c.reader.ReadString('\n')
+			if err != nil {
+				return nil, err
+			}
+			if strings.TrimSpace(header) == "" {
+				break
+			}
+		}
+
+		content := make([]byte, length)
+		if _, err := io.ReadFull(c.reader, content); err != nil {
+			return nil, err
+		}
+		return content, nil
+	}
+}
+
+// httpConn talks to an upstream over HTTP POST (plain JSON or SSE responses)
+type httpConn struct {
+	url       string
+	headers   map[string]string
+	client    *http.Client
+	handle    requestHandler
+	nextID    int64
+	sessionID atomic.Value
+}
+
+func newHTTPConn(url string, headers map[string]string, client *http.Client, handle requestHandler) *httpConn {
+	return &httpConn{
+		url:     url,
+		headers: headers,
+		client:  client,
+		handle:  handle,
+	}
+}
+
+// Call posts a request and decodes the response
+func (c *httpConn) Call(ctx context.Context, method string, params interface{}) (json.RawMessage, error) {
+	id := json.RawMessage(strconv.FormatInt(atomic.AddInt64(&c.nextID, 1), 10))
+
+	body, err := c.post(ctx, id, map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var msg rpcMessage
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return nil, fmt.Errorf("invalid upstream response: %w", err)
+	}
+	if msg.Error != nil {
+		return nil, msg.Error
+	}
+
+	return msg.Result, nil
+}
+
+// Notify posts a notification and ignores the body
+func (c *httpConn) Notify(ctx context.Context, method string, params interface{}) error {
+	msg := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  method,
+	}
+	if params != nil {
+		msg["params"] = params
+	}
+	_, err := c.post(ctx, nil, msg)
+	return err
+}
+
+// Close is a no-op for HTTP upstreams
+func (c *httpConn) Close() error {
+	return nil
+}
+
+// post sends a message, returning the body of the response to the request
+// with the given id, if any
+func (c *httpConn) post(ctx context.Context, id json.RawMessage, v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	for k, v := range c.headers {
+		req.Header.Set(k, v)
+	}
+	if sid, ok := c.sessionID.Load().(string); ok && sid != "" {
+		req.Header.Set("Mcp-Session-Id", sid)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if sid := resp.Header.Get("Mcp-Session-Id"); sid != "" {
+		c.sessionID.Store(sid)
+	}
+
+	if resp.StatusCode == http.StatusAccepted || resp.StatusCode == http.StatusNoContent {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("upstream returned status %d", resp.StatusCode)
+	}
+
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		// Requests sent on the stream are answered with a POST of their own
+		return readSSEResponse(resp.Body, id, func(msg *rpcMessage) {
+			if _, err := c.post(ctx, nil, reply(c.handle, msg)); err != nil {
+				log.Printf("proxy: failed to answer upstream %s request: %v", msg.Method, err)
+			}
+		})
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+// readSSEResponse returns the response to the request with the given id
+// carried by an event stream, passing the requests that precede it to
+// onRequest. Notifications and other responses are skipped; a nil id accepts
+// any response.
+func readSSEResponse(r io.Reader, id json.RawMessage, onRequest func(*rpcMessage)) ([]byte, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	var data strings.Builder
+	hasData := false
+
+	// dispatch handles a complete event, returning its data if it is the response
+	dispatch := func() []byte {
+		if !hasData {
+			return nil
+		}
+		payload := strings.TrimSuffix(data.String(), "\n")
+		data.Reset()
+		hasData = false
+
+		var msg rpcMessage
+		if err := json.Unmarshal([]byte(payload), &msg); err != nil {
+			return nil
+		}
+		switch {
+		case msg.Method != "":
+			if len(msg.ID) > 0 {
+				onRequest(&msg)
+			}
+		case id == nil || sameID(msg.ID, id):
+			return []byte(payload)
+		}
+		return nil
+	}
+
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if line == "" {
+			if body := dispatch(); body != nil {
+				return body, nil
+			}
+			continue
+		}
+
+		// Data lines of one event are joined with newlines; comments
+		// (lines starting with a colon) and other fields are ignored
+		if field, value, _ := strings.Cut(line, ":"); field == "data" {
+			data.WriteString(strings.TrimPrefix(value, " "))
+			data.WriteByte('\n')
+			hasData = true
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	// The stream may end without the blank line closing its last event
+	if body := dispatch(); body != nil {
+		return body, nil
+	}
+
+	return nil, fmt.Errorf("no response in event stream")
+}
+
+// sameID compares JSON-RPC ids, ignoring surrounding whitespace
+func sameID(a, b json.RawMessage) bool {
+	return bytes.Equal(bytes.TrimSpace(a), bytes.TrimSpace(b))
+}
@@ -0,0 +1,468 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/scopweb/mcp-go-context/internal/config"
+)
+
+// ProtocolVersion is the MCP protocol version announced to upstreams
+const ProtocolVersion = "2024-11-05"
+
+// Hub mounts upstream MCP servers and namespaces their capabilities
+type Hub struct {
+	config    config.ProxyConfig
+	workDir   string
+	roots     []Root
+	upstreams map[string]*Upstream
+	order     []string
+	mu        sync.RWMutex
+}
+
+// Upstream is a connected upstream MCP server
+type Upstream struct {
+	Name      string
+	Conn      Conn
+	Tools     []Tool
+	Resources []Resource
+	Prompts   []Prompt
+	timeout   time.Duration
+}
+
+// Tool is a tool advertised by an upstream
+type Tool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	InputSchema map[string]interface{} `json:"inputSchema"`
+}
+
+// Resource is a resource advertised by an upstream
+type Resource struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+// Root is a project directory shared with upstreams through roots/list
+type Root struct {
+	URI  string `json:"uri"`
+	Name string `json:"name,omitempty"`
+}
+
+// Prompt is a prompt advertised by an upstream
+type Prompt struct {
+	Name        string        `json:"name"`
+	Description string        `json:"description,omitempty"`
+	Arguments   []interface{} `json:"arguments,omitempty"`
+}
+
+// New creates a hub for the project paths: upstreams list them as their
+// roots, and stdio upstreams run in the first one
+func New(cfg config.ProxyConfig, projectPaths []string) *Hub {
+	if cfg.Separator == "" {
+		cfg.Separator = "__"
+	}
+
+	h := &Hub{
+		config:    cfg,
+		workDir:   ".",
+		upstreams: make(map[string]*Upstream),
+	}
+	if len(projectPaths) > 0 {
+		h.workDir = projectPaths[0]
+	}
+	for _, path := range projectPaths {
+		if absPath, err := filepath.Abs(path); err == nil {
+			h.roots = append(h.roots, Root{URI: fileURI(absPath), Name: filepath.Base(absPath)})
+		}
+	}
+	return h
+}
+
+// fileURI converts an absolute path to a file:// URI
+func fileURI(path string) string {
+	slashPath := filepath.ToSlash(path)
+	if !strings.HasPrefix(slashPath, "/") {
+		slashPath = "/" + slashPath // Windows drive letter
+	}
+	return (&url.URL{Scheme: "file", Path: slashPath}).String()
+}
+
+// handleRequest answers the requests upstreams send to the proxy as their
+// client: ping, and roots/list with the project paths
+func (h *Hub) handleRequest(method string, params json.RawMessage) (interface{}, error) {
+	switch method {
+	case "ping":
+		return map[string]interface{}{}, nil
+	case "roots/list":
+		roots := h.roots
+		if roots == nil {
+			roots = []Root{}
+		}
+		return map[string]interface{}{"roots": roots}, nil
+	}
+	return nil, &rpcError{Code: -32601, Message: "method not found: " + method}
+}
+
+// Start connects to every configured upstream. Failing upstreams are logged and skipped.
+func (h *Hub) Start(ctx context.Context) {
+	for _, up := range h.config.Upstreams {
+		if up.Disabled {
+			continue
+		}
+
+		upstream, err := h.connect(ctx, up)
+		if err != nil {
+			log.Printf("proxy: upstream %s unavailable: %v", up.Name, err)
+			continue
+		}
+
+		h.mu.Lock()
+		h.upstreams[up.Name] = upstream
+		h.order = append(h.order, up.Name)
+		h.mu.Unlock()
+
+		log.Printf("proxy: mounted %s (%d tools, %d resources, %d prompts)",
+			up.Name, len(upstream.Tools), len(upstream.Resources), len(upstream.Prompts))
+	}
+}
+
+// Close shuts down all upstream connections
+func (h *Hub) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, up := range h.upstreams {
+		up.Conn.Close()
+	}
+	h.upstreams = make(map[string]*Upstream)
+	h.order = nil
+
+	return nil
+}
+
+// Upstreams returns the mounted upstreams in configuration order
+func (h *Hub) Upstreams() []*Upstream {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	result := make([]*Upstream, 0, len(h.order))
+	for _, name := range h.order {
+		result = append(result, h.upstreams[name])
+	}
+	return result
+}
+
+// ToolName returns the namespaced name of an upstream tool or prompt
+func (h *Hub) ToolName(upstream, name string) string {
+	return upstream + h.config.Separator + name
+}
+
+// ResourceURI returns the namespaced URI of an upstream resource
+func (h *Hub) ResourceURI(upstream, uri string) string {
+	return upstream + "+" + uri
+}
+
+// CallTool forwards a tool call to its upstream
+func (h *Hub) CallTool(upstream, name string, args json.RawMessage) (json.RawMessage, error) {
+	up, err := h.get(upstream)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(args) == 0 {
+		args = json.RawMessage("{}")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), up.timeout)
+	defer cancel()
+
+	return up.Conn.Call(ctx, "tools/call", map[string]interface{}{
+		"name":      name,
+		"arguments": args,
+	})
+}
+
+// ListResources returns all upstream resources with namespaced URIs
+func (h *Hub) ListResources() []Resource {
+	var resources []Resource
+	for _, up := range h.Upstreams() {
+		for _, r := range up.Resources {
+			r.URI = h.ResourceURI(up.Name, r.URI)
+			r.Name = h.ToolName(up.Name, r.Name)
+			resources = append(resources, r)
+		}
+	}
+	return resources
+}
+
+// ReadResource forwards resources/read for a namespaced URI
+func (h *Hub) ReadResource(uri string) (json.RawMessage, error) {
+	name, original, ok := strings.Cut(uri, "+")
+	if !ok {
+		return nil, fmt.Errorf("unknown resource: %s", uri)
+	}
+
+	up, err := h.get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), up.timeout)
+	defer cancel()
+
+	result, err := up.Conn.Call(ctx, "resources/read", map[string]interface{}{"uri": original})
+	if err != nil {
+		return nil, err
+	}
+
+	return h.rewriteContentURIs(name, result), nil
+}
+
+// ListPrompts returns all upstream prompts with namespaced names
+func (h *Hub) ListPrompts() []Prompt {
+	var prompts []Prompt
+	for _, up := range h.Upstreams() {
+		for _, p := range up.Prompts {
+			p.Name = h.ToolName(up.Name, p.Name)
+			prompts = append(prompts, p)
+		}
+	}
+	return prompts
+}
+
+// GetPrompt forwards prompts/get for a namespaced prompt name
+func (h *Hub) GetPrompt(name string, args json.RawMessage) (json.RawMessage, error) {
+	upstream, original, ok := strings.Cut(name, h.config.Separator)
+	if !ok {
+		return nil, fmt.Errorf("unknown prompt: %s", name)
+	}
+
+	up, err := h.get(upstream)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), up.timeout)
+	defer cancel()
+
+	params := map[string]interface{}{"name": original}
+	if len(args) > 0 {
+		params["arguments"] = args
+	}
+
+	return up.Conn.Call(ctx, "prompts/get", params)
+}
+
+// Private methods
+
+func (h *Hub) get(name string) (*Upstream, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	up, exists := h.upstreams[name]
+	if !exists {
+		return nil, fmt.Errorf("upstream %s not mounted", name)
+	}
+	return up, nil
+}
+
+func (h *Hub) connect(ctx context.Context, cfg config.UpstreamConfig) (*Upstream, error) {
+	if cfg.Name == "" {
+		return nil, fmt.Errorf("upstream name required")
+	}
+	if strings.Contains(cfg.Name, "+") || strings.Contains(cfg.Name, h.config.Separator) {
+		return nil, fmt.Errorf("upstream name must not contain '+' or %q", h.config.Separator)
+	}
+
+	timeout := time.Duration(cfg.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = 60 * time.Second
+	}
+
+	var conn Conn
+	switch cfg.Transport {
+	case "", "stdio":
+		if cfg.Command == "" {
+			return nil, fmt.Errorf("command required for stdio upstream")
+		}
+		c, err := newStdioConn(cfg.Command, cfg.Args, cfg.Env, h.workDir, cfg.Framing, h.handleRequest)
+		if err != nil {
+			return nil, err
+		}
+		conn = c
+	case "http":
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("url required for http upstream")
+		}
+		conn = newHTTPConn(cfg.URL, cfg.Headers, &http.Client{Timeout: timeout}, h.handleRequest)
+	default:
+		return nil, fmt.Errorf("unknown upstream transport: %s", cfg.Transport)
+	}
+
+	up := &Upstream{
+		Name:    cfg.Name,
+		Conn:    conn,
+		timeout: timeout,
+	}
+
+	if err := up.initialize(ctx); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return up, nil
+}
+
+// initialize performs the MCP handshake and caches the upstream's listings
+func (u *Upstream) initialize(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	result, err := u.Conn.Call(ctx, "initialize", map[string]interface{}{
+		"protocolVersion": ProtocolVersion,
+		"capabilities": map[string]interface{}{
+			"roots": map[string]interface{}{"listChanged": false},
+		},
+		"clientInfo": map[string]string{
+			"name":    "mcp-context-server-proxy",
+			"version": "1.0.0",
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("initialize failed: %w", err)
+	}
+
+	var init struct {
+		Capabilities map[string]json.RawMessage `json:"capabilities"`
+	}
+	json.Unmarshal(result, &init)
+
+	if err := u.Conn.Notify(ctx, "notifications/initialized", nil); err != nil {
+		return fmt.Errorf("initialized notification failed: %w", err)
+	}
+
+	if _, ok := init.Capabilities["tools"]; ok || init.Capabilities == nil {
+		var list struct {
+			Tools []Tool `json:"tools"`
+		}
+		if err := u.list(ctx, "tools/list", &list); err != nil {
+			return err
+		}
+		u.Tools = list.Tools
+	}
+
+	if _, ok := init.Capabilities["resources"]; ok {
+		var list struct {
+			Resources []Resource `json:"resources"`
+		}
+		if err := u.list(ctx, "resources/list", &list); err == nil {
+			u.Resources = list.Resources
+		}
+	}
+
+	if _, ok := init.Capabilities["prompts"]; ok {
+		var list struct {
+			Prompts []Prompt `json:"prompts"`
+		}
+		if err := u.list(ctx, "prompts/list", &list); err == nil {
+			u.Prompts = list.Prompts
+		}
+	}
+
+	return nil
+}
+
+// list calls a paginated list method and merges every page into out
+func (u *Upstream) list(ctx context.Context, method string, out interface{}) error {
+	var merged map[string][]json.RawMessage
+	cursor := ""
+
+	for {
+		params := map[string]interface{}{}
+		if cursor != "" {
+			params["cursor"] = cursor
+		}
+
+		result, err := u.Conn.Call(ctx, method, params)
+		if err != nil {
+			return fmt.Errorf("%s failed: %w", method, err)
+		}
+
+		var page map[string]json.RawMessage
+		if err := json.Unmarshal(result, &page); err != nil {
+			return fmt.Errorf("invalid %s result: %w", method, err)
+		}
+
+		if merged == nil {
+			merged = make(map[string][]json.RawMessage)
+		}
+		for key, value := range page {
+			if key == "nextCursor" {
+				continue
+			}
+			var items []json.RawMessage
+			if json.Unmarshal(value, &items) == nil {
+				merged[key] = append(merged[key], items...)
+			}
+		}
+
+		var next struct {
+			NextCursor string `json:"nextCursor"`
+		}
+		json.Unmarshal(result, &next)
+		if next.NextCursor == "" || next.NextCursor == cursor {
+			break
+		}
+		cursor = next.NextCursor
+	}
+
+	data, err := json.Marshal(merged)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// rewriteContentURIs namespaces the URIs inside a resources/read result,
+// leaving every other field as the upstream sent it
+func (h *Hub) rewriteContentURIs(upstream string, result json.RawMessage) json.RawMessage {
+	var read map[string]json.RawMessage
+	if err := json.Unmarshal(result, &read); err != nil {
+		return result
+	}
+	var contents []map[string]json.RawMessage
+	if err := json.Unmarshal(read["contents"], &contents); err != nil {
+		return result
+	}
+
+	for _, content := range contents {
+		var uri string
+		if err := json.Unmarshal(content["uri"], &uri); err != nil {
+			continue
+		}
+		if data, err := json.Marshal(h.ResourceURI(upstream, uri)); err == nil {
+			content["uri"] = data
+		}
+	}
+
+	data, err := json.Marshal(contents)
+	if err != nil {
+		return result
+	}
+	read["contents"] = data
+	if data, err = json.Marshal(read); err != nil {
+		return result
+	}
+	return data
+}
@@ -0,0 +1,165 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/scopweb/mcp-go-context/internal/config"
+)
+
+func TestRewriteContentURIs(t *testing.T) {
+	h := New(config.ProxyConfig{}, nil)
+	result := json.RawMessage(`{"contents":[{"uri":"file:///a.txt","text":"x","size":12345678901}],"_meta":{"etag":"1"}}`)
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(h.rewriteContentURIs("docs", result), &got); err != nil {
+		t.Fatal(err)
+	}
+	content := got["contents"].([]interface{})[0].(map[string]interface{})
+	if content["uri"] != "docs+file:///a.txt" {
+		t.Errorf("uri = %v, want docs+file:///a.txt", content["uri"])
+	}
+	if content["text"] != "x" {
+		t.Errorf("text = %v, want x", content["text"])
+	}
+	if meta, ok := got["_meta"].(map[string]interface{}); !ok || meta["etag"] != "1" {
+		t.Errorf("_meta = %v, want it kept", got["_meta"])
+	}
+}
+
+func TestHandleRequest(t *testing.T) {
+	root := t.TempDir()
+	h := New(config.ProxyConfig{}, []string{root})
+
+	result, err := h.handleRequest("roots/list", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(result)
+	var list struct {
+		Roots []Root `json:"roots"`
+	}
+	if err := json.Unmarshal(data, &list); err != nil {
+		t.Fatal(err)
+	}
+	want := "file://" + filepath.ToSlash(root)
+	if len(list.Roots) != 1 || list.Roots[0].URI != want || list.Roots[0].Name != filepath.Base(root) {
+		t.Errorf("roots/list = %s, want %s", data, want)
+	}
+
+	if _, err := h.handleRequest("ping", nil); err != nil {
+		t.Errorf("ping error = %v", err)
+	}
+	if _, err := h.handleRequest("sampling/createMessage", nil); err == nil {
+		t.Error("unknown method succeeded, want method not found")
+	} else if rpcErr, ok := err.(*rpcError); !ok || rpcErr.Code != -32601 {
+		t.Errorf("unknown method error = %v, want code -32601", err)
+	}
+}
+
+func TestReadSSEResponse(t *testing.T) {
+	tests := []struct {
+		name     string
+		stream   string
+		id       string
+		want     string
+		requests string
+	}{
+		{
+			name: "requests and notifications before the response",
+			stream: "event: message\ndata: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/progress\"}\n\n" +
+				"data: {\"jsonrpc\":\"2.0\",\"id\":7,\"method\":\"roots/list\"}\n\n" +
+				"data: {\"jsonrpc\":\"2.0\",\"id\":1,\"result\":{}}\n\n",
+			id:       "1",
+			want:     `{"jsonrpc":"2.0","id":1,"result":{}}`,
+			requests: "roots/list",
+		},
+		{
+			name: "responses to other requests are skipped",
+			stream: "data: {\"jsonrpc\":\"2.0\",\"id\":2,\"result\":{\"stale\":true}}\n\n" +
+				"data: {\"jsonrpc\":\"2.0\",\"id\":3,\"result\":{}}\n\n",
+			id:   "3",
+			want: `{"jsonrpc":"2.0","id":3,"result":{}}`,
+		},
+		{
+			name:   "data lines are joined with newlines",
+			stream: ": keep-alive\ndata: {\"jsonrpc\":\"2.0\",\ndata:\"id\":4,\ndata:  \"result\":\"a\"}\n\n",
+			id:     "4",
+			want:   "{\"jsonrpc\":\"2.0\",\n\"id\":4,\n \"result\":\"a\"}",
+		},
+		{
+			name:   "CRLF line endings and an unterminated last event",
+			stream: "retry: 1000\r\ndata: {\"jsonrpc\":\"2.0\",\"id\":5,\"result\":{}}\r\n",
+			id:     "5",
+			want:   `{"jsonrpc":"2.0","id":5,"result":{}}`,
+		},
+		{
+			name:   "no matching response",
+			stream: "data: {\"jsonrpc\":\"2.0\",\"id\":6,\"result\":{}}\n\n",
+			id:     "9",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []string
+			body, err := readSSEResponse(strings.NewReader(tt.stream), json.RawMessage(tt.id), func(msg *rpcMessage) {
+				requests = append(requests, msg.Method)
+			})
+			if tt.want == "" {
+				if err == nil {
+					t.Errorf("response = %s, want none", body)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(body) != tt.want {
+				t.Errorf("response = %q, want %q", body, tt.want)
+			}
+			if got := strings.Join(requests, ","); got != tt.requests {
+				t.Errorf("requests = %q, want %q", got, tt.requests)
+			}
+		})
+	}
+}
+
+func TestReply(t *testing.T) {
+	h := New(config.ProxyConfig{}, nil)
+	msg := &rpcMessage{ID: json.RawMessage(`"a"`), Method: "roots/list"}
+	data, _ := json.Marshal(reply(h.handleRequest, msg))
+	if string(data) != `{"id":"a","jsonrpc":"2.0","result":{"roots":[]}}` {
+		t.Errorf("reply = %s", data)
+	}
+}
+
+func TestHTTPConnSSECall(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg rpcMessage
+		json.NewDecoder(r.Body).Decode(&msg)
+		if msg.Method != "tools/list" {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprintf(w, "data: {\"jsonrpc\":\"2.0\",\"id\":%s0,\"result\":{\"tools\":[\"stale\"]}}\n\n", msg.ID)
+		fmt.Fprintf(w, "data: {\"jsonrpc\":\"2.0\",\"id\":%s,\ndata: \"result\":{\"tools\":[]}}\n\n", msg.ID)
+	}))
+	defer server.Close()
+
+	conn := newHTTPConn(server.URL, nil, server.Client(), New(config.ProxyConfig{}, nil).handleRequest)
+	result, err := conn.Call(context.Background(), "tools/list", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(result) != `{"tools":[]}` {
+		t.Errorf("result = %s", result)
+	}
+}
@@ -6,11 +6,12 @@ import (
 	"fmt"
 	"log"
 
-	"github.com/scopweb/mcp-context-server/internal/analyzer"
-	"github.com/scopweb/mcp-context-server/internal/config"
-	"github.com/scopweb/mcp-context-server/internal/memory"
-	"github.com/scopweb/mcp-context-server/internal/tools"
-	"github.com/scopweb/mcp-context-server/internal/transport"
+	"github.com/scopweb/mcp-go-context/internal/analyzer"
+	"github.com/scopweb/mcp-go-context/internal/config"
+	"github.com/scopweb/mcp-go-context/internal/memory"
+	"github.com/scopweb/mcp-go-context/internal/proxy"
+	"github.com/scopweb/mcp-go-context/internal/tools"
+	"github.com/scopweb/mcp-go-context/internal/transport"
 )
 
 // Server represents the MCP Context Server
@@ -20,6 +21,7 @@ type Server struct {
 	analyzer  *analyzer.ProjectAnalyzer
 	memory    *memory.Manager
 	tools     *tools.Registry
+	proxy     *proxy.Hub
 }
 
 // New creates a new MCP Context Server
@@ -40,7 +42,7 @@ func New(cfg *config.Config) (*Server, error) {
 	}
 
 	// Initialize components
-	projectAnalyzer, err := analyzer.New(cfg.Context)
+	projectAnalyzer, err := analyzer.New(cfg.Context, cfg.Cache, cfg.Dependencies)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create analyzer: %w", err)
 	}
@@ -59,6 +61,11 @@ func New(cfg *config.Config) (*Server, error) {
 		tools:     tools.NewRegistry(),
 	}
 
+	// Mount upstream MCP servers
+	if cfg.Proxy.Enabled {
+		srv.proxy = proxy.New(cfg.Proxy, cfg.Context.ProjectPaths)
+	}
+
 	// Register tools
 	srv.registerTools()
 
@@ -75,6 +82,20 @@ func (s *Server) Start(ctx context.Context) error {
 It analyzes your project, fetches relevant documentation, and maintains conversation memory.`,
 	}
 
+	// Connect upstreams before serving so their tools are listed from the start
+	if s.proxy != nil {
+		s.proxy.Start(ctx)
+		defer s.proxy.Close()
+		s.registerProxyTools()
+	}
+
+	// Keep the project index current in the background
+	go func() {
+		if err := s.analyzer.Watch(ctx); err != nil {
+			log.Printf("File watcher stopped: %v", err)
+		}
+	}()
+
 	// Start transport
 	return s.transport.Start(ctx, info, s.handleRequest)
 }
@@ -110,6 +131,14 @@ func (s *Server) handleRequest(ctx context.Context, req json.RawMessage) (json.R
 		result, err = s.handleToolsList()
 	case "tools/call":
 		result, err = s.handleToolCall(req)
+	case "resources/list":
+		result, err = s.handleResourcesList()
+	case "resources/read":
+		result, err = s.handleResourcesRead(req)
+	case "prompts/list":
+		result, err = s.handlePromptsList()
+	case "prompts/get":
+		result, err = s.handlePromptsGet(req)
 	case "notifications/initialized":
 		// Handle initialization notification (no response needed)
 		return nil, nil
@@ -149,13 +178,24 @@ func (s *Server) createErrorResponse(id interface{}, code int, message string) (
 
 // handleInitialize handles the initialize request
 func (s *Server) handleInitialize(id interface{}) (interface{}, error) {
+	capabilities := map[string]interface{}{
+		"tools": map[string]bool{
+			"listChanged": false,
+		},
+	}
+
+	if s.proxy != nil {
+		capabilities["resources"] = map[string]bool{
+			"listChanged": false,
+		}
+		capabilities["prompts"] = map[string]bool{
+			"listChanged": false,
+		}
+	}
+
 	return map[string]interface{}{
 		"protocolVersion": "2024-11-05",
-		"capabilities": map[string]interface{}{
-			"tools": map[string]bool{
-				"listChanged": false,
-			},
-		},
+		"capabilities":    capabilities,
 		"serverInfo": map[string]string{
 			"name":    "MCP Context Server",
 			"version": "1.0.0",
@@ -190,11 +230,79 @@ func (s *Server) handleToolCall(req json.RawMessage) (interface{}, error) {
 		return nil, fmt.Errorf("tool execution failed: %w", err)
 	}
 
+	// Proxied tools already return a complete tools/call result
+	if raw, ok := result.(json.RawMessage); ok {
+		return raw, nil
+	}
+
 	return map[string]interface{}{
 		"content": result,
 	}, nil
 }
 
+// handleResourcesList returns resources exposed by mounted upstreams
+func (s *Server) handleResourcesList() (interface{}, error) {
+	resources := []proxy.Resource{}
+	if s.proxy != nil {
+		resources = append(resources, s.proxy.ListResources()...)
+	}
+
+	return map[string]interface{}{
+		"resources": resources,
+	}, nil
+}
+
+// handleResourcesRead reads a namespaced upstream resource
+func (s *Server) handleResourcesRead(req json.RawMessage) (interface{}, error) {
+	var readReq struct {
+		Params struct {
+			URI string `json:"uri"`
+		} `json:"params"`
+	}
+
+	if err := json.Unmarshal(req, &readReq); err != nil {
+		return nil, fmt.Errorf("invalid resource read request: %w", err)
+	}
+
+	if s.proxy == nil {
+		return nil, fmt.Errorf("unknown resource: %s", readReq.Params.URI)
+	}
+
+	return s.proxy.ReadResource(readReq.Params.URI)
+}
+
+// handlePromptsList returns prompts exposed by mounted upstreams
+func (s *Server) handlePromptsList() (interface{}, error) {
+	prompts := []proxy.Prompt{}
+	if s.proxy != nil {
+		prompts = append(prompts, s.proxy.ListPrompts()...)
+	}
+
+	return map[string]interface{}{
+		"prompts": prompts,
+	}, nil
+}
+
+// handlePromptsGet renders a namespaced upstream prompt
+func (s *Server) handlePromptsGet(req json.RawMessage) (interface{}, error) {
+	var promptReq struct {
+		Params struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		} `json:"params"`
+	}
+
+	if err := json.Unmarshal(req, &promptReq); err != nil {
+		return nil, fmt.Errorf("invalid prompt request: %w", err)
+	}
+
+	if s.proxy == nil {
+		return nil, fmt.Errorf("unknown prompt: %s", promptReq.Params.Name)
+	}
+
+	return s.proxy.GetPrompt(promptReq.Params.Name, promptReq.Params.Arguments)
+}
+
 // GetAnalyzer returns the project analyzer (implements AnalyzerInterface)
 func (s *Server) GetAnalyzer() tools.AnalyzerInterface {
 	return s.analyzer
@@ -225,7 +333,33 @@ func (s *Server) registerTools() {
 				},
 				"depth": map[string]interface{}{
 					"type":        "integer",
-					"description": "Analysis depth (default: 3)",
+					"description": "Maximum directory depth below the path (default: 3; -1 for no limit)",
+				},
+				"include": map[string]interface{}{
+					"type":        "array",
+					"description": "Only analyze files matching these globs (e.g. \"**/*.go\")",
+					"items": map[string]interface{}{
+						"type": "string",
+					},
+				},
+				"exclude": map[string]interface{}{
+					"type":        "array",
+					"description": "Skip files and directories matching these globs",
+					"items": map[string]interface{}{
+						"type": "string",
+					},
+				},
+				"maxFiles": map[string]interface{}{
+					"type":        "integer",
+					"description": "Maximum number of files to analyze",
+				},
+				"maxFileSizeKB": map[string]interface{}{
+					"type":        "integer",
+					"description": "Skip files larger than this size",
+				},
+				"interfaces": map[string]interface{}{
+					"type":        "boolean",
+					"description": "List interfaces and their implementations; type-checks the module, so off by default",
 				},
 			},
 		},
@@ -241,7 +375,7 @@ func (s *Server) registerTools() {
 			"properties": map[string]interface{}{
 				"query": map[string]interface{}{
 					"type":        "string",
-					"description": "Context query or topic",
+					"description": "Context query or topic (ignored in repomap mode)",
 				},
 				"files": map[string]interface{}{
 					"type":        "array",
@@ -254,6 +388,11 @@ func (s *Server) registerTools() {
 					"type":        "integer",
 					"description": "Maximum tokens to return",
 				},
+				"mode": map[string]interface{}{
+					"type":        "string",
+					"description": "context (default) for files relevant to the query, repomap for an outline of the project's exported Go declarations",
+					"enum":        []string{"context", "repomap"},
+				},
 			},
 			"required": []string{"query"},
 		},
@@ -328,8 +467,156 @@ func (s *Server) registerTools() {
 					"type":        "boolean",
 					"description": "Only analyze direct dependencies",
 				},
+				"graph": map[string]interface{}{
+					"type":        "string",
+					"enum":        []string{"tree", "dot"},
+					"description": "Render the Go module graph as a tree or in Graphviz DOT format",
+				},
+				"why": map[string]interface{}{
+					"type":        "string",
+					"description": "Module path to explain: shortest requirement path from the main module",
+				},
+				"depth": map[string]interface{}{
+					"type":        "integer",
+					"description": "Module tree depth (default: 3)",
+				},
+				"osvDatabase": map[string]interface{}{
+					"type":        "string",
+					"description": "OSV advisory directory or zip to scan against (default: dependencies.osvDatabase)",
+				},
+				"reachability": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Check whether vulnerable Go packages are imported and their symbols called",
+				},
+				"goProxy": map[string]interface{}{
+					"type":        "string",
+					"description": "GOPROXY-protocol URL, e.g. file:///srv/goproxy, checked for newer module versions (default: dependencies.goProxy)",
+				},
+				"npmRegistry": map[string]interface{}{
+					"type":        "string",
+					"description": "npm registry URL checked for newer package versions (default: dependencies.npmRegistry)",
+				},
 			},
 		},
 		Handler: tools.DependencyAnalysisHandler,
 	})
-}
\ No newline at end of file
+
+	// find-symbol tool
+	s.tools.Register(&tools.Tool{
+		Name:        "find-symbol",
+		Description: "Finds Go symbol definitions with signature, doc comment and source, optionally with all references",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"symbol": map[string]interface{}{
+					"type":        "string",
+					"description": "Symbol name: Name, Type.Method, pkg.Name or pkg.Type.Method",
+				},
+				"includeReferences": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Also list references across the project",
+				},
+			},
+			"required": []string{"symbol"},
+		},
+		Handler: tools.FindSymbolHandler,
+	})
+
+	// type-info tool
+	s.tools.Register(&tools.Tool{
+		Name:        "type-info",
+		Description: "Answers type-checked questions about Go code: method sets, interface implementations and identifier resolution",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"query": map[string]interface{}{
+					"type":        "string",
+					"description": "methods, implements or resolve (default: methods)",
+					"enum":        []string{"methods", "implements", "resolve"},
+				},
+				"symbol": map[string]interface{}{
+					"type":        "string",
+					"description": "Type name (Name or pkg.Name), or file:line:col for resolve",
+				},
+			},
+			"required": []string{"symbol"},
+		},
+		Handler: tools.TypeInfoHandler,
+	})
+
+	// who-calls tool
+	s.tools.Register(&tools.Tool{
+		Name:        "who-calls",
+		Description: "Lists the callers of a Go function or method with call sites, up to N levels",
+		InputSchema: callGraphSchema(),
+		Handler:     tools.WhoCallsHandler,
+	})
+
+	// what-calls tool
+	s.tools.Register(&tools.Tool{
+		Name:        "what-calls",
+		Description: "Lists the functions called by a Go function or method with call sites, up to N levels",
+		InputSchema: callGraphSchema(),
+		Handler:     tools.WhatCallsHandler,
+	})
+
+	// interface-map tool
+	s.tools.Register(&tools.Tool{
+		Name:        "interface-map",
+		Description: "Maps every Go interface in the project to the concrete types satisfying it, and each type to its interfaces",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"filter": map[string]interface{}{
+					"type":        "string",
+					"description": "Only show interfaces or types whose name contains this text",
+				},
+			},
+		},
+		Handler: tools.InterfaceMapHandler,
+	})
+}
+
+// callGraphSchema is the input schema shared by who-calls and what-calls
+func callGraphSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"function": map[string]interface{}{
+				"type":        "string",
+				"description": "Function name: Name, Type.Method, pkg.Name or pkg.Type.Method",
+			},
+			"depth": map[string]interface{}{
+				"type":        "integer",
+				"description": "Levels to follow (default: 1, max: 5)",
+			},
+		},
+		"required": []string{"function"},
+	}
+}
+
+// registerProxyTools registers namespaced tools of every mounted upstream
+func (s *Server) registerProxyTools() {
+	for _, up := range s.proxy.Upstreams() {
+		for _, t := range up.Tools {
+			upstream, name := up.Name, t.Name
+
+			schema := t.InputSchema
+			if schema == nil {
+				schema = map[string]interface{}{"type": "object"}
+			}
+
+			err := s.tools.Register(&tools.Tool{
+				Name:        s.proxy.ToolName(upstream, name),
+				Description: fmt.Sprintf("[%s] %s", upstream, t.Description),
+				InputSchema: schema,
+				Handler: func(args json.RawMessage, ctx interface{}) (interface{}, error) {
+					return s.proxy.CallTool(upstream, name, args)
+				},
+			})
+			if err != nil {
+				log.Printf("proxy: %v", err)
+			}
+		}
+	}
+}
@@ -11,6 +11,9 @@ import (
 	"io"
 	"regexp"
 	"sort"
+
+	"github.com/scopweb/mcp-go-context/internal/analyzer"
+	"github.com/scopweb/mcp-go-context/internal/memory"
 )
 
 // ServerInterface defines methods needed from the server
@@ -22,8 +25,23 @@ type ServerInterface interface {
 
 type AnalyzerInterface interface {
 	AnalyzeProject(string, int) (*ProjectStructure, error)
+	AnalyzeProjectWithOptions(string, AnalyzeOptions) (*ProjectStructure, error)
 	GetRelevantContext(string, []string, int) (string, error)
-	AnalyzeDependencies(bool) ([]Dependency, error)
+	CountTokens(string) int
+	TruncateTokens(string, int) string
+	TokenizerName() string
+	RepoMap(int) (string, error)
+	AnalyzeDependencies(bool) ([]Dependency, []string, error)
+	GoModFiles() []GoModFile
+	ModuleGraphReport(ModuleGraphOptions) (string, error)
+	Vulnerabilities(VulnerabilityOptions) ([]Vulnerability, error)
+	Licenses() (*LicenseReport, error)
+	Outdated(OutdatedOptions) ([]Outdated, error)
+	FindSymbol(string, bool) (string, error)
+	TypeInfo(string, string) (string, error)
+	Callers(string, int) (string, error)
+	Callees(string, int) (string, error)
+	InterfaceImplementations(string) (string, error)
 }
 
 type MemoryInterface interface {
@@ -36,47 +54,31 @@ type ConfigInterface interface {
 	GetProjectPaths() []string
 }
 
-// Structs imported from memory and analyzer packages
-type Memory struct {
-	Key       string
-	Content   string
-	Tags      []string
-	Timestamp time.Time
-	Usage     int
-}
-type ProjectStructure struct {
-	RootPath     string
-	Files        []*FileInfo
-	Dependencies []Dependency
-	Structure    map[string][]string
-	Stats        ProjectStats
-}
-
-type FileInfo struct {
-	Path         string
-	Size         int64
-	Language     string
-	Imports      []string
-	Functions    []string
-	Types        []string
-	LastModified int64
-}
-
-type ProjectStats struct {
-	TotalFiles   int
-	TotalLines   int
-	Languages    map[string]int
-	TotalSize    int64
-	GoModules    []string
-	MainPackages []string
-}
-
-type Dependency struct {
-	Name    string
-	Version string
-	Type    string
-	Path    string
-}
+// Types shared with the memory and analyzer packages
+type (
+	Memory               = memory.Memory
+	ProjectStructure     = analyzer.ProjectStructure
+	AnalyzeOptions       = analyzer.AnalyzeOptions
+	SkipSummary          = analyzer.SkipSummary
+	InterfaceImpl        = analyzer.InterfaceImpl
+	FileInfo             = analyzer.FileInfo
+	Symbol               = analyzer.Symbol
+	ProjectStats         = analyzer.ProjectStats
+	Dependency           = analyzer.Dependency
+	GoModFile            = analyzer.GoModFile
+	ModuleVersion        = analyzer.ModuleVersion
+	Replacement          = analyzer.Replacement
+	Retraction           = analyzer.Retraction
+	ModuleGraphOptions   = analyzer.ModuleGraphOptions
+	Vulnerability        = analyzer.Vulnerability
+	VulnerabilityOptions = analyzer.VulnerabilityOptions
+	Outdated             = analyzer.Outdated
+	OutdatedOptions      = analyzer.OutdatedOptions
+	DependencyLicense    = analyzer.DependencyLicense
+	FileLicense          = analyzer.FileLicense
+	LicenseViolation     = analyzer.LicenseViolation
+	LicenseReport        = analyzer.LicenseReport
+)
 
 type MemoryEntry struct {
 	Key     string
@@ -100,8 +102,13 @@ func convertMemoryToEntry(m *Memory) MemoryEntry {
 // AnalyzeProjectHandler - Complete implementation
 func AnalyzeProjectHandler(args json.RawMessage, server interface{}) (interface{}, error) {
 	var params struct {
-		Path  string `json:"path"`
-		Depth int    `json:"depth"`
+		Path          string   `json:"path"`
+		Depth         int      `json:"depth"`
+		Include       []string `json:"include"`
+		Exclude       []string `json:"exclude"`
+		MaxFiles      int      `json:"maxFiles"`
+		MaxFileSizeKB int      `json:"maxFileSizeKB"`
+		Interfaces    bool     `json:"interfaces"`
 	}
 
 	if err := json.Unmarshal(args, &params); err != nil {
@@ -113,7 +120,7 @@ func AnalyzeProjectHandler(args json.RawMessage, server interface{}) (interface{
 		params.Path = "."
 	}
 	if params.Depth == 0 {
-		params.Depth = 3
+		params.Depth = 3 // negative for no limit
 	}
 
 	// Get server interface
@@ -128,7 +135,14 @@ func AnalyzeProjectHandler(args json.RawMessage, server interface{}) (interface{
 		return createErrorResponse("Analyzer not available")
 	}
 
-	structure, err := analyzer.AnalyzeProject(params.Path, params.Depth)
+	structure, err := analyzer.AnalyzeProjectWithOptions(params.Path, AnalyzeOptions{
+		Depth:       params.Depth,
+		Include:     params.Include,
+		Exclude:     params.Exclude,
+		MaxFiles:    params.MaxFiles,
+		MaxFileSize: int64(params.MaxFileSizeKB) * 1024,
+		Interfaces:  params.Interfaces,
+	})
 	if err != nil {
 		return createErrorResponse(fmt.Sprintf("Analysis failed: %v", err))
 	}
@@ -141,6 +155,7 @@ func AnalyzeProjectHandler(args json.RawMessage, server interface{}) (interface{
 	result.WriteString("## 📊 Project Statistics\n")
 	result.WriteString(fmt.Sprintf("- **Total Files**: %d\n", structure.Stats.TotalFiles))
 	result.WriteString(fmt.Sprintf("- **Total Size**: %.2f MB\n", float64(structure.Stats.TotalSize)/(1024*1024)))
+	result.WriteString(fmt.Sprintf("- **Total Lines**: %d\n", structure.Stats.TotalLines))
 	
 	// Languages breakdown
 	result.WriteString("\n### Languages Distribution\n")
@@ -157,6 +172,25 @@ func AnalyzeProjectHandler(args json.RawMessage, server interface{}) (interface{
 		}
 	}
 
+	// Go code structure
+	writeGoSymbolSummary(&result, structure)
+
+	// Interface implementations
+	if len(structure.Implementations) > 0 {
+		result.WriteString("\n## 🔌 Interface Implementations\n")
+		for i, impl := range structure.Implementations {
+			if i >= 20 {
+				result.WriteString(fmt.Sprintf("- ... and %d more interfaces (see `interface-map`)\n", len(structure.Implementations)-20))
+				break
+			}
+			if len(impl.Implementations) == 0 {
+				result.WriteString(fmt.Sprintf("- `%s` ← _none_\n", impl.Interface))
+				continue
+			}
+			result.WriteString(fmt.Sprintf("- `%s` ← `%s`\n", impl.Interface, strings.Join(impl.Implementations, "`, `")))
+		}
+	}
+
 	// Dependencies
 	if len(structure.Dependencies) > 0 {
 		result.WriteString("\n## 📦 Dependencies\n")
@@ -182,6 +216,21 @@ func AnalyzeProjectHandler(args json.RawMessage, server interface{}) (interface{
 			}
 		}
 	}
+	writeDependencyWarnings(&result, structure.DependencyWarnings)
+
+	// Skipped entries
+	if len(structure.Skipped.Counts) > 0 {
+		result.WriteString("\n## ⏭️ Skipped\n")
+		reasons := make([]string, 0, len(structure.Skipped.Counts))
+		for reason := range structure.Skipped.Counts {
+			reasons = append(reasons, reason)
+		}
+		sort.Strings(reasons)
+		for _, reason := range reasons {
+			result.WriteString(fmt.Sprintf("- **%s** (%s): %d (e.g. `%s`)\n", reason, structure.Skipped.Limits[reason],
+				structure.Skipped.Counts[reason], strings.Join(structure.Skipped.Examples[reason], "`, `")))
+		}
+	}
 
 	// Important files
 	result.WriteString("\n## 🔍 Key Files\n")
@@ -206,6 +255,7 @@ func GetContextHandler(args json.RawMessage, server interface{}) (interface{}, e
 		Query     string   `json:"query"`
 		Files     []string `json:"files"`
 		MaxTokens int      `json:"maxTokens"`
+		Mode      string   `json:"mode"`
 	}
 
 	if err := json.Unmarshal(args, &params); err != nil {
@@ -224,37 +274,98 @@ func GetContextHandler(args json.RawMessage, server interface{}) (interface{}, e
 	analyzer := srv.GetAnalyzer()
 	memory := srv.GetMemory()
 
+	switch params.Mode {
+	case "", "context":
+		if params.Query == "" {
+			return createErrorResponse("query is required")
+		}
+	case "repomap":
+		if analyzer == nil {
+			return createErrorResponse("Analyzer not available")
+		}
+		// The token report is set aside so the map and the report fit together
+		reportFormat := "\n📏 %d of %d tokens (%s tokenizer)\n"
+		reserved := analyzer.CountTokens(fmt.Sprintf(reportFormat, params.MaxTokens, params.MaxTokens, analyzer.TokenizerName()))
+		repoMap, err := analyzer.RepoMap(params.MaxTokens - reserved)
+		if err != nil {
+			return createErrorResponse(fmt.Sprintf("Repository map failed: %v", err))
+		}
+		return []map[string]interface{}{
+			{
+				"type": "text",
+				"text": repoMap + fmt.Sprintf(reportFormat, analyzer.CountTokens(repoMap), params.MaxTokens, analyzer.TokenizerName()),
+			},
+		}, nil
+	default:
+		return createErrorResponse(fmt.Sprintf("unknown mode %q", params.Mode))
+	}
+
+	// Without an analyzer, fall back to four characters per token
+	countTokens := func(text string) int { return (len(text) + 3) / 4 }
+	truncateTokens := func(text string, maxTokens int) string { return strings.ToValidUTF8(text[:min(len(text), max(0, maxTokens*4))], "") }
+	tokenizerName := "chars"
+	if analyzer != nil {
+		countTokens, truncateTokens, tokenizerName = analyzer.CountTokens, analyzer.TruncateTokens, analyzer.TokenizerName()
+	}
+
 	var context strings.Builder
 	context.WriteString(fmt.Sprintf("# Context for: %s\n\n", params.Query))
 
+	// Every section is charged to the budget; the query analysis and the
+	// token report are set aside first so that files cannot crowd them out
+	var analysisSection string
+	if analysis := analyzeQuery(params.Query); analysis != "" {
+		analysisSection = "\n## 🔍 Query Analysis\n" + analysis
+	}
+	reportFormat := "\n---\n📏 %d of %d tokens (%s tokenizer)\n"
+	remaining := params.MaxTokens - countTokens(context.String()) - countTokens(fmt.Sprintf(reportFormat, params.MaxTokens, params.MaxTokens, tokenizerName))
+	if cost := countTokens(analysisSection); cost <= remaining {
+		remaining -= cost
+	} else {
+		analysisSection = ""
+	}
+
 	// Add relevant memory
 	if memory != nil {
 		memories, err := memory.Search(params.Query, []string{})
 		if err == nil && len(memories) > 0 {
-			context.WriteString("## 💭 Relevant Memory\n\n")
-			for i, mem := range memories {
-				if i >= 3 {
+			const ellipsis = "…\n\n"
+			section := "## 💭 Relevant Memory\n\n"
+			cost, entries := countTokens(section), 0
+			for _, mem := range memories {
+				if entries >= 3 || cost >= remaining {
 					break
 				}
-				context.WriteString(fmt.Sprintf("**%s**: %s\n\n", mem.Key, mem.Content))
+				entry := fmt.Sprintf("**%s**: %s\n\n", mem.Key, mem.Content)
+				if cost+countTokens(entry) > remaining {
+					if entry = truncateTokens(entry, remaining-cost-countTokens(ellipsis)); entry == "" {
+						break
+					}
+					entry += ellipsis
+				}
+				section += entry
+				cost += countTokens(entry)
+				entries++
+			}
+			if entries > 0 {
+				context.WriteString(section)
+				remaining -= cost
 			}
 		}
 	}
 
 	// Get file context
-	if analyzer != nil {
-		fileContext, err := analyzer.GetRelevantContext(params.Query, params.Files, params.MaxTokens-len(context.String()))
+	if analyzer != nil && remaining > 0 {
+		fileContext, err := analyzer.GetRelevantContext(params.Query, params.Files, remaining)
 		if err == nil {
 			context.WriteString(fileContext)
 		}
 	}
 
 	// Add query-specific analysis
-	analysis := analyzeQuery(params.Query)
-	if analysis != "" {
-		context.WriteString("\n## 🔍 Query Analysis\n")
-		context.WriteString(analysis)
-	}
+	context.WriteString(analysisSection)
+
+	context.WriteString(fmt.Sprintf(reportFormat, countTokens(context.String()), params.MaxTokens, tokenizerName))
 
 	return []map[string]interface{}{
 		{
@@ -357,9 +468,16 @@ func RememberConversationHandler(args json.RawMessage, server interface{}) (inte
 // DependencyAnalysisHandler - Complete dependency analysis
 func DependencyAnalysisHandler(args json.RawMessage, server interface{}) (interface{}, error) {
 	var params struct {
-		IncludeTransitive bool `json:"includeTransitive"`
-		OnlyDirect        bool `json:"onlyDirect"`
-		SuggestDocs       bool `json:"suggestDocs"`
+		IncludeTransitive bool   `json:"includeTransitive"`
+		OnlyDirect        bool   `json:"onlyDirect"`
+		SuggestDocs       bool   `json:"suggestDocs"`
+		Graph             string `json:"graph"`
+		Why               string `json:"why"`
+		Depth             int    `json:"depth"`
+		OSVDatabase       string `json:"osvDatabase"`
+		Reachability      bool   `json:"reachability"`
+		GoProxy           string `json:"goProxy"`
+		NPMRegistry       string `json:"npmRegistry"`
 	}
 
 	if err := json.Unmarshal(args, &params); err != nil {
@@ -376,42 +494,191 @@ func DependencyAnalysisHandler(args json.RawMessage, server interface{}) (interf
 		return createErrorResponse("Analyzer not available")
 	}
 
-	deps, err := analyzer.AnalyzeDependencies(params.IncludeTransitive && !params.OnlyDirect)
+	deps, warnings, err := analyzer.AnalyzeDependencies(params.IncludeTransitive && !params.OnlyDirect)
 	if err != nil {
 		return createErrorResponse(fmt.Sprintf("Dependency analysis failed: %v", err))
 	}
 
 	var result strings.Builder
 	result.WriteString("# 📦 Dependency Analysis\n\n")
+	writeDependencyWarnings(&result, warnings)
+
+	// Group dependencies by the module requiring them; Go modules come first
+	// so modules without requirements are still listed
+	modFiles := make(map[string]GoModFile)
+	modules := []string{}
+	for _, mf := range analyzer.GoModFiles() {
+		modFiles[mf.Module] = mf
+		modules = append(modules, mf.Module)
+	}
+
+	byModule := make(map[string][]Dependency)
+	for _, dep := range deps {
+		if _, seen := byModule[dep.Module]; !seen {
+			if _, isGo := modFiles[dep.Module]; !isGo {
+				modules = append(modules, dep.Module)
+			}
+		}
+		byModule[dep.Module] = append(byModule[dep.Module], dep)
+	}
 
-	// Categorize dependencies
 	directDeps := []Dependency{}
+	for _, module := range modules {
+		if mf, isGo := modFiles[module]; isGo {
+			result.WriteString(fmt.Sprintf("## 📁 Module `%s`\n\n", module))
+			writeGoModDirectives(&result, mf)
+		} else {
+			result.WriteString(fmt.Sprintf("## 📁 Module `%s` (%s)\n\n", module, byModule[module][0].Ecosystem))
+		}
+		direct := writeModuleDependencies(&result, byModule[module], params.IncludeTransitive, params.SuggestDocs)
+		directDeps = append(directDeps, direct...)
+		result.WriteString("\n")
+	}
+
+	// Module graph from go.sum and the module cache
+	if (params.IncludeTransitive && !params.OnlyDirect) || params.Graph != "" || params.Why != "" {
+		graph, err := analyzer.ModuleGraphReport(ModuleGraphOptions{
+			Format: params.Graph,
+			Why:    params.Why,
+			Depth:  params.Depth,
+		})
+		if err == nil {
+			result.WriteString(graph)
+		}
+	}
+
+	// Known vulnerabilities from the offline OSV database
+	vulns, vulnErr := analyzer.Vulnerabilities(VulnerabilityOptions{
+		Database:     params.OSVDatabase,
+		Reachability: params.Reachability,
+	})
+	writeVulnerabilities(&result, vulns, vulnErr)
+
+	// Newer releases from the module proxy and npm registry
+	outdated, outdatedErr := analyzer.Outdated(OutdatedOptions{
+		GoProxy:     params.GoProxy,
+		NPMRegistry: params.NPMRegistry,
+	})
+	writeOutdated(&result, outdated, outdatedErr)
+
+	// Licenses of dependencies and project files against the policy
+	licenses, licenseErr := analyzer.Licenses()
+	writeLicenses(&result, licenses, licenseErr)
+
+	// Security and update recommendations
+	result.WriteString("## 🔍 Recommendations\n\n")
+	recommendations := generateDepRecommendations(directDeps, vulns, vulnErr, outdated, licenses)
+	for _, rec := range recommendations {
+		result.WriteString(fmt.Sprintf("- %s\n", rec))
+	}
+
+	return []map[string]interface{}{
+		{
+			"type": "text",
+			"text": result.String(),
+		},
+	}, nil
+}
+
+// writeDependencyWarnings lists the manifests that could not be parsed
+func writeDependencyWarnings(result *strings.Builder, warnings []string) {
+	if len(warnings) == 0 {
+		return
+	}
+	result.WriteString("\n**Warnings** (other ecosystems were still analyzed):\n")
+	for _, warning := range warnings {
+		result.WriteString(fmt.Sprintf("- %s\n", warning))
+	}
+	result.WriteString("\n")
+}
+
+// writeGoModDirectives renders the go.mod directives other than require
+func writeGoModDirectives(result *strings.Builder, mf GoModFile) {
+	if mf.GoVersion != "" {
+		result.WriteString(fmt.Sprintf("- **Go**: %s\n", mf.GoVersion))
+	}
+	if mf.Toolchain != "" {
+		result.WriteString(fmt.Sprintf("- **Toolchain**: %s\n", mf.Toolchain))
+	}
+	result.WriteString("\n")
+
+	if len(mf.Replaces) > 0 {
+		result.WriteString(fmt.Sprintf("### Replacements (%d)\n\n", len(mf.Replaces)))
+		for _, r := range mf.Replaces {
+			old := r.Old.Path
+			if r.Old.Version != "" {
+				old += " " + r.Old.Version
+			}
+			if r.Local {
+				result.WriteString(fmt.Sprintf("- `%s` → `%s` _(local)_\n", old, r.New.Path))
+			} else {
+				result.WriteString(fmt.Sprintf("- `%s` → `%s %s`\n", old, r.New.Path, r.New.Version))
+			}
+		}
+		result.WriteString("\n")
+	}
+
+	if len(mf.Excludes) > 0 {
+		result.WriteString(fmt.Sprintf("### Excluded Versions (%d)\n\n", len(mf.Excludes)))
+		for _, ex := range mf.Excludes {
+			result.WriteString(fmt.Sprintf("- `%s %s`\n", ex.Path, ex.Version))
+		}
+		result.WriteString("\n")
+	}
+
+	if len(mf.Retracts) > 0 {
+		result.WriteString(fmt.Sprintf("### Retracted Versions (%d)\n\n", len(mf.Retracts)))
+		for _, ret := range mf.Retracts {
+			versions := ret.Low
+			if ret.High != ret.Low {
+				versions = fmt.Sprintf("[%s, %s]", ret.Low, ret.High)
+			}
+			line := fmt.Sprintf("- `%s`", versions)
+			if ret.Rationale != "" {
+				line += " - " + ret.Rationale
+			}
+			result.WriteString(line + "\n")
+		}
+		result.WriteString("\n")
+	}
+}
+
+// writeModuleDependencies renders the direct and, if requested, indirect
+// dependencies of one module, returning the direct ones
+func writeModuleDependencies(result *strings.Builder, deps []Dependency, includeTransitive, suggestDocs bool) []Dependency {
+	// Categorize dependencies; peer and optional ones are direct
+	directDeps := []Dependency{}
+	devDeps := []Dependency{}
 	indirectDeps := []Dependency{}
-	
+
 	for _, dep := range deps {
-		if dep.Type == "direct" {
+		switch dep.Type {
+		case "direct", "peer", "optional":
 			directDeps = append(directDeps, dep)
-		} else {
+		case "dev":
+			devDeps = append(devDeps, dep)
+		default:
 			indirectDeps = append(indirectDeps, dep)
 		}
 	}
 
 	// Direct dependencies
-	result.WriteString(fmt.Sprintf("## Direct Dependencies (%d)\n\n", len(directDeps)))
+	result.WriteString(fmt.Sprintf("### Direct Dependencies (%d)\n\n", len(directDeps)))
 	for _, dep := range directDeps {
-		result.WriteString(fmt.Sprintf("- **%s** `%s`", dep.Name, dep.Version))
-		if params.SuggestDocs {
-			docSuggestion := suggestDocumentation(dep.Name)
-			if docSuggestion != "" {
-				result.WriteString(fmt.Sprintf(" - [📚 Docs](%s)", docSuggestion))
-			}
+		writeDependency(result, dep, suggestDocs)
+	}
+
+	// Development dependencies
+	if len(devDeps) > 0 {
+		result.WriteString(fmt.Sprintf("\n### Dev Dependencies (%d)\n\n", len(devDeps)))
+		for _, dep := range devDeps {
+			writeDependency(result, dep, suggestDocs)
 		}
-		result.WriteString("\n")
 	}
 
 	// Indirect dependencies if requested
-	if params.IncludeTransitive && len(indirectDeps) > 0 {
-		result.WriteString(fmt.Sprintf("\n## Indirect Dependencies (%d)\n\n", len(indirectDeps)))
+	if includeTransitive && len(indirectDeps) > 0 {
+		result.WriteString(fmt.Sprintf("\n### Indirect Dependencies (%d)\n\n", len(indirectDeps)))
 		// Show only first 20 to avoid clutter
 		displayCount := min(20, len(indirectDeps))
 		for i, dep := range indirectDeps[:displayCount] {
@@ -422,20 +689,197 @@ func DependencyAnalysisHandler(args json.RawMessage, server interface{}) (interf
 		}
 	}
 
-	// Security and update recommendations
-	result.WriteString("\n## 🔍 Recommendations\n\n")
-	recommendations := generateDepRecommendations(directDeps)
-	for _, rec := range recommendations {
-		result.WriteString(fmt.Sprintf("- %s\n", rec))
+	return directDeps
+}
+
+func writeDependency(result *strings.Builder, dep Dependency, suggestDocs bool) {
+	result.WriteString(fmt.Sprintf("- **%s** `%s`", dep.Name, dep.Version))
+	if dep.Constraint != "" {
+		result.WriteString(fmt.Sprintf(" (`%s`)", dep.Constraint))
+	}
+	if len(dep.Extras) > 0 {
+		result.WriteString(fmt.Sprintf(" [%s]", strings.Join(dep.Extras, ", ")))
+	}
+	if dep.Type == "peer" || dep.Type == "optional" {
+		result.WriteString(fmt.Sprintf(" _%s_", dep.Type))
+	}
+	if dep.Markers != "" {
+		result.WriteString(fmt.Sprintf(" when `%s`", dep.Markers))
+	}
+	if suggestDocs {
+		docSuggestion := suggestDocumentation(dep.Name)
+		if docSuggestion != "" {
+			result.WriteString(fmt.Sprintf(" - [📚 Docs](%s)", docSuggestion))
+		}
+	}
+	result.WriteString("\n")
+}
+
+// FindSymbolHandler - Jump to Go definitions and references
+func FindSymbolHandler(args json.RawMessage, server interface{}) (interface{}, error) {
+	var params struct {
+		Symbol            string `json:"symbol"`
+		IncludeReferences bool   `json:"includeReferences"`
+	}
+
+	if err := json.Unmarshal(args, &params); err != nil {
+		return nil, fmt.Errorf("invalid parameters: %w", err)
+	}
+
+	if params.Symbol == "" {
+		return createErrorResponse("symbol is required")
+	}
+
+	srv, ok := server.(ServerInterface)
+	if !ok {
+		return createErrorResponse("Server interface error")
+	}
+
+	analyzer := srv.GetAnalyzer()
+	if analyzer == nil {
+		return createErrorResponse("Analyzer not available")
+	}
+
+	result, err := analyzer.FindSymbol(params.Symbol, params.IncludeReferences)
+	if err != nil {
+		return createErrorResponse(fmt.Sprintf("Symbol lookup failed: %v", err))
 	}
 
 	return []map[string]interface{}{
 		{
 			"type": "text",
-			"text": result.String(),
+			"text": result,
+		},
+	}, nil
+}
+
+// TypeInfoHandler - Type-checked queries: method sets, implementations, identifier resolution
+func TypeInfoHandler(args json.RawMessage, server interface{}) (interface{}, error) {
+	var params struct {
+		Query  string `json:"query"`
+		Symbol string `json:"symbol"`
+	}
+
+	if err := json.Unmarshal(args, &params); err != nil {
+		return nil, fmt.Errorf("invalid parameters: %w", err)
+	}
+
+	if params.Query == "" {
+		params.Query = "methods"
+	}
+
+	srv, ok := server.(ServerInterface)
+	if !ok {
+		return createErrorResponse("Server interface error")
+	}
+
+	analyzer := srv.GetAnalyzer()
+	if analyzer == nil {
+		return createErrorResponse("Analyzer not available")
+	}
+
+	result, err := analyzer.TypeInfo(params.Query, params.Symbol)
+	if err != nil {
+		return createErrorResponse(fmt.Sprintf("Type query failed: %v", err))
+	}
+
+	return []map[string]interface{}{
+		{
+			"type": "text",
+			"text": result,
+		},
+	}, nil
+}
+
+// WhoCallsHandler - Lists callers of a function up to N levels
+func WhoCallsHandler(args json.RawMessage, server interface{}) (interface{}, error) {
+	return callGraphHandler(args, server, true)
+}
+
+// WhatCallsHandler - Lists functions called by a function up to N levels
+func WhatCallsHandler(args json.RawMessage, server interface{}) (interface{}, error) {
+	return callGraphHandler(args, server, false)
+}
+
+func callGraphHandler(args json.RawMessage, server interface{}, callers bool) (interface{}, error) {
+	var params struct {
+		Function string `json:"function"`
+		Depth    int    `json:"depth"`
+	}
+
+	if err := json.Unmarshal(args, &params); err != nil {
+		return nil, fmt.Errorf("invalid parameters: %w", err)
+	}
+
+	if params.Function == "" {
+		return createErrorResponse("function is required")
+	}
+	if params.Depth == 0 {
+		params.Depth = 1
+	}
+
+	srv, ok := server.(ServerInterface)
+	if !ok {
+		return createErrorResponse("Server interface error")
+	}
+
+	analyzer := srv.GetAnalyzer()
+	if analyzer == nil {
+		return createErrorResponse("Analyzer not available")
+	}
+
+	var result string
+	var err error
+	if callers {
+		result, err = analyzer.Callers(params.Function, params.Depth)
+	} else {
+		result, err = analyzer.Callees(params.Function, params.Depth)
+	}
+	if err != nil {
+		return createErrorResponse(fmt.Sprintf("Call graph query failed: %v", err))
+	}
+
+	return []map[string]interface{}{
+		{
+			"type": "text",
+			"text": result,
+		},
+	}, nil
+}
+
+// InterfaceMapHandler - Interfaces and the concrete types satisfying them
+func InterfaceMapHandler(args json.RawMessage, server interface{}) (interface{}, error) {
+	var params struct {
+		Filter string `json:"filter"`
+	}
+
+	if err := json.Unmarshal(args, &params); err != nil {
+		return nil, fmt.Errorf("invalid parameters: %w", err)
+	}
+
+	srv, ok := server.(ServerInterface)
+	if !ok {
+		return createErrorResponse("Server interface error")
+	}
+
+	analyzer := srv.GetAnalyzer()
+	if analyzer == nil {
+		return createErrorResponse("Analyzer not available")
+	}
+
+	result, err := analyzer.InterfaceImplementations(params.Filter)
+	if err != nil {
+		return createErrorResponse(fmt.Sprintf("Interface analysis failed: %v", err))
+	}
+
+	return []map[string]interface{}{
+		{
+			"type": "text",
+			"text": result,
 		},
 	}, nil
 }
+
 // Helper functions
 
 func createErrorResponse(message string) ([]map[string]interface{}, error) {
@@ -474,6 +918,61 @@ func findKeyFiles(files []*FileInfo) []*FileInfo {
 	return keyFiles
 }
 
+func writeGoSymbolSummary(result *strings.Builder, structure *ProjectStructure) {
+	counts := make(map[string]int)
+	exported := 0
+	var exportedTypes []Symbol
+
+	for _, file := range structure.Files {
+		for _, sym := range file.Symbols {
+			counts[sym.Kind]++
+			if sym.Exported {
+				exported++
+				if sym.Kind == "struct" || sym.Kind == "interface" {
+					exportedTypes = append(exportedTypes, sym)
+				}
+			}
+		}
+	}
+
+	if len(counts) == 0 && len(structure.Stats.GoModules) == 0 {
+		return
+	}
+
+	result.WriteString("\n## 🧩 Go Code Structure\n")
+	for _, module := range structure.Stats.GoModules {
+		result.WriteString(fmt.Sprintf("- **Module**: `%s`\n", module))
+	}
+	for _, pkg := range structure.Stats.MainPackages {
+		result.WriteString(fmt.Sprintf("- **Main package**: `%s`\n", pkg))
+	}
+	result.WriteString(fmt.Sprintf("- **Functions**: %d, **Methods**: %d\n", counts["func"], counts["method"]))
+	result.WriteString(fmt.Sprintf("- **Structs**: %d, **Interfaces**: %d, **Other types**: %d\n",
+		counts["struct"], counts["interface"], counts["type"]))
+	result.WriteString(fmt.Sprintf("- **Constants**: %d, **Variables**: %d\n", counts["const"], counts["var"]))
+	result.WriteString(fmt.Sprintf("- **Exported symbols**: %d\n", exported))
+
+	if len(exportedTypes) > 0 {
+		sort.Slice(exportedTypes, func(i, j int) bool {
+			if exportedTypes[i].Package != exportedTypes[j].Package {
+				return exportedTypes[i].Package < exportedTypes[j].Package
+			}
+			return exportedTypes[i].Name < exportedTypes[j].Name
+		})
+
+		result.WriteString("\n### Exported Types\n")
+		for i, sym := range exportedTypes {
+			if i >= 20 {
+				result.WriteString(fmt.Sprintf("- ... and %d more\n", len(exportedTypes)-20))
+				break
+			}
+			relPath, _ := filepath.Rel(structure.RootPath, sym.Path)
+			result.WriteString(fmt.Sprintf("- `%s.%s` (%s) - `%s:%d`\n",
+				sym.Package, sym.Name, sym.Kind, relPath, sym.StartLine))
+		}
+	}
+}
+
 func analyzeQuery(query string) string {
 	query = strings.ToLower(query)
 	
@@ -674,39 +1173,292 @@ func suggestDocumentation(depName string) string {
 	return fmt.Sprintf("https://pkg.go.dev/%s", depName)
 }
 
-func generateDepRecommendations(deps []Dependency) []string {
+func generateDepRecommendations(deps []Dependency, vulns []Vulnerability, vulnErr error, outdated []Outdated, licenses *LicenseReport) []string {
 	recommendations := []string{}
-	
-	// Check for common security recommendations
-	for _, dep := range deps {
-		if strings.Contains(dep.Name, "crypto") || strings.Contains(dep.Name, "security") {
-			recommendations = append(recommendations, 
-				fmt.Sprintf("🔒 Review security implementation for %s", dep.Name))
+
+	// Upgrades fixing known vulnerabilities, one per dependency version
+	upgrades := make(map[string][]string)
+	fixes := make(map[string][]string)
+	fixSeen := make(map[string]bool)
+	order := []string{}
+	for _, v := range vulns {
+		key := fmt.Sprintf("**%s** `%s`", v.Dependency.Name, v.Dependency.Version)
+		if _, seen := upgrades[key]; !seen {
+			order = append(order, key)
 		}
-		
-		if strings.Contains(dep.Name, "test") {
-			recommendations = append(recommendations, 
-				"🧪 Ensure adequate test coverage with testing libraries")
+		upgrades[key] = append(upgrades[key], v.ID)
+		if v.Fixed != "" && !fixSeen[key+v.Fixed] {
+			fixSeen[key+v.Fixed] = true
+			fixes[key] = append(fixes[key], "`"+v.Fixed+"`")
 		}
-		
-		if strings.Contains(dep.Name, "http") || strings.Contains(dep.Name, "gin") {
-			recommendations = append(recommendations, 
-				"🌐 Implement proper rate limiting and security headers for web services")
+	}
+	for _, key := range order {
+		if len(fixes[key]) > 0 {
+			recommendations = append(recommendations,
+				fmt.Sprintf("🛡️ Upgrade %s (fixed in %s): %s", key, strings.Join(fixes[key], ", "), strings.Join(upgrades[key], ", ")))
+		} else {
+			recommendations = append(recommendations,
+				fmt.Sprintf("🛡️ No fixed version of %s yet; assess or replace it: %s", key, strings.Join(upgrades[key], ", ")))
 		}
 	}
-	
+
+	if vulnErr != nil {
+		recommendations = append(recommendations,
+			"🛡️ Set `dependencies.osvDatabase` to an OSV export (a directory of advisories or an ecosystem `all.zip`) to scan for known vulnerabilities")
+	}
+
+	if len(outdated) > 0 {
+		kinds := make(map[string]int)
+		for _, o := range outdated {
+			if o.Patch != "" {
+				kinds["patch"]++
+			}
+			if o.Minor != "" {
+				kinds["minor"]++
+			}
+			if o.Major != "" {
+				kinds["major"]++
+			}
+		}
+		if kinds["patch"] > 0 || kinds["minor"] > 0 {
+			recommendations = append(recommendations,
+				fmt.Sprintf("⬆️ Apply %d patch and %d minor upgrades, which should be backward compatible", kinds["patch"], kinds["minor"]))
+		}
+		if kinds["major"] > 0 {
+			recommendations = append(recommendations,
+				fmt.Sprintf("⬆️ Review %d major upgrades for breaking changes before adopting them", kinds["major"]))
+		}
+	}
+
+	if licenses != nil {
+		if len(licenses.Violations) > 0 {
+			recommendations = append(recommendations,
+				fmt.Sprintf("⚖️ Replace or get legal approval for %d license policy violations", len(licenses.Violations)))
+		}
+		if !licenses.Policy {
+			recommendations = append(recommendations,
+				"⚖️ Set `dependencies.licenseAllow` or `dependencies.licenseDeny` to check dependency licenses against a policy")
+		}
+	}
+
+	hasGo := false
+	for _, dep := range deps {
+		if dep.Ecosystem == "go" {
+			hasGo = true
+		}
+	}
+
 	// General recommendations
-	recommendations = append(recommendations, 
-		"📊 Regularly update dependencies to latest stable versions",
-		"🔍 Use `go mod tidy` to clean up unused dependencies",
-		"📋 Consider using `go mod audit` for security vulnerability checks")
-	
+	recommendations = append(recommendations,
+		"📊 Regularly update dependencies to latest stable versions")
+	if hasGo {
+		recommendations = append(recommendations,
+			"🔍 Use `go mod tidy` to clean up unused dependencies",
+			"🔬 Run `govulncheck ./...` for call-graph vulnerability analysis through dependencies")
+	}
+
 	return recommendations
 }
 
+// writeVulnerabilities renders the OSV scan results
+func writeVulnerabilities(result *strings.Builder, vulns []Vulnerability, err error) {
+	result.WriteString("## 🛡️ Vulnerabilities\n\n")
+	if err != nil {
+		result.WriteString(fmt.Sprintf("Not scanned: %v\n\n", err))
+		return
+	}
+	if len(vulns) == 0 {
+		result.WriteString("✅ No known vulnerabilities in resolved dependency versions.\n\n")
+		return
+	}
+
+	counts := make(map[string]int)
+	for _, v := range vulns {
+		counts[v.Severity]++
+	}
+	summary := []string{}
+	for _, severity := range []string{"CRITICAL", "HIGH", "MEDIUM", "LOW", "UNKNOWN"} {
+		if counts[severity] > 0 {
+			summary = append(summary, fmt.Sprintf("%d %s", counts[severity], strings.ToLower(severity)))
+		}
+	}
+	result.WriteString(fmt.Sprintf("**%d advisories**: %s\n\n", len(vulns), strings.Join(summary, ", ")))
+
+	// Show only the first 50 to avoid clutter
+	displayCount := min(50, len(vulns))
+	for _, v := range vulns[:displayCount] {
+		severity := v.Severity
+		if v.Score > 0 {
+			severity = fmt.Sprintf("%s %.1f", v.Severity, v.Score)
+		}
+		id := v.ID
+		if len(v.Aliases) > 0 {
+			id = fmt.Sprintf("%s (%s)", v.ID, strings.Join(v.Aliases, ", "))
+		}
+		result.WriteString(fmt.Sprintf("- **%s** `%s` — %s `%s` in `%s`\n", id, severity, v.Dependency.Name, v.Dependency.Version, v.Dependency.Module))
+		if v.Summary != "" {
+			result.WriteString(fmt.Sprintf("  - %s\n", v.Summary))
+		}
+		if len(v.Ranges) > 0 {
+			result.WriteString(fmt.Sprintf("  - Affected: `%s`\n", strings.Join(v.Ranges, "` | `")))
+		}
+		if v.Fixed != "" {
+			result.WriteString(fmt.Sprintf("  - Fixed in: `%s`\n", v.Fixed))
+		} else {
+			result.WriteString("  - Fixed in: no fix available\n")
+		}
+		if v.Reachability != "" {
+			result.WriteString(fmt.Sprintf("  - Reachability: %s\n", v.Reachability))
+			for _, site := range v.CallSites {
+				result.WriteString(fmt.Sprintf("    - `%s`\n", site))
+			}
+		}
+	}
+	if len(vulns) > displayCount {
+		result.WriteString(fmt.Sprintf("\n... and %d more advisories\n", len(vulns)-displayCount))
+	}
+	result.WriteString("\n")
+}
+
 func min(a, b int) int {
 	if a < b {
 		return a
 	}
 	return b
-}
\ No newline at end of file
+}
+
+// writeOutdated renders the available upgrades, largest first
+func writeOutdated(result *strings.Builder, outdated []Outdated, err error) {
+	result.WriteString("## ⬆️ Available Upgrades\n\n")
+	if err != nil {
+		result.WriteString(fmt.Sprintf("Not checked: %v\n\n", err))
+		return
+	}
+	if len(outdated) == 0 {
+		result.WriteString("✅ All direct Go and npm dependencies are at their latest release.\n\n")
+		return
+	}
+
+	counts := make(map[string]int)
+	for _, o := range outdated {
+		counts[o.Kind]++
+	}
+	summary := []string{}
+	for _, kind := range []string{"major", "minor", "patch"} {
+		if counts[kind] > 0 {
+			summary = append(summary, fmt.Sprintf("%d %s", counts[kind], kind))
+		}
+	}
+	result.WriteString(fmt.Sprintf("**%d outdated**: %s\n\n", len(outdated), strings.Join(summary, ", ")))
+
+	// Show only the first 50 to avoid clutter
+	displayCount := min(50, len(outdated))
+	for _, o := range outdated[:displayCount] {
+		upgrades := []string{}
+		if o.Patch != "" {
+			upgrades = append(upgrades, fmt.Sprintf("patch `%s`", o.Patch))
+		}
+		if o.Minor != "" {
+			upgrades = append(upgrades, fmt.Sprintf("minor `%s`", o.Minor))
+		}
+		if o.Major != "" {
+			major := fmt.Sprintf("major `%s`", o.Major)
+			if o.MajorPath != "" {
+				major = fmt.Sprintf("major `%s@%s`", o.MajorPath, o.Major)
+			}
+			upgrades = append(upgrades, major)
+		}
+		result.WriteString(fmt.Sprintf("- **%s** `%s` → %s _(%s)_\n", o.Dependency.Name, o.Dependency.Version, strings.Join(upgrades, ", "), o.Dependency.Module))
+	}
+	if len(outdated) > displayCount {
+		result.WriteString(fmt.Sprintf("\n... and %d more\n", len(outdated)-displayCount))
+	}
+	result.WriteString("\n")
+}
+
+// writeLicenses renders dependencies grouped by license, the licenses
+// declared by the project and its files, and policy violations
+func writeLicenses(result *strings.Builder, report *LicenseReport, err error) {
+	result.WriteString("## ⚖️ Licenses\n\n")
+	if err != nil {
+		result.WriteString(fmt.Sprintf("Not checked: %v\n\n", err))
+		return
+	}
+
+	for _, file := range report.Project {
+		result.WriteString(fmt.Sprintf("- **Project**: %s (`%s`)\n", file.License, file.Path))
+	}
+	if len(report.Files) > 0 || report.Unlabeled > 0 {
+		counts := make(map[string]int)
+		for _, file := range report.Files {
+			counts[file.License]++
+		}
+		headers := []string{}
+		for _, license := range sortedLicenseCounts(counts) {
+			headers = append(headers, fmt.Sprintf("%d %s", counts[license], license))
+		}
+		if report.Unlabeled > 0 {
+			headers = append(headers, fmt.Sprintf("%d without header", report.Unlabeled))
+		}
+		result.WriteString(fmt.Sprintf("- **Source headers**: %s\n", strings.Join(headers, ", ")))
+	}
+	result.WriteString("\n")
+
+	// One entry per package version, grouped by license
+	byLicense := make(map[string][]string)
+	counts := make(map[string]int)
+	seen := make(map[string]bool)
+	for _, dl := range report.Dependencies {
+		name := fmt.Sprintf("%s@%s", dl.Dependency.Name, dl.Dependency.Version)
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		license := dl.License
+		if license == "" {
+			license = "Unknown"
+		}
+		byLicense[license] = append(byLicense[license], name)
+		counts[license]++
+	}
+	for _, license := range sortedLicenseCounts(counts) {
+		names := byLicense[license]
+		line := fmt.Sprintf("- **%s** (%d): `%s`", license, len(names), strings.Join(names[:min(10, len(names))], "`, `"))
+		if len(names) > 10 {
+			line += fmt.Sprintf(" and %d more", len(names)-10)
+		}
+		result.WriteString(line + "\n")
+	}
+	if len(byLicense) > 0 {
+		result.WriteString("\n")
+	}
+
+	if len(report.Violations) > 0 {
+		result.WriteString(fmt.Sprintf("### ❌ Policy Violations (%d)\n\n", len(report.Violations)))
+		for _, v := range report.Violations {
+			license := v.License
+			if license == "" {
+				license = "Unknown"
+			}
+			result.WriteString(fmt.Sprintf("- `%s` — %s: %s\n", v.Subject, license, v.Reason))
+		}
+		result.WriteString("\n")
+	} else if report.Policy {
+		result.WriteString("✅ All licenses comply with the configured policy.\n\n")
+	}
+}
+
+// sortedLicenseCounts orders licenses by descending count, then by name
+func sortedLicenseCounts(counts map[string]int) []string {
+	licenses := make([]string, 0, len(counts))
+	for license := range counts {
+		licenses = append(licenses, license)
+	}
+	sort.Slice(licenses, func(i, j int) bool {
+		if counts[licenses[i]] != counts[licenses[j]] {
+			return counts[licenses[i]] > counts[licenses[j]]
+		}
+		return licenses[i] < licenses[j]
+	})
+	return licenses
+}
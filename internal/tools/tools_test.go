@@ -0,0 +1,122 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/scopweb/mcp-go-context/internal/analyzer"
+	"github.com/scopweb/mcp-go-context/internal/config"
+)
+
+// testServer serves a real analyzer without memory
+type testServer struct {
+	analyzer AnalyzerInterface
+	root     string
+}
+
+func (s testServer) GetAnalyzer() AnalyzerInterface { return s.analyzer }
+func (s testServer) GetMemory() MemoryInterface     { return nil }
+func (s testServer) GetConfig() ConfigInterface     { return nil }
+
+func newTestServer(t *testing.T, files map[string]string) testServer {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a, err := analyzer.New(config.ContextConfig{ProjectPaths: []string{root}}, config.CacheConfig{}, config.DependencyConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testServer{analyzer: a, root: root}
+}
+
+// responseText returns the text of a single-item tool response
+func responseText(t *testing.T, response interface{}) string {
+	t.Helper()
+	items, ok := response.([]map[string]interface{})
+	if !ok || len(items) != 1 {
+		t.Fatalf("response = %#v, want one item", response)
+	}
+	return items[0]["text"].(string)
+}
+
+func TestGetContextRepoMapFitsBudget(t *testing.T) {
+	var source strings.Builder
+	source.WriteString("package shapes\n\n")
+	for i := 0; i < 60; i++ {
+		fmt.Fprintf(&source, "// Shape%d is a shape\ntype Shape%d struct{ Side float64 }\n\nfunc (s Shape%d) Area(scale float64) float64 { return s.Side * scale }\n\n", i, i, i)
+	}
+	srv := newTestServer(t, map[string]string{
+		"go.mod":    "module example.com/shapes\n\ngo 1.21\n",
+		"shapes.go": source.String(),
+	})
+
+	for _, maxTokens := range []int{120, 300, 1000} {
+		args, _ := json.Marshal(map[string]interface{}{"mode": "repomap", "maxTokens": maxTokens})
+		response, err := GetContextHandler(args, srv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		text := responseText(t, response)
+		if !strings.Contains(text, "# Repository Map") {
+			t.Fatalf("maxTokens %d: no repository map in %q", maxTokens, text)
+		}
+		if n := srv.analyzer.CountTokens(text); n > maxTokens {
+			t.Errorf("maxTokens %d: response is %d tokens", maxTokens, n)
+		}
+	}
+}
+
+func TestAnalyzeProjectSkippedReasons(t *testing.T) {
+	srv := newTestServer(t, map[string]string{
+		"main.go":           "package main\n\nfunc main() {}\n",
+		"big.txt":           strings.Repeat("x", 4096),
+		"a/b/c/d/deep.go":   "package d\n",
+		"vendor/skip/x.txt": "x\n",
+	})
+	root := srv.root
+
+	tests := []struct {
+		args map[string]interface{}
+		want []string
+		not  []string
+	}{
+		{
+			args: map[string]interface{}{"path": root, "maxFileSizeKB": 1, "exclude": []string{"vendor"}},
+			want: []string{"**depth limit** (deeper than 3): 1", "**too large** (over 1 KB): 1", "**excluded** (matching vendor): 1"},
+		},
+		{
+			args: map[string]interface{}{"path": root, "depth": -1},
+			not:  []string{"depth limit"},
+		},
+	}
+	for _, tt := range tests {
+		args, _ := json.Marshal(tt.args)
+		response, err := AnalyzeProjectHandler(args, srv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		text := responseText(t, response)
+		for _, want := range tt.want {
+			if !strings.Contains(text, want) {
+				t.Errorf("%v: output lacks %q:\n%s", tt.args, want, text)
+			}
+		}
+		for _, not := range tt.not {
+			if strings.Contains(text, not) {
+				t.Errorf("%v: output has %q:\n%s", tt.args, not, text)
+			}
+		}
+	}
+}